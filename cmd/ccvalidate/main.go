@@ -0,0 +1,237 @@
+// Command ccvalidate validates card numbers from the terminal or in shell
+// pipelines using the same validator as the service.
+//
+// Card numbers are read from the arguments, from files given with -f, or from
+// stdin when neither is present, one number per line. The exit status is 0
+// when every number is valid, 1 when any number is invalid and 2 on usage or
+// I/O errors.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Exit statuses
+const (
+	exitValid   = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+// fileList collects repeated -f flags
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// options holds the command-line flags
+type options struct {
+	files    fileList
+	format   string
+	bin      bool
+	binURL   string
+	timeout  time.Duration
+	accept   string
+	showPAN  bool
+	verbose  bool
+	noHeader bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command and returns its exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var opts options
+
+	flags := flag.NewFlagSet("ccvalidate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var(&opts.files, "f", "read card numbers from `file`, one per line (- for stdin); repeatable")
+	flags.StringVar(&opts.format, "o", "table", "output format: table, json or csv")
+	flags.BoolVar(&opts.bin, "bin", false, "enrich valid cards with a BIN lookup")
+	flags.StringVar(&opts.binURL, "bin-url", service.DefaultConfig().BINServiceURL, "BIN lookup service `url`")
+	flags.DurationVar(&opts.timeout, "timeout", 10*time.Second, "BIN lookup timeout")
+	flags.StringVar(&opts.accept, "accept", "", "comma separated card `types` considered valid (empty accepts all)")
+	flags.BoolVar(&opts.showPAN, "show-pan", false, "print full card numbers instead of masked ones")
+	flags.BoolVar(&opts.verbose, "v", false, "log validation details to stderr")
+	flags.BoolVar(&opts.noHeader, "no-header", false, "omit the header row of table and csv output")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, `Usage: ccvalidate [flags] [card number ...]
+
+Validates card numbers given as arguments, read from files (-f) or from
+stdin. Exits with 0 when all numbers are valid, 1 when any is invalid and 2
+on errors.
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitValid
+		}
+		return exitError
+	}
+
+	writer, err := newWriter(opts.format, stdout, !opts.noHeader)
+	if err != nil {
+		fmt.Fprintf(stderr, "ccvalidate: %v\n", err)
+		return exitError
+	}
+
+	validator, err := newValidator(&opts, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "ccvalidate: %v\n", err)
+		return exitError
+	}
+
+	status := exitValid
+	ctx := context.Background()
+	count := 0
+	err = eachInput(flags.Args(), opts.files, stdin, func(in input) error {
+		count++
+
+		var (
+			result *service.ValidationResult
+			err    error
+		)
+		if opts.bin {
+			result, err = validator.ValidateCard(ctx, in.value)
+		} else {
+			result, err = validator.ValidateCardSimple(in.value)
+		}
+
+		if err != nil || !result.Valid {
+			status = exitInvalid
+		}
+
+		return writer.write(newOutput(in, result, err, opts.showPAN))
+	})
+	if err != nil {
+		writer.flush()
+		fmt.Fprintf(stderr, "ccvalidate: %v\n", err)
+		return exitError
+	}
+	if count == 0 {
+		fmt.Fprintln(stderr, "ccvalidate: no card numbers given")
+		return exitError
+	}
+
+	if err := writer.flush(); err != nil {
+		fmt.Fprintf(stderr, "ccvalidate: %v\n", err)
+		return exitError
+	}
+
+	return status
+}
+
+// newValidator builds a validator from the flags. BIN lookups are disabled
+// unless requested so the tool works offline.
+func newValidator(opts *options, stderr io.Writer) (*service.Validator, error) {
+	logger := logrus.New()
+	logger.SetOutput(stderr)
+	logger.SetLevel(logrus.WarnLevel)
+	if opts.verbose {
+		logger.SetLevel(logrus.InfoLevel)
+	}
+
+	cfg := service.DefaultConfig()
+	cfg.EnableBINLookup = opts.bin
+	cfg.BINServiceURL = strings.TrimRight(opts.binURL, "/")
+	cfg.HTTPTimeout = opts.timeout
+	cfg.MaskSensitive = !opts.showPAN
+	for _, cardType := range strings.Split(opts.accept, ",") {
+		if cardType = strings.TrimSpace(cardType); cardType != "" {
+			cfg.AcceptedCardTypes = append(cfg.AcceptedCardTypes, cardType)
+		}
+	}
+
+	return service.NewValidator(cfg, logging.FromLogrus(logger))
+}
+
+// input is one card number and where it came from
+type input struct {
+	source string
+	line   int
+	value  string
+}
+
+// eachInput passes card numbers from the arguments and files to fn as they
+// are read, falling back to stdin when neither is given. It stops at the
+// first error.
+func eachInput(args []string, files []string, stdin io.Reader, fn func(input) error) error {
+	for i, arg := range args {
+		if err := fn(input{source: "arg", line: i + 1, value: arg}); err != nil {
+			return err
+		}
+	}
+
+	if len(args) == 0 && len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, name := range files {
+		if err := eachFileInput(name, stdin, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// eachFileInput passes the card numbers of a file, or of stdin for -, to fn
+func eachFileInput(name string, stdin io.Reader, fn func(input) error) error {
+	r, source := stdin, "stdin"
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r, source = file, name
+	}
+
+	if err := eachLine(source, r, fn); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	return nil
+}
+
+// eachLine reads one card number per line, skipping blank lines and lines
+// starting with #
+func eachLine(source string, r io.Reader, fn func(input) error) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		if err := fn(input{source: source, line: line, value: value}); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
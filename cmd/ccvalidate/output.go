@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"credit-card-validator/internal/service"
+)
+
+// output is the printed result for one input
+type output struct {
+	Source     string              `json:"source"`
+	Line       int                 `json:"line"`
+	CardNumber string              `json:"card_number"`
+	Valid      bool                `json:"valid"`
+	CardType   service.CardType    `json:"card_type,omitempty"`
+	Issues     []service.IssueCode `json:"issues,omitempty"`
+	Scheme     string              `json:"scheme,omitempty"`
+	Bank       string              `json:"bank,omitempty"`
+	Country    string              `json:"country,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// newOutput builds the output for a validation result or error. Card numbers
+// are masked unless showPAN is set.
+func newOutput(in input, result *service.ValidationResult, err error, showPAN bool) output {
+	out := output{
+		Source: in.source,
+		Line:   in.line,
+	}
+
+	if err != nil {
+		out.CardNumber = displayPAN(in.value, showPAN)
+		out.Error = err.Error()
+		return out
+	}
+
+	out.CardNumber = displayPAN(result.CardNumber, showPAN)
+	out.Valid = result.Valid
+	out.CardType = result.CardType
+	out.Issues = result.Issues
+	out.Scheme = result.Scheme
+	out.Bank = result.Bank.Name
+	out.Country = result.Country.Alpha2
+
+	return out
+}
+
+// displayPAN returns the card number as printed. Unparseable input is masked
+// as well since it may still hold a PAN.
+func displayPAN(value string, showPAN bool) string {
+	if showPAN {
+		return value
+	}
+	return service.MaskCardNumber(strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, value))
+}
+
+// issues joins the issue codes of an output
+func (o output) issues() string {
+	codes := make([]string, len(o.Issues))
+	for i, issue := range o.Issues {
+		codes[i] = issue.String()
+	}
+	return strings.Join(codes, ";")
+}
+
+// writer prints outputs in one format
+type writer interface {
+	write(output) error
+	flush() error
+}
+
+// newWriter returns a writer for the named format
+func newWriter(format string, w io.Writer, header bool) (writer, error) {
+	switch format {
+	case "table":
+		return newTableWriter(w, header), nil
+	case "json":
+		return &jsonWriter{encoder: json.NewEncoder(w)}, nil
+	case "csv":
+		return newCSVWriter(w, header), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// tableRows is how many rows are aligned and printed together, so long
+// inputs are printed while they are read
+const tableRows = 100
+
+// tableWriter prints aligned columns
+type tableWriter struct {
+	tw   *tabwriter.Writer
+	rows int
+}
+
+func newTableWriter(w io.Writer, header bool) *tableWriter {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if header {
+		fmt.Fprintln(tw, "CARD NUMBER\tVALID\tTYPE\tISSUES\tSCHEME\tBANK\tCOUNTRY")
+	}
+	return &tableWriter{tw: tw}
+}
+
+func (t *tableWriter) write(o output) error {
+	valid := strconv.FormatBool(o.Valid)
+	issues := o.issues()
+	if o.Error != "" {
+		valid, issues = "error", o.Error
+	}
+
+	_, err := fmt.Fprintf(t.tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		o.CardNumber, valid, dash(o.CardType.String()), dash(issues), dash(o.Scheme), dash(o.Bank), dash(o.Country))
+	if err != nil {
+		return err
+	}
+
+	t.rows++
+	if t.rows%tableRows == 0 {
+		return t.tw.Flush()
+	}
+	return nil
+}
+
+func (t *tableWriter) flush() error {
+	return t.tw.Flush()
+}
+
+// dash replaces empty table cells
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// jsonWriter prints one JSON object per line
+type jsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonWriter) write(o output) error {
+	return j.encoder.Encode(o)
+}
+
+func (j *jsonWriter) flush() error {
+	return nil
+}
+
+// csvWriter prints CSV rows as they are written
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, header bool) *csvWriter {
+	cw := csv.NewWriter(w)
+	if header {
+		cw.Write([]string{"source", "line", "card_number", "valid", "card_type", "issues", "scheme", "bank", "country", "error"})
+	}
+	return &csvWriter{w: cw}
+}
+
+func (c *csvWriter) write(o output) error {
+	err := c.w.Write([]string{
+		o.Source,
+		strconv.Itoa(o.Line),
+		o.CardNumber,
+		strconv.FormatBool(o.Valid),
+		o.CardType.String(),
+		o.issues(),
+		o.Scheme,
+		o.Bank,
+		o.Country,
+		o.Error,
+	})
+	if err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
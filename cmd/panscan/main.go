@@ -0,0 +1,222 @@
+// Command panscan searches files and directories for cleartext card numbers.
+//
+// Directories are walked recursively and gzip and tar archives are scanned
+// member by member. Text is extracted from XLSX, DOCX, ODS and PDF documents.
+// Every finding is reported with its file, line or document location, byte
+// offset, masked PAN and confidence. The exit status is 0 when no PAN was
+// found, 1 when any was found and 2 when a file could not be scanned.
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+)
+
+// Exit statuses
+const (
+	exitClean    = 0
+	exitFindings = 1
+	exitError    = 2
+)
+
+// patternList collects repeated glob flags
+type patternList []string
+
+func (p *patternList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patternList) Set(value string) error {
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			*p = append(*p, pattern)
+		}
+	}
+	return nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command and returns its exit status
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	var (
+		opts   dlp.FileScanOptions
+		format string
+		quiet  bool
+	)
+
+	flags := flag.NewFlagSet("panscan", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var((*patternList)(&opts.Include), "include", "only scan files matching `glob`; repeatable or comma separated")
+	flags.Var((*patternList)(&opts.Exclude), "exclude", "skip files and directories matching `glob`; repeatable or comma separated")
+	flags.IntVar(&opts.Workers, "workers", runtime.NumCPU(), "files scanned in parallel")
+	flags.Float64Var(&opts.MinConfidence, "min-confidence", 0, "report only findings with at least this confidence (0-1)")
+	flags.StringVar(&format, "o", "table", "report format: table, json or csv")
+	flags.BoolVar(&quiet, "q", false, "do not print the summary")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, `Usage: panscan [flags] path ...
+
+Scans files and directories, including gzip and tar archives and XLSX,
+DOCX, ODS and PDF documents, for card numbers. Exits with 0 when nothing was found, 1 when card numbers were found
+and 2 when a file could not be scanned.
+
+Flags:`)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	report, err := newReport(format, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "panscan: %v\n", err)
+		return exitError
+	}
+
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		fmt.Fprintf(stderr, "panscan: %v\n", err)
+		return exitError
+	}
+
+	stats, err := scanner.ScanFiles(ctx, flags.Args(), opts, func(file string, findings []dlp.FileFinding, err error) {
+		if err != nil {
+			fmt.Fprintf(stderr, "panscan: %s: %v\n", file, err)
+		}
+		for _, finding := range findings {
+			report.write(finding)
+		}
+	})
+	if ferr := report.flush(); ferr != nil && err == nil {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "panscan: %v\n", err)
+		return exitError
+	}
+
+	if !quiet {
+		fmt.Fprintf(stderr, "Scanned %d files (%d bytes): %d findings, %d errors\n",
+			stats.Files, stats.Bytes, stats.Findings, stats.Errors)
+	}
+
+	switch {
+	case stats.Findings > 0:
+		return exitFindings
+	case stats.Errors > 0:
+		return exitError
+	default:
+		return exitClean
+	}
+}
+
+// report prints findings in one format
+type report interface {
+	write(dlp.FileFinding)
+	flush() error
+}
+
+// newReport returns a report for the named format
+func newReport(format string, w io.Writer) (report, error) {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FILE\tLOCATION\tOFFSET\tMASKED PAN\tTYPE\tCONFIDENCE")
+		return &tableReport{tw: tw}, nil
+	case "json":
+		return &jsonReport{encoder: json.NewEncoder(w)}, nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"file", "line", "location", "offset", "masked_pan", "card_type", "confidence"})
+		return &csvReport{w: cw}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// tableReport prints aligned columns
+type tableReport struct {
+	tw *tabwriter.Writer
+}
+
+func (t *tableReport) write(f dlp.FileFinding) {
+	fmt.Fprintf(t.tw, "%s\t%s\t%d\t%s\t%s\t%.2f\n", f.File, location(f), f.Offset, f.MaskedPAN, f.CardType, f.Confidence)
+}
+
+func (t *tableReport) flush() error {
+	return t.tw.Flush()
+}
+
+// location describes where in a file a finding is
+func location(f dlp.FileFinding) string {
+	if f.Location != "" {
+		return f.Location
+	}
+	return "line " + strconv.Itoa(f.Line)
+}
+
+// jsonReport prints one JSON object per finding
+type jsonReport struct {
+	encoder *json.Encoder
+	err     error
+}
+
+func (j *jsonReport) write(f dlp.FileFinding) {
+	if err := j.encoder.Encode(f); err != nil && j.err == nil {
+		j.err = err
+	}
+}
+
+func (j *jsonReport) flush() error {
+	return j.err
+}
+
+// csvReport prints CSV rows
+type csvReport struct {
+	w *csv.Writer
+}
+
+func (c *csvReport) write(f dlp.FileFinding) {
+	c.w.Write([]string{
+		f.File,
+		strconv.Itoa(f.Line),
+		f.Location,
+		strconv.FormatInt(f.Offset, 10),
+		f.MaskedPAN,
+		f.CardType,
+		strconv.FormatFloat(f.Confidence, 'f', 2, 64),
+	})
+}
+
+func (c *csvReport) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/config"
+)
+
+// runAudit implements the audit subcommand
+func runAudit(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "verify" {
+		return errors.New("usage: server audit verify [-dir DIR] [-checkpoint FILE] [-export FILE]")
+	}
+
+	fs := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	dir := fs.String("dir", cfg.Audit.Dir, "audit log directory")
+	checkpointFile := fs.String("checkpoint", "", "exported checkpoint the log must contain")
+	exportFile := fs.String("export", "", "write the checkpoint of the verified log to this file")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	key := audit.ChainKey(&cfg.Audit)
+	if len(key) == 0 {
+		return errors.New("AUDIT_CHAIN_KEY or AUDIT_FINGERPRINT_KEY is required to verify the audit log")
+	}
+
+	var checkpoint *audit.Checkpoint
+	if *checkpointFile != "" {
+		var err error
+		if checkpoint, err = audit.ReadCheckpoint(*checkpointFile); err != nil {
+			return err
+		}
+	}
+
+	report, err := audit.Verify(*dir, key, checkpoint)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Audit log OK: %d entries in %d files\n", report.Entries, report.Files)
+	if report.LastHash != "" {
+		fmt.Printf("Last hash: %s\n", report.LastHash)
+	}
+
+	if *exportFile != "" && report.Checkpoint != nil {
+		data, err := json.Marshal(report.Checkpoint)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*exportFile, data, 0o600); err != nil {
+			return fmt.Errorf("failed to export checkpoint: %w", err)
+		}
+		fmt.Printf("Checkpoint written to %s\n", *exportFile)
+	}
+
+	return nil
+}
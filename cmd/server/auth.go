@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+)
+
+// newAuthenticator builds the authenticator for API keys and, when enabled,
+// JWT bearer tokens
+func newAuthenticator(cfg *config.AuthConfig) (auth.Authenticator, error) {
+	keyStore, err := auth.NewKeyStore(cfg.APIKeysFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.JWTEnabled {
+		return keyStore, nil
+	}
+
+	jwks, err := auth.NewJWKS(context.Background(), cfg.JWKSFile, cfg.JWKSURL, cfg.JWKSRefresh, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := auth.NewJWTVerifier(jwks, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.Chain(keyStore, verifier), nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"credit-card-validator/internal/config"
+)
+
+// runCommand executes an administrative subcommand instead of starting the servers
+func runCommand(cfg *config.Config, name string, args []string) error {
+	switch name {
+	case "keys":
+		return runKeys(cfg, args)
+	case "audit":
+		return runAudit(cfg, args)
+	case "help", "-h", "--help":
+		printUsage()
+		return nil
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// printUsage prints the available subcommands
+func printUsage() {
+	fmt.Fprintln(os.Stderr, `Usage: server [command]
+
+Without a command the HTTP and gRPC servers are started.
+
+Commands:
+  keys    Manage API keys (create, list, revoke)
+  audit   Verify the audit log hash chain (verify)`)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+)
+
+// runKeys implements the keys subcommand used to manage API keys
+func runKeys(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: server keys <create|list|revoke> [options]")
+	}
+
+	store, err := auth.NewKeyStore(cfg.Auth.APIKeysFile)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "create":
+		return createKey(store, args[1:])
+	case "list":
+		return listKeys(store)
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New("usage: server keys revoke <id>")
+		}
+		if err := store.Revoke(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked key %s\n", args[1])
+		return nil
+	default:
+		return fmt.Errorf("unknown keys command %q", args[0])
+	}
+}
+
+// createKey creates a new API key and prints its token once
+func createKey(store *auth.KeyStore, args []string) error {
+	fs := flag.NewFlagSet("keys create", flag.ContinueOnError)
+	name := fs.String("name", "", "human readable name of the key owner")
+	tenant := fs.String("tenant", "", "tenant the key is bound to (optional)")
+	scopes := fs.String("scopes", string(auth.ScopeValidate), "comma separated scopes (validate, bin:read, tokenize, admin)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	parsed, err := auth.ParseScopes(*scopes)
+	if err != nil {
+		return err
+	}
+
+	token, key, err := store.Create(*name, *tenant, parsed)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created key %s (%s)\n", key.ID, key.Name)
+	fmt.Printf("Token: %s\n", token)
+	fmt.Println("Store the token securely, it cannot be shown again.")
+
+	return nil
+}
+
+// listKeys prints all stored keys without their secrets
+func listKeys(store *auth.KeyStore) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTENANT\tSCOPES\tCREATED\tSTATUS")
+
+	for _, key := range store.List() {
+		scopes := make([]string, len(key.Scopes))
+		for i, scope := range key.Scopes {
+			scopes[i] = scope.String()
+		}
+
+		status := "active"
+		if key.Revoked() {
+			status = "revoked " + key.RevokedAt.Format(time.RFC3339)
+		}
+
+		tenant := key.Tenant
+		if tenant == "" {
+			tenant = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.ID, key.Name, tenant, strings.Join(scopes, ","), key.CreatedAt.Format(time.RFC3339), status)
+	}
+
+	return w.Flush()
+}
@@ -5,23 +5,43 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
 
+	"credit-card-validator/internal/api/gateway"
 	"credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/api/grpcweb"
+	"credit-card-validator/internal/api/mux"
+	"credit-card-validator/internal/api/openapi"
 	"credit-card-validator/internal/api/rest"
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/certs"
 	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/health"
+	"credit-card-validator/internal/jobs"
+	"credit-card-validator/internal/logging"
 	"credit-card-validator/internal/middleware"
-	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/proxy"
+	"credit-card-validator/internal/syslog"
+	"credit-card-validator/internal/tenant"
+	"credit-card-validator/internal/tracing"
+	pb "credit-card-validator/pkg/proto"
+	pbv2 "credit-card-validator/pkg/proto/v2"
 
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	grpcserver "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -29,6 +49,14 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Run administrative subcommands
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s", err.Error())
+		}
+		return
+	}
+
 	// Setup logger
 	logger := logrus.New()
 	level, err := logrus.ParseLevel(cfg.LogLevel)
@@ -37,71 +65,403 @@ func main() {
 	}
 	logger.SetLevel(level)
 
-	// Create validator service
-	validatorService, err := service.NewValidator(&cfg.Validator, logger)
+	// Components log through requestLogger; while serving a request they use
+	// the request logger carried by the context instead
+	requestLogger := logging.FromLogrus(logger)
+
+	// Setup tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing)
+	if err != nil {
+		logger.Fatalf("Failed to setup tracing: %v", err)
+	}
+
+	// Create a validator service per tenant
+	var overrides map[string]config.TenantOverride
+	if cfg.Tenants.TenantsFile != "" {
+		overrides, err = config.LoadTenants(cfg.Tenants.TenantsFile)
+		if err != nil {
+			log.Fatalf("%s", err.Error())
+		}
+	}
+
+	tenants, err := tenant.NewRegistry(cfg, overrides, requestLogger)
 	if err != nil {
 		log.Fatalf("%s", err.Error())
 	}
 
+	// Setup audit log
+	var auditLog *audit.Logger
+	if cfg.Audit.Enabled {
+		auditLog, err = audit.NewLogger(&cfg.Audit)
+		if err != nil {
+			logger.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer auditLog.Close()
+	}
+
+	// Setup authentication
+	var authenticator auth.Authenticator
+	if cfg.Auth.Enabled {
+		authenticator, err = newAuthenticator(&cfg.Auth)
+		if err != nil {
+			logger.Fatalf("Failed to setup authentication: %v", err)
+		}
+	}
+
+	// Setup TLS with certificate hot reload
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+
+	var certReloader *certs.Reloader
+	if cfg.TLS.Enabled() {
+		certReloader, err = certs.NewReloader(&cfg.TLS, requestLogger)
+		if err != nil {
+			logger.Fatalf("Failed to load TLS certificates: %v", err)
+		}
+		go certReloader.Watch(watchCtx)
+	}
+
+	// Setup PAN detection for free text
+	scanner, err := dlp.NewScanner(&cfg.DLP)
+	if err != nil {
+		logger.Fatalf("Failed to create PAN scanner: %v", err)
+	}
+
+	// Setup bulk validation jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	var jobManager *jobs.Manager
+	if cfg.Jobs.Enabled {
+		jobManager, err = jobs.NewManager(&cfg.Jobs, tenants, auditLog, requestLogger)
+		if err != nil {
+			logger.Fatalf("Failed to open job store: %v", err)
+		}
+		if err := jobManager.Start(jobsCtx); err != nil {
+			logger.Fatalf("Failed to start jobs: %v", err)
+		}
+	}
+
+	// Setup readiness checks
+	checker := health.NewChecker(&cfg.Health, requestLogger)
+	// Validation works without the BIN service, only without issuer details
+	checker.AddOptional("bin_service", tenants.CheckBINServices)
+
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	defer stopHealth()
+	go checker.Run(healthCtx)
+
 	// Setup Echo server
 	e := echo.New()
 	e.HideBanner = true
+	e.HTTPErrorHandler = apperror.HTTPErrorHandler
 	e.Use(echomiddleware.Logger())
 	e.Use(echomiddleware.Recover())
-	e.Use(echomiddleware.CORS())
+	e.Use(echomiddleware.CORSWithConfig(echomiddleware.CORSConfig{
+		// Let cross-origin gRPC-Web clients read the call status
+		ExposeHeaders: []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"},
+	}))
 	e.Use(middleware.RequestID())
-	e.Use(middleware.Metrics())
+	e.Use(middleware.Tracing())
+	e.Use(middleware.ContextLogger(requestLogger))
+	e.Use(middleware.Metrics(tenants.Header()))
+
+	// Document the REST API and validate requests against the document
+	spec := openapi.New(gateway.Routes(), openapi.Options{
+		Title:         "Credit Card Validator API",
+		Version:       "1.0.0",
+		TenantHeader:  tenants.Header(),
+		Auth:          authenticator != nil,
+		MaxBatchItems: cfg.Batch.MaxItems,
+		MaxBodySize:   cfg.MaxRequestSize,
+	})
 
 	// Setup REST API
-	restHandler := rest.NewHandler(validatorService, logger)
-	restHandler.RegisterRoutes(e)
+	restHandler := rest.NewHandler(tenants, jobManager, &cfg.Jobs, scanner, requestLogger)
+	restHandler.RegisterRoutes(e, authenticator)
 
 	// Serve static files
 	e.Static("/", "web")
 
-	// Health check endpoint
+	// Health check endpoints
 	e.GET("/health", func(c echo.Context) error {
 		return c.JSON(200, map[string]string{"status": "healthy"})
 	})
+	e.GET("/livez", checker.LivezHandler())
+	e.GET("/readyz", checker.ReadyzHandler())
 
 	// Metrics endpoint
 	if cfg.MetricsEnabled {
 		e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 	}
 
-	// Setup gRPC server
-	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
-	if err != nil {
-		logger.Fatalf("Failed to listen on gRPC port: %v", err)
+	// Setup gRPC server. In single-port mode it is served by the HTTP server.
+	var grpcListener net.Listener
+	if !cfg.SinglePort {
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			logger.Fatalf("Failed to listen on gRPC port: %v", err)
+		}
+	}
+
+	// Assign request IDs, log and measure calls and recover panics before
+	// authentication, so rejected calls are observed as well
+	unaryInterceptors := []grpcserver.UnaryServerInterceptor{
+		grpc.TracingUnaryInterceptor(),
+		grpc.RequestIDUnaryInterceptor(),
+		grpc.LoggerUnaryInterceptor(requestLogger),
+		grpc.ObserveUnaryInterceptor(requestLogger),
+		grpc.RecoveryUnaryInterceptor(requestLogger),
+	}
+	streamInterceptors := []grpcserver.StreamServerInterceptor{
+		grpc.TracingStreamInterceptor(),
+		grpc.RequestIDStreamInterceptor(),
+		grpc.LoggerStreamInterceptor(requestLogger),
+		grpc.ObserveStreamInterceptor(requestLogger),
+		grpc.RecoveryStreamInterceptor(requestLogger),
+	}
+	if authenticator != nil {
+		unaryInterceptors = append(unaryInterceptors, grpc.AuthUnaryInterceptor(authenticator))
+		streamInterceptors = append(streamInterceptors, grpc.AuthStreamInterceptor(authenticator))
+	}
+	unaryInterceptors = append(unaryInterceptors, grpc.TenantUnaryInterceptor(tenants))
+	streamInterceptors = append(streamInterceptors, grpc.TenantStreamInterceptor(tenants))
+
+	grpcOptions := []grpcserver.ServerOption{
+		grpcserver.ChainUnaryInterceptor(unaryInterceptors...),
+		grpcserver.ChainStreamInterceptor(streamInterceptors...),
+	}
+
+	if certReloader != nil && !cfg.SinglePort {
+		grpcTLS, err := certReloader.ServerConfig(certs.ClientAuth(cfg.TLS.GRPCClientAuth))
+		if err != nil {
+			logger.Fatalf("Failed to configure gRPC TLS: %v", err)
+		}
+		grpcOptions = append(grpcOptions, grpcserver.Creds(credentials.NewTLS(grpcTLS)))
 	}
 
-	grpcServer := grpcserver.NewServer()
-	grpcHandler := grpc.NewServer(validatorService, logger)
+	grpcServer := grpcserver.NewServer(grpcOptions...)
+	grpcHandler := grpc.NewServer(tenants, auditLog, &cfg.Batch, scanner, requestLogger)
 	grpcHandler.RegisterServer(grpcServer)
 	reflection.Register(grpcServer)
+	checker.RegisterGRPC(grpcServer, pb.CardValidator_ServiceDesc.ServiceName, pbv2.CardValidator_ServiceDesc.ServiceName)
+
+	// Serve the annotated RPCs as REST. The gateway calls an in-process gRPC
+	// server on loopback with the same interceptors, audited as REST. Only
+	// this server trusts the client certificate subject forwarded by the
+	// gateway.
+	gatewayServer := grpcserver.NewServer(
+		grpcserver.ChainUnaryInterceptor(append([]grpcserver.UnaryServerInterceptor{
+			grpc.ChannelUnaryInterceptor(audit.ChannelREST),
+			grpc.ForwardedCertUnaryInterceptor(),
+		}, unaryInterceptors...)...),
+		grpcserver.ChainStreamInterceptor(append([]grpcserver.StreamServerInterceptor{
+			grpc.ChannelStreamInterceptor(audit.ChannelREST),
+			grpc.ForwardedCertStreamInterceptor(),
+		}, streamInterceptors...)...),
+	)
+	grpcHandler.RegisterServer(gatewayServer)
+
+	gatewayListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		logger.Fatalf("Failed to listen for the REST gateway: %v", err)
+	}
+	gatewayConn, err := grpcserver.NewClient(gatewayListener.Addr().String(),
+		grpcserver.WithTransportCredentials(insecure.NewCredentials()),
+		grpcserver.WithChainUnaryInterceptor(grpc.TracingClientUnaryInterceptor()),
+		grpcserver.WithChainStreamInterceptor(grpc.TracingClientStreamInterceptor()),
+	)
+	if err != nil {
+		logger.Fatalf("Failed to connect the REST gateway: %v", err)
+	}
+	defer gatewayConn.Close()
+
+	gatewayHandler, err := gateway.NewHandler(context.Background(), gatewayConn, tenants.Header())
+	if err != nil {
+		logger.Fatalf("Failed to setup the REST gateway: %v", err)
+	}
+
+	gateway.Register(e, gatewayHandler, spec.ValidateRequests())
+	e.GET("/openapi.json", spec.Handler())
+	e.File("/docs", "web/docs.html")
+
+	// Serve the gRPC API to browsers over gRPC-Web and Connect. Calls are
+	// handled in process by a gRPC server with the same interceptors,
+	// audited as web.
+	webServer := grpcserver.NewServer(
+		grpcserver.ChainUnaryInterceptor(append([]grpcserver.UnaryServerInterceptor{grpc.ChannelUnaryInterceptor(audit.ChannelWeb)}, unaryInterceptors...)...),
+		grpcserver.ChainStreamInterceptor(append([]grpcserver.StreamServerInterceptor{grpc.ChannelStreamInterceptor(audit.ChannelWeb)}, streamInterceptors...)...),
+	)
+	grpcHandler.RegisterServer(webServer)
+
+	webHandler, err := grpcweb.NewHandler(webServer)
+	if err != nil {
+		logger.Fatalf("Failed to setup gRPC-Web: %v", err)
+	}
+	grpcweb.Register(e, webHandler)
+
+	if certReloader != nil && !cfg.SinglePort {
+		e.TLSServer.TLSConfig, err = certReloader.ServerConfig(certs.ClientAuth(cfg.TLS.HTTPClientAuth))
+		if err != nil {
+			logger.Fatalf("Failed to configure HTTP TLS: %v", err)
+		}
+	}
+
+	// Serve gRPC and HTTP on one port, routed on content type
+	var singlePortServer *http.Server
+	if cfg.SinglePort {
+		grpcAuth := certs.ClientAuth(cfg.TLS.GRPCClientAuth)
+		httpAuth := certs.ClientAuth(cfg.TLS.HTTPClientAuth)
+
+		singlePortServer = &http.Server{
+			Addr: fmt.Sprintf(":%d", cfg.Port),
+			Handler: mux.NewHandler(grpcServer, e, mux.Options{
+				GRPCClientCert: certReloader != nil && grpcAuth == certs.ClientAuthRequire,
+				HTTPClientCert: certReloader != nil && httpAuth == certs.ClientAuthRequire,
+			}),
+			ReadHeaderTimeout: 10 * time.Second,
+			Protocols:         mux.Protocols(certReloader != nil),
+		}
+		if certReloader != nil {
+			singlePortServer.TLSConfig, err = certReloader.ServerConfig(grpcAuth.Shared(httpAuth))
+			if err != nil {
+				logger.Fatalf("Failed to configure TLS: %v", err)
+			}
+		}
+	}
+
+	// Setup redacting reverse proxy
+	var proxyServer *http.Server
+	if cfg.Proxy.Enabled {
+		var routes []config.ProxyRoute
+		if cfg.Proxy.RoutesFile != "" {
+			routes, err = config.LoadProxyRoutes(cfg.Proxy.RoutesFile)
+			if err != nil {
+				logger.Fatalf("Failed to load proxy routes: %v", err)
+			}
+		}
+
+		redactingProxy, err := proxy.NewProxy(&cfg.Proxy, routes, scanner, requestLogger)
+		if err != nil {
+			logger.Fatalf("Failed to setup proxy: %v", err)
+		}
+
+		proxyServer = &http.Server{
+			Addr:              fmt.Sprintf(":%d", cfg.Proxy.Port),
+			Handler:           redactingProxy,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		if certReloader != nil {
+			proxyServer.TLSConfig, err = certReloader.ServerConfig(certs.ClientAuth(cfg.TLS.HTTPClientAuth))
+			if err != nil {
+				logger.Fatalf("Failed to configure proxy TLS: %v", err)
+			}
+		}
+	}
+
+	// Setup syslog redaction
+	syslogCtx, stopSyslog := context.WithCancel(context.Background())
+	defer stopSyslog()
+
+	var syslogServer *syslog.Server
+	if cfg.Syslog.Enabled {
+		syslogServer, err = syslog.NewServer(&cfg.Syslog, scanner, requestLogger)
+		if err != nil {
+			logger.Fatalf("Failed to setup syslog: %v", err)
+		}
+	}
 
 	// Start servers
 	var wg sync.WaitGroup
 
 	// Start gRPC server
+	if grpcListener != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Infof("Starting gRPC server on port %d", cfg.GRPCPort)
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				logger.Errorf("gRPC server error: %v", err)
+			}
+		}()
+	}
+
+	// Start REST gateway backend
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		logger.Infof("Starting gRPC server on port %d", cfg.GRPCPort)
-		if err := grpcServer.Serve(grpcListener); err != nil {
-			logger.Errorf("gRPC server error: %v", err)
+		if err := gatewayServer.Serve(gatewayListener); err != nil {
+			logger.Errorf("REST gateway server error: %v", err)
 		}
 	}()
 
 	// Start HTTP server
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		logger.Infof("Starting HTTP server on port %d", cfg.Port)
-		if err := e.Start(fmt.Sprintf(":%d", cfg.Port)); err != nil {
-			logger.Errorf("HTTP server error: %v", err)
-		}
-	}()
+	if singlePortServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			if singlePortServer.TLSConfig != nil {
+				logger.Infof("Starting HTTPS and gRPC server on port %d", cfg.Port)
+				err = singlePortServer.ListenAndServeTLS("", "")
+			} else {
+				logger.Infof("Starting HTTP and gRPC (h2c) server on port %d", cfg.Port)
+				err = singlePortServer.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				logger.Errorf("HTTP server error: %v", err)
+			}
+		}()
+	} else {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addr := fmt.Sprintf(":%d", cfg.Port)
+
+			var err error
+			if e.TLSServer.TLSConfig != nil {
+				logger.Infof("Starting HTTPS server on port %d", cfg.Port)
+				e.TLSServer.Addr = addr
+				err = e.StartServer(e.TLSServer)
+			} else {
+				logger.Infof("Starting HTTP server on port %d", cfg.Port)
+				err = e.Start(addr)
+			}
+			if err != nil {
+				logger.Errorf("HTTP server error: %v", err)
+			}
+		}()
+	}
+
+	// Start proxy server
+	if proxyServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Infof("Starting redacting proxy on port %d for %s", cfg.Proxy.Port, cfg.Proxy.Upstream)
+
+			var err error
+			if proxyServer.TLSConfig != nil {
+				err = proxyServer.ListenAndServeTLS("", "")
+			} else {
+				err = proxyServer.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				logger.Errorf("Proxy server error: %v", err)
+			}
+		}()
+	}
+
+	// Start syslog receiver
+	if syslogServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Infof("Starting syslog receiver (udp %s, tcp %s) forwarding to %s", cfg.Syslog.UDPAddr, cfg.Syslog.TCPAddr, cfg.Syslog.Forward)
+			syslogServer.Serve(syslogCtx)
+		}()
+	}
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
@@ -110,18 +470,84 @@ func main() {
 
 	logger.Info("Shutting down servers...")
 
+	// Fail readiness first so load balancers stop routing new requests
+	// before the listeners close
+	checker.Drain()
+	if cfg.Health.DrainDelay > 0 {
+		logger.Infof("Draining for %s", cfg.Health.DrainDelay)
+		time.Sleep(cfg.Health.DrainDelay)
+	}
+	stopHealth()
+
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Shutdown HTTP server
-	if err := e.Shutdown(ctx); err != nil {
+	httpDrained := true
+	if singlePortServer != nil {
+		if err := singlePortServer.Shutdown(ctx); err != nil {
+			logger.Errorf("HTTP server shutdown error: %v", err)
+			httpDrained = false
+		}
+	} else if err := e.Shutdown(ctx); err != nil {
 		logger.Errorf("HTTP server shutdown error: %v", err)
+		httpDrained = false
 	}
 
-	// Shutdown gRPC server
-	grpcServer.GracefulStop()
+	// Shutdown proxy server
+	if proxyServer != nil {
+		if err := proxyServer.Shutdown(ctx); err != nil {
+			logger.Errorf("Proxy server shutdown error: %v", err)
+		}
+	}
+
+	// Shutdown syslog receiver
+	stopSyslog()
+
+	// Shutdown gRPC servers. Calls served through the HTTP server were
+	// drained by its shutdown; gRPC cannot drain them itself and panics if
+	// any are still running, so those servers are stopped at once.
+	stopGRPC(ctx, grpcServer, cfg.SinglePort && !httpDrained)
+	stopGRPC(ctx, gatewayServer, false)
+	stopGRPC(ctx, webServer, !httpDrained)
 
 	wg.Wait()
+
+	// Stop jobs; running jobs are resumed on the next start
+	stopJobs()
+	if jobManager != nil {
+		if err := jobManager.Close(); err != nil {
+			logger.Errorf("Job store close error: %v", err)
+		}
+	}
+
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Errorf("Tracing shutdown error: %v", err)
+	}
+
 	logger.Info("Servers stopped")
 }
+
+// stopGRPC stops server once its calls complete, or at once when ctx
+// expires or force is set
+func stopGRPC(ctx context.Context, server *grpcserver.Server, force bool) {
+	if force {
+		server.Stop()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
+		<-done
+	}
+}
@@ -0,0 +1,210 @@
+// Package convert translates validation and scan results between the service
+// types and the v1 and v2 protobuf APIs. All API layers use these functions so
+// that every version exposes the same fields in the same way.
+package convert
+
+import (
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/service"
+	pb "credit-card-validator/pkg/proto"
+	pbv2 "credit-card-validator/pkg/proto/v2"
+)
+
+// issueCodes maps service issue codes to their v2 enum values
+var issueCodes = map[service.IssueCode]pbv2.IssueCode{
+	service.IssueLuhnCheckFailed:     pbv2.IssueCode_ISSUE_CODE_LUHN_CHECK_FAILED,
+	service.IssueCardTypeNotAccepted: pbv2.IssueCode_ISSUE_CODE_CARD_TYPE_NOT_ACCEPTED,
+}
+
+// enrichmentStatuses maps service enrichment statuses to their v2 enum values
+var enrichmentStatuses = map[service.EnrichmentStatus]pbv2.EnrichmentStatus{
+	service.EnrichmentNotRequested: pbv2.EnrichmentStatus_ENRICHMENT_STATUS_NOT_REQUESTED,
+	service.EnrichmentDisabled:     pbv2.EnrichmentStatus_ENRICHMENT_STATUS_DISABLED,
+	service.EnrichmentSkipped:      pbv2.EnrichmentStatus_ENRICHMENT_STATUS_SKIPPED,
+	service.EnrichmentEnriched:     pbv2.EnrichmentStatus_ENRICHMENT_STATUS_ENRICHED,
+	service.EnrichmentFailed:       pbv2.EnrichmentStatus_ENRICHMENT_STATUS_FAILED,
+}
+
+// ToV1 converts a validation result to its v1 protobuf form. v1 has no BIN,
+// last four, issue or enrichment fields and truncates coordinates to whole
+// degrees.
+func ToV1(result *service.ValidationResult) *pb.ValidateCardResponse {
+	res := &pb.ValidateCardResponse{
+		Valid:      result.Valid,
+		CardType:   string(result.CardType),
+		CardNumber: result.CardNumber,
+		Scheme:     result.Scheme,
+		CardBrand:  result.CardBrand,
+		CardKind:   result.CardKind,
+	}
+
+	// Add country if available
+	if result.Country.Name != "" {
+		res.Country = &pb.Country{
+			Name:      result.Country.Name,
+			Alpha2:    result.Country.Alpha2,
+			Currency:  result.Country.Currency,
+			Emoji:     result.Country.Emoji,
+			Latitude:  int32(result.Country.Latitude),
+			Longitude: int32(result.Country.Longitude),
+		}
+	}
+
+	// Add bank if available
+	if result.Bank.Name != "" {
+		res.Bank = &pb.Bank{
+			Name:  result.Bank.Name,
+			Url:   result.Bank.URL,
+			Phone: result.Bank.Phone,
+		}
+	}
+
+	return res
+}
+
+// FromV1 converts a v1 response back to a validation result. Fields that v1
+// does not carry are left empty.
+func FromV1(res *pb.ValidateCardResponse) *service.ValidationResult {
+	result := &service.ValidationResult{
+		Valid:      res.Valid,
+		CardType:   service.CardType(res.CardType),
+		CardNumber: res.CardNumber,
+		Scheme:     res.Scheme,
+		CardBrand:  res.CardBrand,
+		CardKind:   res.CardKind,
+	}
+
+	if c := res.Country; c != nil {
+		result.Country = service.CountryInfo{
+			Name:      c.Name,
+			Alpha2:    c.Alpha2,
+			Currency:  c.Currency,
+			Emoji:     c.Emoji,
+			Latitude:  float64(c.Latitude),
+			Longitude: float64(c.Longitude),
+		}
+	}
+	if b := res.Bank; b != nil {
+		result.Bank = service.BankInfo{Name: b.Name, URL: b.Url, Phone: b.Phone}
+	}
+
+	return result
+}
+
+// ToV2 converts a validation result to its v2 protobuf form
+func ToV2(result *service.ValidationResult) *pbv2.ValidateCardResponse {
+	res := &pbv2.ValidateCardResponse{
+		Valid:            result.Valid,
+		CardType:         string(result.CardType),
+		CardNumber:       result.CardNumber,
+		Scheme:           result.Scheme,
+		CardBrand:        result.CardBrand,
+		CardKind:         result.CardKind,
+		Bin:              result.BIN,
+		LastFour:         result.LastFour,
+		EnrichmentStatus: enrichmentStatuses[result.EnrichmentStatus],
+		EnrichmentSource: result.EnrichmentSource,
+	}
+
+	for _, issue := range result.Issues {
+		res.Issues = append(res.Issues, issueCodes[issue])
+	}
+
+	// Add country if available
+	if result.Country.Name != "" {
+		res.Country = &pbv2.Country{
+			Name:      result.Country.Name,
+			Alpha2:    result.Country.Alpha2,
+			Currency:  result.Country.Currency,
+			Emoji:     result.Country.Emoji,
+			Latitude:  result.Country.Latitude,
+			Longitude: result.Country.Longitude,
+		}
+	}
+
+	// Add bank if available
+	if result.Bank.Name != "" {
+		res.Bank = &pbv2.Bank{
+			Name:  result.Bank.Name,
+			Url:   result.Bank.URL,
+			Phone: result.Bank.Phone,
+		}
+	}
+
+	return res
+}
+
+// FromV2 converts a v2 response back to a validation result. Unknown issue
+// codes and enrichment statuses are dropped.
+func FromV2(res *pbv2.ValidateCardResponse) *service.ValidationResult {
+	result := &service.ValidationResult{
+		Valid:            res.Valid,
+		CardType:         service.CardType(res.CardType),
+		CardNumber:       res.CardNumber,
+		Scheme:           res.Scheme,
+		CardBrand:        res.CardBrand,
+		CardKind:         res.CardKind,
+		BIN:              res.Bin,
+		LastFour:         res.LastFour,
+		EnrichmentSource: res.EnrichmentSource,
+	}
+
+	for _, code := range res.Issues {
+		for issue, c := range issueCodes {
+			if c == code {
+				result.Issues = append(result.Issues, issue)
+			}
+		}
+	}
+	for status, s := range enrichmentStatuses {
+		if s == res.EnrichmentStatus {
+			result.EnrichmentStatus = status
+		}
+	}
+
+	if c := res.Country; c != nil {
+		result.Country = service.CountryInfo{
+			Name:      c.Name,
+			Alpha2:    c.Alpha2,
+			Currency:  c.Currency,
+			Emoji:     c.Emoji,
+			Latitude:  c.Latitude,
+			Longitude: c.Longitude,
+		}
+	}
+	if b := res.Bank; b != nil {
+		result.Bank = service.BankInfo{Name: b.Name, URL: b.Url, Phone: b.Phone}
+	}
+
+	return result
+}
+
+// FindingsToV1 converts text scan findings to their v1 protobuf form
+func FindingsToV1(findings []dlp.Finding) []*pb.PanFinding {
+	out := make([]*pb.PanFinding, len(findings))
+	for i, f := range findings {
+		out[i] = &pb.PanFinding{
+			Start:      int32(f.Start),
+			End:        int32(f.End),
+			MaskedPan:  f.MaskedPAN,
+			CardType:   f.CardType.String(),
+			Confidence: f.Confidence,
+		}
+	}
+	return out
+}
+
+// FindingsToV2 converts text scan findings to their v2 protobuf form
+func FindingsToV2(findings []dlp.Finding) []*pbv2.PanFinding {
+	out := make([]*pbv2.PanFinding, len(findings))
+	for i, f := range findings {
+		out[i] = &pbv2.PanFinding{
+			Start:      int32(f.Start),
+			End:        int32(f.End),
+			MaskedPan:  f.MaskedPAN,
+			CardType:   f.CardType.String(),
+			Confidence: f.Confidence,
+		}
+	}
+	return out
+}
@@ -0,0 +1,241 @@
+// Package gateway serves the gRPC API as REST. Every RPC with a
+// google.api.http annotation in the proto files gets a route that transcodes
+// JSON requests to gRPC calls, so REST clients go through the same service
+// implementation, authentication and tenant resolution as gRPC clients.
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/apperror"
+	pb "credit-card-validator/pkg/proto"
+	pbv2 "credit-card-validator/pkg/proto/v2"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// services lists the proto files whose annotated RPCs are served
+var services = []protoreflect.FileDescriptor{
+	pb.File_pkg_proto_cardvalidator_proto,
+	pbv2.File_pkg_proto_v2_cardvalidator_proto,
+}
+
+// legacy maps the RPCs of the v1 validation routes to the v2 RPCs serving
+// them. These routes predate the gateway and return the service result, which
+// has fields and precision the v1 messages lack but the v2 messages carry.
+var legacy = map[string]string{
+	"/cardvalidator.CardValidator/ValidateCard":  "/cardvalidator.v2.CardValidator/ValidateCard",
+	"/cardvalidator.CardValidator/ValidateCards": "/cardvalidator.v2.CardValidator/ValidateCards",
+}
+
+// certSubjectHeader forwards the subject of the verified client certificate
+// of a request to the gRPC call
+var certSubjectHeader = http.CanonicalHeaderKey(grpcapi.CertSubjectMetadataKey)
+
+// pathParam matches a path template variable such as {id}
+var pathParam = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+
+// Route is the REST route of an annotated RPC
+type Route struct {
+	Method string
+	Path   string
+	// FullMethod is the full gRPC method name, e.g. /cardvalidator.CardValidator/ValidateCard
+	FullMethod string
+	// Body is the request field bound to the body, "*" for the whole request
+	Body   string
+	Input  protoreflect.MessageDescriptor
+	Output protoreflect.MessageDescriptor
+	// Streaming reports whether requests and responses are newline-delimited
+	// JSON message streams
+	Streaming bool
+	// Legacy reports whether the route is served by the v2 RPC and returns
+	// the service result as JSON instead of the message
+	Legacy bool
+}
+
+// Routes returns the REST routes declared by the google.api.http annotations
+func Routes() []Route {
+	var routes []Route
+	for _, file := range services {
+		for i := 0; i < file.Services().Len(); i++ {
+			svc := file.Services().Get(i)
+			for j := 0; j < svc.Methods().Len(); j++ {
+				m := svc.Methods().Get(j)
+				rule, ok := proto.GetExtension(m.Options(), annotations.E_Http).(*annotations.HttpRule)
+				if !ok || rule == nil {
+					continue
+				}
+
+				fullMethod := "/" + string(svc.FullName()) + "/" + string(m.Name())
+				for _, r := range append([]*annotations.HttpRule{rule}, rule.AdditionalBindings...) {
+					method, path := httpPattern(r)
+					if path == "" {
+						continue
+					}
+					routes = append(routes, Route{
+						Method:     method,
+						Path:       path,
+						FullMethod: fullMethod,
+						Body:       r.Body,
+						Input:      m.Input(),
+						Output:     m.Output(),
+						Streaming:  m.IsStreamingClient() || m.IsStreamingServer(),
+						Legacy:     legacy[fullMethod] != "",
+					})
+				}
+			}
+		}
+	}
+	return routes
+}
+
+// httpPattern returns the HTTP method and path template of a rule
+func httpPattern(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		return p.Custom.Kind, p.Custom.Path
+	}
+	return "", ""
+}
+
+// NewHandler returns a handler transcoding REST requests to calls on conn.
+// The API key, authorization, request ID, tenant and client certificate
+// headers are forwarded as call metadata, and the tenant the call was served as is returned in the
+// tenant header.
+func NewHandler(ctx context.Context, conn *grpc.ClientConn, tenantHeader string) (http.Handler, error) {
+	forwarded := map[string]bool{
+		"x-api-key":                            true,
+		grpcapi.CertSubjectMetadataKey:         true,
+		strings.ToLower(echo.HeaderXRequestID): true,
+		strings.ToLower(tenantHeader):          true,
+	}
+
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &marshaler{JSONPb: &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}}),
+		runtime.WithForwardResponseRewriter(rewriteLegacy),
+		runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			if name := strings.ToLower(key); forwarded[name] {
+				return name, true
+			}
+			return runtime.DefaultHeaderMatcher(key)
+		}),
+		// Echo already returns the request ID as X-Request-Id. The tenant
+		// is returned in the tenant header, as on the other REST routes.
+		runtime.WithOutgoingHeaderMatcher(func(key string) (string, bool) {
+			switch key {
+			case strings.ToLower(echo.HeaderXRequestID):
+				return "", false
+			case strings.ToLower(tenantHeader):
+				return tenantHeader, true
+			}
+			return runtime.MetadataHeaderPrefix + key, true
+		}),
+		runtime.WithErrorHandler(func(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+			if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+				if values := md.HeaderMD.Get(tenantHeader); len(values) > 0 {
+					w.Header().Set(tenantHeader, values[0])
+				}
+			}
+			writeError(ctx, mux, m, w, r, err)
+		}),
+	)
+
+	if err := pb.RegisterCardValidatorHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+	if err := pbv2.RegisterCardValidatorHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+
+	return mux, nil
+}
+
+// Register adds an Echo route for every annotated RPC, served by handler
+// behind the given middleware. Legacy routes are passed to handler as calls
+// of their v2 RPC. The request ID assigned by Echo is passed on to the gRPC
+// call, and so is the subject of the verified client certificate; a
+// certificate subject sent by the client is dropped.
+func Register(e *echo.Echo, handler http.Handler, m ...echo.MiddlewareFunc) {
+	routes := Routes()
+	paths := make(map[string]string, len(routes))
+	for _, route := range routes {
+		paths[route.FullMethod] = route.Path
+	}
+
+	serve := func(c echo.Context) error {
+		req := c.Request()
+		if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+			req.Header.Set(echo.HeaderXRequestID, id)
+		}
+		req.Header.Del(certSubjectHeader)
+		if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+			req.Header.Set(certSubjectHeader, req.TLS.PeerCertificates[0].Subject.String())
+		}
+		handler.ServeHTTP(c.Response(), req)
+		return nil
+	}
+
+	for _, route := range routes {
+		if !route.Legacy {
+			e.Add(route.Method, EchoPath(route.Path), serve, m...)
+			continue
+		}
+
+		target := paths[legacy[route.FullMethod]]
+		e.Add(route.Method, EchoPath(route.Path), func(c echo.Context) error {
+			req := c.Request()
+			u := *req.URL
+			u.Path, u.RawPath = target, ""
+			req = req.WithContext(context.WithValue(req.Context(), legacyKey{}, req.URL.Path))
+			req.URL = &u
+			c.SetRequest(req)
+			return serve(c)
+		}, m...)
+	}
+}
+
+// EchoPath converts a path template such as /jobs/{id} to the Echo form /jobs/:id
+func EchoPath(path string) string {
+	return pathParam.ReplaceAllString(path, ":$1")
+}
+
+// writeError writes a gRPC error as the problem details used by the REST
+// API, taking the error code and invalid fields from the status details.
+// Errors of legacy routes name the path the request was received on.
+func writeError(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if path, ok := r.Context().Value(legacyKey{}).(string); ok {
+		u := *r.URL
+		u.Path = path
+		r = r.Clone(r.Context())
+		r.URL = &u
+	}
+	apperror.Write(w, r, err)
+}
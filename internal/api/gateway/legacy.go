@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"credit-card-validator/internal/api/convert"
+	"credit-card-validator/internal/service"
+	pbv2 "credit-card-validator/pkg/proto/v2"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/proto"
+)
+
+// legacyKey is the context key of the path a request received on a legacy
+// route was sent to
+type legacyKey struct{}
+
+// legacyBody is a response of a legacy route, written as plain JSON
+type legacyBody struct {
+	value any
+}
+
+// legacyBatch is the response of the legacy batch route
+type legacyBatch struct {
+	Results []legacyItem `json:"results"`
+}
+
+// legacyItem holds either the result or the error of one batch item
+type legacyItem struct {
+	Index  int                       `json:"index"`
+	Result *service.ValidationResult `json:"result,omitempty"`
+	Error  string                    `json:"error,omitempty"`
+}
+
+// rewriteLegacy converts the v2 responses of calls received on a legacy
+// route to the service results these routes return
+func rewriteLegacy(ctx context.Context, resp proto.Message) (any, error) {
+	if ctx.Value(legacyKey{}) == nil {
+		return resp, nil
+	}
+
+	switch resp := resp.(type) {
+	case *pbv2.ValidateCardResponse:
+		return legacyBody{convert.FromV2(resp)}, nil
+	case *pbv2.ValidateCardsResponse:
+		batch := legacyBatch{Results: make([]legacyItem, len(resp.Results))}
+		for i, item := range resp.Results {
+			batch.Results[i] = legacyItem{Index: int(item.Index), Error: item.GetError()}
+			if result := item.GetResult(); result != nil {
+				batch.Results[i].Result = convert.FromV2(result)
+			}
+		}
+		return legacyBody{batch}, nil
+	}
+	return resp, nil
+}
+
+// marshaler writes legacy responses with encoding/json followed by a newline,
+// as these routes did before the gateway, and everything else as protobuf JSON
+type marshaler struct {
+	*runtime.JSONPb
+}
+
+func (m *marshaler) Marshal(v any) ([]byte, error) {
+	body, ok := v.(legacyBody)
+	if !ok {
+		return m.JSONPb.Marshal(v)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body.value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/logging"
+	pb "credit-card-validator/pkg/proto"
+	pbv2 "credit-card-validator/pkg/proto/v2"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// methodScopes maps full gRPC method names to the scope they require.
+// Methods that are not listed require the admin scope.
+var methodScopes = map[string]auth.Scope{
+	pb.CardValidator_ValidateCard_FullMethodName:       auth.ScopeValidate,
+	pb.CardValidator_ValidateCards_FullMethodName:      auth.ScopeValidate,
+	pb.CardValidator_ValidateCardStream_FullMethodName: auth.ScopeValidate,
+	pb.CardValidator_ScanText_FullMethodName:           auth.ScopeValidate,
+
+	pbv2.CardValidator_ValidateCard_FullMethodName:       auth.ScopeValidate,
+	pbv2.CardValidator_ValidateCards_FullMethodName:      auth.ScopeValidate,
+	pbv2.CardValidator_ValidateCardStream_FullMethodName: auth.ScopeValidate,
+	pbv2.CardValidator_ScanText_FullMethodName:           auth.ScopeValidate,
+}
+
+// publicServicePrefixes lists services that can be called without
+// credentials and outside of a tenant
+var publicServicePrefixes = []string{
+	"/grpc.reflection.",
+	"/grpc.health.",
+}
+
+// isPublic reports whether method belongs to a public service
+func isPublic(method string) bool {
+	for _, prefix := range publicServicePrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthUnaryInterceptor authenticates unary calls and enforces method scopes
+func AuthUnaryInterceptor(authenticator auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor authenticates streaming calls and enforces method scopes
+func AuthStreamInterceptor(authenticator auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize authenticates the caller and checks the scope required by method
+func authorize(ctx context.Context, authenticator auth.Authenticator, method string) (context.Context, error) {
+	if isPublic(method) {
+		return ctx, nil
+	}
+
+	identity, err := authenticator.Authenticate(ctx, credentialsFromMetadata(ctx))
+	if err != nil {
+		if errors.Is(err, auth.ErrMissingCredentials) || errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, apperror.New(apperror.CodeUnauthenticated, "authentication required")
+		}
+		return nil, apperror.Wrap(apperror.CodeInternal, err, "authentication failed")
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+			identity.CertSubject = info.State.PeerCertificates[0].Subject.String()
+		}
+	}
+	// Gateway calls arrive on loopback; the certificate was verified by the
+	// HTTP server
+	if identity.CertSubject == "" {
+		identity.CertSubject = forwardedCertSubject(ctx)
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = auth.ScopeAdmin
+	}
+	if !identity.HasScope(scope) {
+		return nil, apperror.Newf(apperror.CodePermissionDenied, "missing required scope: %s", scope)
+	}
+
+	ctx = logging.With(ctx, logging.FieldClient, identity.Subject)
+	return auth.NewContext(ctx, identity), nil
+}
+
+// credentialsFromMetadata extracts the credentials presented in call metadata
+func credentialsFromMetadata(ctx context.Context) auth.Credentials {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return auth.Credentials{}
+	}
+
+	var creds auth.Credentials
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		creds.APIKey = values[0]
+	}
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			creds.BearerToken = strings.TrimSpace(token)
+		} else if key, ok := strings.CutPrefix(value, "ApiKey "); ok && creds.APIKey == "" {
+			creds.APIKey = strings.TrimSpace(key)
+		}
+	}
+
+	return creds
+}
+
+// contextStream overrides the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
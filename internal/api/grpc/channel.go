@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"context"
+
+	"credit-card-validator/internal/audit"
+
+	"google.golang.org/grpc"
+)
+
+// channelKey is the context key of the audit channel of a call
+type channelKey struct{}
+
+// ChannelUnaryInterceptor records channel as the audit channel of unary
+// calls. The REST gateway uses it so its calls are audited as REST.
+func ChannelUnaryInterceptor(channel string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(context.WithValue(ctx, channelKey{}, channel), req)
+	}
+}
+
+// ChannelStreamInterceptor records channel as the audit channel of streaming calls
+func ChannelStreamInterceptor(channel string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := context.WithValue(ss.Context(), channelKey{}, channel)
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// auditChannel returns the audit channel of a call, gRPC unless set by an interceptor
+func auditChannel(ctx context.Context) string {
+	if channel, ok := ctx.Value(channelKey{}).(string); ok {
+		return channel
+	}
+	return audit.ChannelGRPC
+}
@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"credit-card-validator/internal/api/convert"
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/dlp"
+	pb "credit-card-validator/pkg/proto"
+)
+
+// ScanText finds card numbers in free text and returns them with a redacted
+// copy of the text
+func (s *Server) ScanText(ctx context.Context, req *pb.ScanTextRequest) (*pb.ScanTextResponse, error) {
+	result, err := s.scanText(req.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ScanTextResponse{
+		Findings:     convert.FindingsToV1(result.Findings),
+		RedactedText: result.RedactedText,
+	}, nil
+}
+
+// scanText scans text, mapping scanner errors to client errors
+func (s *Server) scanText(text string) (*dlp.Result, error) {
+	result, err := s.scanner.ScanText(text)
+	if err != nil {
+		if errors.Is(err, dlp.ErrTextTooLarge) {
+			return nil, apperror.From(err).WithField("text")
+		}
+		return nil, apperror.Wrap(apperror.CodeInternal, err, "scan failed")
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// CertSubjectMetadataKey carries the subject of the client certificate
+// verified by the HTTP server on calls made by the REST gateway
+const CertSubjectMetadataKey = "x-client-cert-subject"
+
+// certSubjectKey is the context key of a forwarded certificate subject
+type certSubjectKey struct{}
+
+// ForwardedCertUnaryInterceptor trusts the certificate subject forwarded in
+// CertSubjectMetadataKey on unary calls. Any caller can send the metadata,
+// so it must only be installed on the loopback server of the gateway.
+func ForwardedCertUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withForwardedCert(ctx), req)
+	}
+}
+
+// ForwardedCertStreamInterceptor trusts the certificate subject forwarded on
+// streaming calls, like ForwardedCertUnaryInterceptor
+func ForwardedCertStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withForwardedCert(ss.Context())})
+	}
+}
+
+// withForwardedCert stores the forwarded certificate subject of a call in ctx
+func withForwardedCert(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if values := md.Get(CertSubjectMetadataKey); len(values) > 0 && values[0] != "" {
+		return context.WithValue(ctx, certSubjectKey{}, values[0])
+	}
+	return ctx
+}
+
+// forwardedCertSubject returns the trusted forwarded certificate subject of a call
+func forwardedCertSubject(ctx context.Context) string {
+	subject, _ := ctx.Value(certSubjectKey{}).(string)
+	return subject
+}
@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"context"
+
+	"credit-card-validator/internal/logging"
+
+	"google.golang.org/grpc"
+)
+
+// LoggerUnaryInterceptor stores a request logger in the context of unary
+// calls, annotated with the request ID, method and channel. It must run
+// after the request ID interceptor; the auth and tenant interceptors add the
+// client and tenant.
+func LoggerUnaryInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withLogger(ctx, logger, info.FullMethod), req)
+	}
+}
+
+// LoggerStreamInterceptor stores a request logger in the context of streaming calls
+func LoggerStreamInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withLogger(ss.Context(), logger, info.FullMethod)
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// withLogger stores logger, annotated with the call, in ctx
+func withLogger(ctx context.Context, logger logging.Logger, method string) context.Context {
+	return logging.NewContext(ctx, logger.With(
+		logging.FieldRequestID, RequestID(ctx),
+		logging.FieldMethod, method,
+		"channel", auditChannel(ctx),
+	))
+}
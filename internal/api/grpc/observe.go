@@ -0,0 +1,94 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"credit-card-validator/internal/logging"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_grpc_requests_total",
+			Help: "Total number of gRPC calls by method, status code and channel",
+		},
+		[]string{"method", "code", "channel"},
+	)
+
+	grpcRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "card_validation_grpc_request_duration_seconds",
+			Help: "Duration of gRPC calls by method",
+		},
+		[]string{"method", "channel"},
+	)
+)
+
+// ObserveUnaryInterceptor records metrics and an access log entry for every
+// unary call. The entry is written with the request logger of the call when
+// the logger interceptor runs first, and with logger otherwise.
+func ObserveUnaryInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		observe(ctx, logger, info.FullMethod, false, start, err)
+		return res, err
+	}
+}
+
+// ObserveStreamInterceptor records metrics and an access log entry for
+// every streaming call once it ends
+func ObserveStreamInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observe(ss.Context(), logger, info.FullMethod, true, start, err)
+		return err
+	}
+}
+
+// observe records a finished call
+func observe(ctx context.Context, logger logging.Logger, method string, stream bool, start time.Time, err error) {
+	duration := time.Since(start)
+	code := status.Code(err)
+	channel := auditChannel(ctx)
+
+	grpcRequestsTotal.WithLabelValues(method, code.String(), channel).Inc()
+	grpcRequestDuration.WithLabelValues(method, channel).Observe(duration.Seconds())
+
+	// The request logger already carries the request ID, method and channel
+	entry := logging.FromContext(ctx, logger.With(
+		logging.FieldRequestID, RequestID(ctx),
+		logging.FieldMethod, method,
+		"channel", channel,
+	))
+
+	args := []any{
+		"code", code.String(),
+		"stream", stream,
+		"duration_ms", float64(duration.Microseconds()) / 1000,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		args = append(args, "peer", p.Addr.String())
+	}
+	if err != nil {
+		args = append(args, logging.FieldError, status.Convert(err).Message())
+	}
+
+	switch code {
+	case codes.OK:
+		entry.Info("gRPC call", args...)
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded, codes.Unimplemented:
+		entry.Error("gRPC call", args...)
+	default:
+		entry.Warn("gRPC call", args...)
+	}
+}
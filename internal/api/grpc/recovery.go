@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+	"runtime/debug"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/logging"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+)
+
+var panicsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "card_validation_grpc_panics_total",
+		Help: "Total number of panics recovered in gRPC handlers",
+	},
+	[]string{"method"},
+)
+
+// RecoveryUnaryInterceptor turns panics in unary handlers into Internal errors
+func RecoveryUnaryInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, logger, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor turns panics in streaming handlers into Internal
+// errors. Panics in goroutines started by a handler are not recovered.
+func RecoveryStreamInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), logger, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered logs a recovered panic with the request logger of the call and
+// returns the error sent to the client, which does not reveal the panic value
+func recovered(ctx context.Context, logger logging.Logger, method string, r interface{}) error {
+	panicsTotal.WithLabelValues(method).Inc()
+	logging.FromContext(ctx, logger.With(
+		logging.FieldRequestID, RequestID(ctx),
+		logging.FieldMethod, method,
+	)).Error("Recovered from panic in gRPC handler",
+		"panic", r,
+		"stack", string(debug.Stack()),
+	)
+	return apperror.New(apperror.CodeInternal, "internal error")
+}
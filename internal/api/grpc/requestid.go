@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/labstack/gommon/random"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadataKey is the metadata key carrying the request ID, the gRPC
+// form of the X-Request-Id header
+const RequestIDMetadataKey = "x-request-id"
+
+// requestIDKey is the context key of the request ID of a call
+type requestIDKey struct{}
+
+// RequestIDUnaryInterceptor assigns unary calls the request ID sent by the
+// client, or a new one, and returns it in the response headers
+func RequestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// RequestIDStreamInterceptor assigns streaming calls a request ID
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+// withRequestID stores the request ID of the call in ctx and sends it back
+func withRequestID(ctx context.Context) context.Context {
+	id := requestIDFromMetadata(ctx)
+	if id == "" {
+		id = random.String(32)
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, id))
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of a call, falling back to the one sent
+// by the client when no interceptor assigned one
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return requestIDFromMetadata(ctx)
+}
+
+// requestIDFromMetadata returns the request ID sent by the client, if any
+func requestIDFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -2,71 +2,164 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
+	"credit-card-validator/internal/api/convert"
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/logging"
 	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
 	pb "credit-card-validator/pkg/proto"
+	pbv2 "credit-card-validator/pkg/proto/v2"
 
-	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	pb.UnimplementedCardValidatorServer
-	validator *service.Validator
-	logger    *logrus.Logger
+	tenants  *tenant.Registry
+	auditLog *audit.Logger
+	batch    *config.BatchConfig
+	scanner  *dlp.Scanner
+	logger   logging.Logger
 }
 
-func NewServer(validator *service.Validator, logger *logrus.Logger) *Server {
+// NewServer creates the gRPC service. auditLog may be nil to disable
+// auditing. logger is used for calls whose context carries no request logger.
+func NewServer(tenants *tenant.Registry, auditLog *audit.Logger, batch *config.BatchConfig, scanner *dlp.Scanner, logger logging.Logger) *Server {
 	return &Server{
-		validator: validator,
-		logger:    logger,
+		tenants:  tenants,
+		auditLog: auditLog,
+		batch:    batch,
+		scanner:  scanner,
+		logger:   logger,
 	}
 }
 
+// RegisterServer registers the v1 and v2 APIs on grpcServer
 func (s *Server) RegisterServer(grpcServer *grpc.Server) {
 	pb.RegisterCardValidatorServer(grpcServer, s)
+	pbv2.RegisterCardValidatorServer(grpcServer, &serverV2{s: s})
 }
 
 func (s *Server) ValidateCard(ctx context.Context, req *pb.ValidateCardRequest) (*pb.ValidateCardResponse, error) {
-	s.logger.WithField("request_id", ctx.Value("request_id")).Info("gRPC ValidateCard called")
+	result, err := s.validate(ctx, "v1", req.CardNumber)
+	if err != nil {
+		return nil, err
+	}
+	return convert.ToV1(result), nil
+}
 
-	result, err := s.validator.ValidateCard(ctx, req.CardNumber)
+func (s *Server) ValidateCards(ctx context.Context, req *pb.ValidateCardsRequest) (*pb.ValidateCardsResponse, error) {
+	results, err := s.validateBatch(ctx, "v1", req.CardNumbers)
 	if err != nil {
-		s.logger.WithError(err).Error("Card validation failed")
-		return nil, status.Errorf(codes.Internal, "validation failed: %v", err)
+		return nil, err
 	}
 
-	res := &pb.ValidateCardResponse{
-		Valid:      result.Valid,
-		CardType:   string(result.CardType),
-		CardNumber: result.CardNumber,
-		Scheme:     result.Scheme,
-		CardBrand:  result.CardBrand,
-		CardKind:   result.CardKind,
+	res := &pb.ValidateCardsResponse{
+		Results: make([]*pb.ValidateCardsItem, len(results)),
+	}
+	for i, item := range results {
+		out := &pb.ValidateCardsItem{Index: int32(item.Index)}
+		if item.Err != nil {
+			out.Outcome = &pb.ValidateCardsItem_Error{Error: item.Err.Error()}
+		} else {
+			out.Outcome = &pb.ValidateCardsItem_Result{Result: convert.ToV1(item.Result)}
+		}
+		res.Results[i] = out
 	}
 
-	// Add country if available
-	if result.Country.Name != "" {
-		res.Country = &pb.Country{
-			Name:      result.Country.Name,
-			Alpha2:    result.Country.Alpha2,
-			Currency:  result.Country.Currency,
-			Emoji:     result.Country.Emoji,
-			Latitude:  int32(result.Country.Latitude),
-			Longitude: int32(result.Country.Longitude),
+	return res, nil
+}
+
+// validate validates a card for the calling tenant and audits the result.
+// version is the API version that received the call.
+func (s *Server) validate(ctx context.Context, version, cardNumber string) (*service.ValidationResult, error) {
+	validator := s.tenants.Validator(ctx)
+
+	s.log(ctx).Info("gRPC ValidateCard called", "version", version)
+
+	if cardNumber == "" {
+		return nil, apperror.New(apperror.CodeMissingField, "card_number is required").WithField("card_number")
+	}
+
+	// Issuer details are only returned to callers allowed to read BIN data
+	var (
+		result *service.ValidationResult
+		err    error
+	)
+	if auth.Allowed(ctx, auth.ScopeBINRead) {
+		result, err = validator.ValidateCard(ctx, cardNumber)
+	} else {
+		result, err = validator.ValidateCardSimple(cardNumber)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCardNumber) || errors.Is(err, service.ErrCardNumberTooShort) {
+			s.log(ctx).Warn("Card validation rejected", logging.FieldError, err)
+			return nil, apperror.From(err).WithField("card_number")
 		}
+		s.log(ctx).Error("Card validation failed", logging.FieldError, err)
+		return nil, apperror.Wrap(apperror.CodeInternal, err, "validation failed")
 	}
 
-	// Add bank if available
-	if result.Bank.Name != "" {
-		res.Bank = &pb.Bank{
-			Name:  result.Bank.Name,
-			Url:   result.Bank.URL,
-			Phone: result.Bank.Phone,
+	if s.auditLog != nil {
+		if err := s.auditLog.RecordValidation(ctx, auditChannel(ctx), RequestID(ctx), result); err != nil {
+			s.log(ctx).Error("Failed to write audit log", logging.FieldError, err)
+			return nil, apperror.Wrap(apperror.CodeAuditLogUnavailable, err, "audit log unavailable")
 		}
 	}
 
-	return res, nil
+	return result, nil
+}
+
+// validateBatch validates a batch of cards for the calling tenant and audits
+// every successful result
+func (s *Server) validateBatch(ctx context.Context, version string, cardNumbers []string) ([]service.BatchResult, error) {
+	validator := s.tenants.Validator(ctx)
+
+	s.log(ctx).Info("gRPC ValidateCards called", "items", len(cardNumbers), "version", version)
+
+	if len(cardNumbers) == 0 {
+		return nil, apperror.New(apperror.CodeMissingField, "card_numbers is required").WithField("card_numbers")
+	}
+	if len(cardNumbers) > s.batch.MaxItems {
+		return nil, apperror.Newf(apperror.CodeBatchTooLarge, "batch exceeds %d items", s.batch.MaxItems).WithField("card_numbers")
+	}
+	// The tenant interceptor charged the call as one validation; the other
+	// cards are charged as if they had been validated one by one
+	if t, ok := tenant.FromContext(ctx); ok && !t.AllowN(len(cardNumbers)-1) {
+		return nil, apperror.New(apperror.CodeRateLimited, "rate limit exceeded")
+	}
+
+	// Issuer details are only returned to callers allowed to read BIN data
+	var results []service.BatchResult
+	if auth.Allowed(ctx, auth.ScopeBINRead) {
+		results = validator.ValidateCards(ctx, cardNumbers, s.batch.Concurrency)
+	} else {
+		results = validator.ValidateCardsSimple(cardNumbers)
+	}
+
+	if s.auditLog != nil {
+		requestID := RequestID(ctx)
+		for _, item := range results {
+			if item.Err != nil {
+				continue
+			}
+			if err := s.auditLog.RecordValidation(ctx, auditChannel(ctx), requestID, item.Result); err != nil {
+				s.log(ctx).Error("Failed to write audit log", logging.FieldError, err)
+				return nil, apperror.Wrap(apperror.CodeAuditLogUnavailable, err, "audit log unavailable")
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// log returns the request logger of the call
+func (s *Server) log(ctx context.Context) logging.Logger {
+	return logging.FromContext(ctx, s.logger)
 }
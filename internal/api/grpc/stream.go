@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"credit-card-validator/internal/api/convert"
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	pb "credit-card-validator/pkg/proto"
+
+	"google.golang.org/grpc"
+)
+
+// ValidateCardStream validates cards sent on a bidirectional stream. Results
+// are sent as soon as they complete and carry the correlation ID of their
+// request. At most StreamConcurrency requests are in flight per stream; once
+// the limit is reached the server stops reading, so HTTP/2 flow control
+// pushes back on the client. Every card is charged to the tenant rate limit;
+// cards over the limit get an error result.
+func (s *Server) ValidateCardStream(stream grpc.BidiStreamingServer[pb.ValidateCardStreamRequest, pb.ValidateCardStreamResponse]) error {
+	return serveStream(s, stream, "v1",
+		func(req *pb.ValidateCardStreamRequest) (string, string) {
+			return req.CorrelationId, req.CardNumber
+		},
+		func(correlationID string, result *service.ValidationResult, err error) *pb.ValidateCardStreamResponse {
+			res := &pb.ValidateCardStreamResponse{CorrelationId: correlationID}
+			if err != nil {
+				res.Outcome = &pb.ValidateCardStreamResponse_Error{Error: err.Error()}
+			} else {
+				res.Outcome = &pb.ValidateCardStreamResponse_Result{Result: convert.ToV1(result)}
+			}
+			return res
+		})
+}
+
+// serveStream implements ValidateCardStream for every API version. request
+// extracts the correlation ID and card number of a request; response builds
+// the reply from a validation result or error.
+func serveStream[Req, Res any](
+	s *Server,
+	stream grpc.BidiStreamingServer[Req, Res],
+	version string,
+	request func(*Req) (string, string),
+	response func(string, *service.ValidationResult, error) *Res,
+) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	validator := s.tenants.Validator(ctx)
+	enrich := auth.Allowed(ctx, auth.ScopeBINRead)
+	requestID := RequestID(ctx)
+
+	s.log(ctx).Info("gRPC ValidateCardStream called", "version", version)
+
+	concurrency := s.batch.StreamConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var (
+		wg      sync.WaitGroup
+		sendMu  sync.Mutex
+		errOnce sync.Once
+		sendErr error
+	)
+
+	// fail records the first error and stops further processing
+	fail := func(err error) {
+		errOnce.Do(func() {
+			sendErr = err
+			cancel()
+		})
+	}
+
+	send := func(res *Res) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if err := stream.Send(res); err != nil {
+			fail(err)
+		}
+	}
+
+	// Requests are received in their own goroutine, so the stream ends as
+	// soon as processing fails instead of waiting for the next request
+	type received struct {
+		req *Req
+		err error
+	}
+	requests := make(chan received)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			select {
+			case requests <- received{req, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	t, limited := tenant.FromContext(ctx)
+
+	for {
+		var r received
+		select {
+		case r = <-requests:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if errors.Is(r.err, io.EOF) {
+			break
+		}
+		if r.err != nil {
+			fail(r.err)
+			break
+		}
+
+		correlationID, cardNumber := request(r.req)
+		if limited && !t.Allow() {
+			send(response(correlationID, nil, apperror.New(apperror.CodeRateLimited, "rate limit exceeded")))
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			var (
+				result *service.ValidationResult
+				err    error
+			)
+			if enrich {
+				result, err = validator.ValidateCard(ctx, cardNumber)
+			} else {
+				result, err = validator.ValidateCardSimple(cardNumber)
+			}
+
+			if err == nil && s.auditLog != nil {
+				if err := s.auditLog.RecordValidation(ctx, auditChannel(ctx), requestID, result); err != nil {
+					s.log(ctx).Error("Failed to write audit log", logging.FieldError, err)
+					fail(apperror.Wrap(apperror.CodeAuditLogUnavailable, err, "audit log unavailable"))
+					return
+				}
+			}
+
+			send(response(correlationID, result, err))
+		}()
+	}
+
+	wg.Wait()
+
+	if sendErr != nil {
+		return sendErr
+	}
+	return stream.Context().Err()
+}
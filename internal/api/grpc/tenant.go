@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TenantUnaryInterceptor resolves the tenant of unary calls and enforces its rate limit
+func TenantUnaryInterceptor(registry *tenant.Registry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := resolveTenant(ctx, registry)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// TenantStreamInterceptor resolves the tenant of streaming calls and enforces its rate limit
+func TenantStreamInterceptor(registry *tenant.Registry) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, err := resolveTenant(ss.Context(), registry)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// resolveTenant stores the tenant of the call in ctx and returns it in the
+// tenant header of the response
+func resolveTenant(ctx context.Context, registry *tenant.Registry) (context.Context, error) {
+	var requested string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(strings.ToLower(registry.Header())); len(values) > 0 {
+			requested = values[0]
+		}
+	}
+
+	identity, _ := auth.FromContext(ctx)
+	t, err := registry.Resolve(identity, requested)
+	if err != nil {
+		return nil, apperror.From(err)
+	}
+
+	// Return the tenant in the response headers, so the gateway and
+	// gRPC-Web routes can label their HTTP metrics with it
+	grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(registry.Header()), t.ID))
+
+	if !t.Allow() {
+		return nil, apperror.New(apperror.CodeRateLimited, "rate limit exceeded")
+	}
+
+	ctx = logging.With(ctx, logging.FieldTenant, t.ID)
+	return tenant.NewContext(ctx, t), nil
+}
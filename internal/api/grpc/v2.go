@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+
+	"credit-card-validator/internal/api/convert"
+	"credit-card-validator/internal/service"
+	pbv2 "credit-card-validator/pkg/proto/v2"
+
+	"google.golang.org/grpc"
+)
+
+// serverV2 serves the cardvalidator.v2 API. It shares validation, auditing
+// and scanning with the v1 server and only differs in the messages it returns.
+type serverV2 struct {
+	pbv2.UnimplementedCardValidatorServer
+	s *Server
+}
+
+func (v *serverV2) ValidateCard(ctx context.Context, req *pbv2.ValidateCardRequest) (*pbv2.ValidateCardResponse, error) {
+	result, err := v.s.validate(ctx, "v2", req.CardNumber)
+	if err != nil {
+		return nil, err
+	}
+	return convert.ToV2(result), nil
+}
+
+func (v *serverV2) ValidateCards(ctx context.Context, req *pbv2.ValidateCardsRequest) (*pbv2.ValidateCardsResponse, error) {
+	results, err := v.s.validateBatch(ctx, "v2", req.CardNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pbv2.ValidateCardsResponse{
+		Results: make([]*pbv2.ValidateCardsItem, len(results)),
+	}
+	for i, item := range results {
+		out := &pbv2.ValidateCardsItem{Index: int32(item.Index)}
+		if item.Err != nil {
+			out.Outcome = &pbv2.ValidateCardsItem_Error{Error: item.Err.Error()}
+		} else {
+			out.Outcome = &pbv2.ValidateCardsItem_Result{Result: convert.ToV2(item.Result)}
+		}
+		res.Results[i] = out
+	}
+
+	return res, nil
+}
+
+func (v *serverV2) ValidateCardStream(stream grpc.BidiStreamingServer[pbv2.ValidateCardStreamRequest, pbv2.ValidateCardStreamResponse]) error {
+	return serveStream(v.s, stream, "v2",
+		func(req *pbv2.ValidateCardStreamRequest) (string, string) {
+			return req.CorrelationId, req.CardNumber
+		},
+		func(correlationID string, result *service.ValidationResult, err error) *pbv2.ValidateCardStreamResponse {
+			res := &pbv2.ValidateCardStreamResponse{CorrelationId: correlationID}
+			if err != nil {
+				res.Outcome = &pbv2.ValidateCardStreamResponse_Error{Error: err.Error()}
+			} else {
+				res.Outcome = &pbv2.ValidateCardStreamResponse_Result{Result: convert.ToV2(result)}
+			}
+			return res
+		})
+}
+
+func (v *serverV2) ScanText(ctx context.Context, req *pbv2.ScanTextRequest) (*pbv2.ScanTextResponse, error) {
+	result, err := v.s.scanText(req.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.ScanTextResponse{
+		Findings:     convert.FindingsToV2(result.Findings),
+		RedactedText: result.RedactedText,
+	}, nil
+}
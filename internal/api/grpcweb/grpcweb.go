@@ -0,0 +1,73 @@
+// Package grpcweb serves the gRPC API to browsers. Calls made with the
+// gRPC-Web or Connect protocol on the HTTP port are transcoded to gRPC and
+// handled by a gRPC server, so browser clients use the same proto contract,
+// authentication and tenant resolution as gRPC clients.
+package grpcweb
+
+import (
+	"net/http"
+
+	pb "credit-card-validator/pkg/proto"
+	pbv2 "credit-card-validator/pkg/proto/v2"
+
+	"connectrpc.com/vanguard"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Services lists the gRPC services served to browsers
+var Services = []string{
+	pb.CardValidator_ServiceDesc.ServiceName,
+	pbv2.CardValidator_ServiceDesc.ServiceName,
+}
+
+// NewHandler returns a handler serving the Services registered on server
+// over gRPC-Web and Connect, with the proto and JSON codecs. JSON uses the
+// proto field names, as the REST API does.
+func NewHandler(server *grpc.Server) (http.Handler, error) {
+	// grpc.Server.ServeHTTP only accepts HTTP/2 requests. The transcoder
+	// frames calls as gRPC whatever the version of the browser connection,
+	// so requests are passed on as HTTP/2.
+	target := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Proto, r.ProtoMajor, r.ProtoMinor = "HTTP/2", 2, 0
+		server.ServeHTTP(w, r)
+	})
+
+	services := make([]*vanguard.Service, 0, len(Services))
+	for _, name := range Services {
+		services = append(services, vanguard.NewService(name, target,
+			vanguard.WithTargetProtocols(vanguard.ProtocolGRPC),
+			vanguard.WithTargetCodecs(vanguard.CodecProto),
+			vanguard.WithNoTargetCompression(),
+		))
+	}
+
+	return vanguard.NewTranscoder(services,
+		vanguard.WithCodec(func(res vanguard.TypeResolver) vanguard.Codec {
+			return &vanguard.JSONCodec{
+				MarshalOptions:   protojson.MarshalOptions{Resolver: res, UseProtoNames: true, EmitUnpopulated: true},
+				UnmarshalOptions: protojson.UnmarshalOptions{Resolver: res, DiscardUnknown: true},
+			}
+		}),
+	)
+}
+
+// Register routes the paths of the Services, /<service>/<method>, to
+// handler. The request ID assigned by Echo is passed on to the gRPC call,
+// which returns it in its response headers.
+func Register(e *echo.Echo, handler http.Handler) {
+	serve := func(c echo.Context) error {
+		req := c.Request()
+		if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+			req.Header.Set(echo.HeaderXRequestID, id)
+			c.Response().Header().Del(echo.HeaderXRequestID)
+		}
+		handler.ServeHTTP(c.Response(), req)
+		return nil
+	}
+
+	for _, name := range Services {
+		e.Any("/"+name+"/*", serve)
+	}
+}
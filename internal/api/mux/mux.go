@@ -0,0 +1,80 @@
+// Package mux serves gRPC and HTTP on a single listener. Requests are routed
+// on their content type: HTTP/2 requests of type application/grpc go to the
+// gRPC server and everything else, including gRPC-Web, to the HTTP handler.
+package mux
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Options configures which protocols require a verified client certificate.
+// The listener verifies certificates that are presented; requiring one is
+// left to the handler since the protocol is not known during the handshake.
+type Options struct {
+	GRPCClientCert bool
+	HTTPClientCert bool
+}
+
+// NewHandler returns a handler routing gRPC requests to grpcHandler, usually
+// a *grpc.Server, and all other requests to httpHandler
+func NewHandler(grpcHandler, httpHandler http.Handler, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsGRPC(r) {
+			if opts.GRPCClientCert && !hasClientCert(r) {
+				writeGRPCError(w, codes.Unauthenticated, "client certificate required")
+				return
+			}
+			grpcHandler.ServeHTTP(w, r)
+			return
+		}
+
+		if opts.HTTPClientCert && !hasClientCert(r) {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		httpHandler.ServeHTTP(w, r)
+	})
+}
+
+// IsGRPC reports whether r is a gRPC call: HTTP/2 with the content type
+// application/grpc or application/grpc+<codec>
+func IsGRPC(r *http.Request) bool {
+	if r.ProtoMajor != 2 {
+		return false
+	}
+	contentType := r.Header.Get("Content-Type")
+	return contentType == "application/grpc" ||
+		strings.HasPrefix(contentType, "application/grpc+") ||
+		strings.HasPrefix(contentType, "application/grpc;")
+}
+
+// Protocols returns the protocols of the shared server. Without TLS, gRPC
+// clients use HTTP/2 with prior knowledge (h2c), which is enabled alongside
+// HTTP/1.1.
+func Protocols(tls bool) *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if tls {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	return protocols
+}
+
+// hasClientCert reports whether the client presented a verified certificate
+func hasClientCert(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+}
+
+// writeGRPCError rejects a gRPC call with a trailers-only response
+func writeGRPCError(w http.ResponseWriter, code codes.Code, message string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(int(code)))
+	w.Header().Set("Grpc-Message", message)
+	w.WriteHeader(http.StatusOK)
+}
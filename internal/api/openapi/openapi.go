@@ -0,0 +1,488 @@
+// Package openapi generates the OpenAPI 3 document of the REST API from the
+// proto descriptors of the gateway routes and validates request bodies
+// against it.
+//
+// Every message becomes a component schema named after its full proto name.
+// Field names are the proto field names, as produced by the gateway, and
+// fields marked (google.api.field_behavior) = REQUIRED are required.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"credit-card-validator/internal/api/gateway"
+	"credit-card-validator/internal/apperror"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Version is the OpenAPI version of the generated document
+const Version = "3.0.3"
+
+// Media types of unary and streaming bodies and of errors
+const (
+	mediaJSON    = "application/json"
+	mediaNDJSON  = "application/x-ndjson"
+	mediaProblem = apperror.ContentType
+)
+
+// Names of the schemas that are not generated from messages
+const (
+	errorSchema       = "Error"
+	fieldErrorSchema  = "FieldError"
+	streamErrorSchema = "StreamError"
+	resultSchema      = "ValidationResult"
+	batchSchema       = "BatchValidateResponse"
+)
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path by lower-case HTTP method
+type PathItem map[string]*Operation
+
+// Operation is a single API operation
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a header, path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a response
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how clients authenticate
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	In     string `json:"in,omitempty"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// Schema is an OpenAPI 3 schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// Options configure the generated document
+type Options struct {
+	Title   string
+	Version string
+	// TenantHeader is documented as an optional header of every operation
+	TenantHeader string
+	// Auth documents the API key and bearer token security schemes
+	Auth bool
+	// MaxBatchItems limits the card numbers of batch requests
+	MaxBatchItems int
+	// MaxBodySize limits the request bodies read for validation; 0 disables
+	// the limit
+	MaxBodySize int64
+}
+
+// Spec is the OpenAPI document of the REST API together with the request
+// schemas used for validation
+type Spec struct {
+	doc         *Document
+	maxBodySize int64
+	// requests holds the request body schema by method and Echo path
+	requests map[string]*Schema
+}
+
+// New builds the document describing routes
+func New(routes []gateway.Route, opts Options) *Spec {
+	s := &Spec{
+		doc: &Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:       opts.Title,
+				Description: "REST API transcoded from the CardValidator gRPC services.",
+				Version:     opts.Version,
+			},
+			Paths: make(map[string]*PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+			},
+		},
+		requests:    make(map[string]*Schema),
+		maxBodySize: opts.MaxBodySize,
+	}
+	s.addErrorSchemas()
+	s.addResultSchemas()
+
+	for _, route := range routes {
+		s.addRoute(route, opts)
+	}
+
+	// Batches are limited by configuration rather than by the proto files
+	if opts.MaxBatchItems > 0 {
+		for name, schema := range s.doc.Components.Schemas {
+			if strings.HasSuffix(name, ".ValidateCardsRequest") {
+				schema.Properties["card_numbers"].MaxItems = intPtr(opts.MaxBatchItems)
+			}
+		}
+	}
+
+	if opts.Auth {
+		s.doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			"bearer": {Type: "http", Scheme: "bearer"},
+		}
+		s.doc.Security = []map[string][]string{{"apiKey": {}}, {"bearer": {}}}
+	}
+
+	return s
+}
+
+// Document returns the generated document
+func (s *Spec) Document() *Document {
+	return s.doc
+}
+
+// Handler serves the document as JSON
+func (s *Spec) Handler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, s.doc)
+	}
+}
+
+// addRoute documents the operation of a gateway route
+func (s *Spec) addRoute(route gateway.Route, opts Options) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(route.FullMethod, "/"), "/")
+	pkg := service[:strings.LastIndex(service, ".")]
+
+	media, input, output := mediaJSON, s.message(route.Input), s.message(route.Output)
+	if route.Legacy {
+		// Legacy v1 routes return the service result rather than the message
+		output = ref(resultSchema)
+		if route.Output.Name() == "ValidateCardsResponse" {
+			output = ref(batchSchema)
+		}
+	}
+	errorResponse := &Response{
+		Description: "Error",
+		Content:     map[string]*MediaType{mediaProblem: {Schema: ref(errorSchema)}},
+	}
+	if route.Streaming {
+		// The gateway wraps every streamed message in a result or error
+		media = mediaNDJSON
+		output = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"result": output,
+				"error":  ref(streamErrorSchema),
+			},
+		}
+	}
+
+	op := &Operation{
+		OperationID: service + "_" + method,
+		Summary:     method,
+		Tags:        []string{pkg},
+		RequestBody: &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{media: {Schema: input}},
+		},
+		Responses: map[string]*Response{
+			"200":     {Description: "OK", Content: map[string]*MediaType{media: {Schema: output}}},
+			"default": errorResponse,
+		},
+	}
+	if opts.TenantHeader != "" {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:        opts.TenantHeader,
+			In:          "header",
+			Description: "Tenant to serve the request as",
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	item, ok := s.doc.Paths[route.Path]
+	if !ok {
+		item = &PathItem{}
+		s.doc.Paths[route.Path] = item
+	}
+	(*item)[strings.ToLower(route.Method)] = op
+
+	// Streams carry one message per line and are not validated as a whole
+	if !route.Streaming && route.Body == "*" {
+		s.requests[route.Method+" "+gateway.EchoPath(route.Path)] = input
+	}
+}
+
+// message returns a reference to the schema of md, generating it and the
+// schemas of the messages it uses on first use
+func (s *Spec) message(md protoreflect.MessageDescriptor) *Schema {
+	name := string(md.FullName())
+	if _, ok := s.doc.Components.Schemas[name]; ok {
+		return ref(name)
+	}
+
+	schema := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	// Register before the fields so recursive messages terminate
+	s.doc.Components.Schemas[name] = schema
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		field := s.field(fd)
+
+		if isRequired(fd) {
+			schema.Required = append(schema.Required, string(fd.Name()))
+			switch {
+			case field.Type == "array":
+				field.MinItems = intPtr(1)
+			case field.Type == "string" && fd.Kind() == protoreflect.StringKind:
+				field.MinLength = intPtr(1)
+			}
+		}
+		schema.Properties[string(fd.Name())] = field
+	}
+
+	return ref(name)
+}
+
+// field returns the schema of a message field
+func (s *Spec) field(fd protoreflect.FieldDescriptor) *Schema {
+	switch {
+	case fd.IsMap():
+		return &Schema{Type: "object", AdditionalProperties: s.singular(fd.MapValue())}
+	case fd.IsList():
+		return &Schema{Type: "array", Items: s.singular(fd)}
+	}
+
+	schema := s.singular(fd)
+	if fd.Kind() == protoreflect.MessageKind {
+		// Unset messages are written as null
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+	return schema
+}
+
+// singular returns the schema of a single value of fd
+func (s *Spec) singular(fd protoreflect.FieldDescriptor) *Schema {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+	case protoreflect.StringKind:
+		return &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &Schema{Type: "string", Format: "byte"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson writes 64-bit integers as strings
+		return &Schema{Type: "string", Format: "int64"}
+	case protoreflect.FloatKind:
+		return &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return &Schema{Type: "number", Format: "double"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		schema := &Schema{Type: "string", Enum: make([]string, values.Len())}
+		for i := 0; i < values.Len(); i++ {
+			schema.Enum[i] = string(values.Get(i).Name())
+		}
+		return schema
+	default:
+		return s.message(fd.Message())
+	}
+}
+
+// addErrorSchemas adds the schemas of error bodies
+func (s *Spec) addErrorSchemas() {
+	schemas := s.doc.Components.Schemas
+	schemas[fieldErrorSchema] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"field":   {Type: "string", Description: "Path of the invalid field, e.g. card_numbers[2]"},
+			"message": {Type: "string"},
+		},
+		Required: []string{"field", "message"},
+	}
+	schemas[errorSchema] = &Schema{
+		Type:        "object",
+		Description: "RFC 9457 problem details",
+		Properties: map[string]*Schema{
+			"type":       {Type: "string", Description: "Problem type URI"},
+			"title":      {Type: "string"},
+			"status":     {Type: "integer", Format: "int32", Description: "HTTP status code"},
+			"detail":     {Type: "string"},
+			"instance":   {Type: "string", Description: "Request path"},
+			"code":       {Type: "string", Description: "Stable error code, e.g. INVALID_CARD_NUMBER"},
+			"request_id": {Type: "string"},
+			"error":      {Type: "string", Description: "Same as detail"},
+			"fields":     {Type: "array", Items: ref(fieldErrorSchema), Description: "Invalid request fields"},
+		},
+		Required: []string{"type", "title", "status", "code", "error"},
+	}
+	schemas[streamErrorSchema] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Format: "int32", Description: "gRPC status code"},
+			"message": {Type: "string"},
+		},
+	}
+}
+
+// addResultSchemas adds the schemas of the service results returned by the
+// legacy v1 validation routes
+func (s *Spec) addResultSchemas() {
+	str := func() *Schema { return &Schema{Type: "string"} }
+	double := func() *Schema { return &Schema{Type: "number", Format: "double"} }
+
+	schemas := s.doc.Components.Schemas
+	schemas[resultSchema] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"valid":       {Type: "boolean"},
+			"card_type":   str(),
+			"card_number": str(),
+			"scheme":      str(),
+			"card_brand":  str(),
+			"card_kind":   str(),
+			"country": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"name": str(), "alpha2": str(), "currency": str(), "emoji": str(),
+					"latitude": double(), "longitude": double(),
+				},
+			},
+			"bank": {
+				Type:       "object",
+				Properties: map[string]*Schema{"name": str(), "url": str(), "phone": str()},
+			},
+			"bin":               str(),
+			"last_four":         str(),
+			"issues":            {Type: "array", Items: str()},
+			"enrichment_status": str(),
+			"enrichment_source": str(),
+		},
+		Required: []string{"valid", "card_type", "card_number"},
+	}
+	schemas[batchSchema] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"results": {
+				Type: "array",
+				Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"index":  {Type: "integer", Format: "int32"},
+						"result": ref(resultSchema),
+						"error":  str(),
+					},
+					Required: []string{"index"},
+				},
+			},
+		},
+		Required: []string{"results"},
+	}
+}
+
+// isRequired reports whether fd is annotated as a required field
+func isRequired(fd protoreflect.FieldDescriptor) bool {
+	behaviors, _ := proto.GetExtension(fd.Options(), annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	for _, b := range behaviors {
+		if b == annotations.FieldBehavior_REQUIRED {
+			return true
+		}
+	}
+	return false
+}
+
+// ref returns a reference to a component schema
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// resolve returns the component schema a reference points to
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for schema.Ref != "" {
+		schema = s.doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func intPtr(v int) *int {
+	return &v
+}
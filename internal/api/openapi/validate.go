@@ -0,0 +1,195 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"credit-card-validator/internal/apperror"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FieldError describes why a request field is invalid. Field is the path of
+// the field, e.g. card_numbers[2], and empty for the body itself.
+type FieldError = apperror.FieldViolation
+
+// ValidateRequests rejects request bodies that do not match the schema of
+// their route with 400 and the invalid fields, and bodies above the size
+// limit with 413. Routes without a request schema are passed through.
+func (s *Spec) ValidateRequests() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			schema, ok := s.requests[req.Method+" "+c.Path()]
+			if !ok {
+				return next(c)
+			}
+
+			if s.maxBodySize > 0 {
+				req.Body = http.MaxBytesReader(c.Response(), req.Body, s.maxBodySize)
+			}
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					return apperror.Respond(c, apperror.Newf(apperror.CodePayloadTooLarge, "Request body exceeds %d bytes", s.maxBodySize))
+				}
+				return apperror.Respond(c, apperror.Wrap(apperror.CodeInvalidArgument, err, "Invalid request format"))
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			if errs := s.Validate(schema, body); len(errs) > 0 {
+				return apperror.Respond(c, &apperror.Error{
+					Code:    apperror.CodeInvalidArgument,
+					Message: "Request validation failed",
+					Fields:  errs,
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// Validate checks a JSON body against schema. An empty body is treated as an
+// empty object, as the gateway does.
+func (s *Spec) Validate(schema *Schema, body []byte) []FieldError {
+	if len(bytes.TrimSpace(body)) == 0 {
+		body = []byte("{}")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []FieldError{{Message: "invalid JSON: " + err.Error()}}
+	}
+	if decoder.More() {
+		return []FieldError{{Message: "invalid JSON: unexpected data after the body"}}
+	}
+
+	var errs []FieldError
+	s.validate(schema, value, "", &errs)
+	return errs
+}
+
+// validate appends the errors of value against schema to errs
+func (s *Spec) validate(schema *Schema, value interface{}, path string, errs *[]FieldError) {
+	schema = s.resolve(schema)
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if !schema.Nullable {
+			fail("must not be null")
+		}
+		return
+	}
+	for _, sub := range schema.AllOf {
+		s.validate(sub, value, path, errs)
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range schema.Required {
+			if v, ok := obj[name]; !ok || v == nil {
+				*errs = append(*errs, FieldError{Field: join(path, name), Message: "is required"})
+			}
+		}
+		for _, name := range sortedKeys(obj) {
+			if prop, ok := schema.Properties[name]; ok {
+				s.validate(prop, obj[name], join(path, name), errs)
+			} else if sub, ok := schema.AdditionalProperties.(*Schema); ok {
+				s.validate(sub, obj[name], join(path, name), errs)
+			} else if schema.AdditionalProperties == false {
+				*errs = append(*errs, FieldError{Field: join(path, name), Message: "is not a known field"})
+			}
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			if *schema.MinItems == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must have at least %d items", *schema.MinItems)
+			}
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			fail("must have at most %d items", *schema.MaxItems)
+		}
+		for i, item := range items {
+			s.validate(schema.Items, item, path+"["+strconv.Itoa(i)+"]", errs)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if schema.MinLength != nil && utf8.RuneCountInString(str) < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters", *schema.MinLength)
+			}
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, str) {
+			fail("must be one of %v", schema.Enum)
+		}
+
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			fail("must be an integer")
+			return
+		}
+		if _, err := n.Int64(); err != nil {
+			fail("must be an integer")
+		}
+
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			fail("must be a number")
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+// join returns the path of a property of the object at path
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
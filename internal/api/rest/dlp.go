@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ScanFileResponse struct {
+	File     string            `json:"file"`
+	Findings []dlp.FileFinding `json:"findings"`
+}
+
+// ScanFile finds card numbers in an uploaded file. Text files, gzip and tar
+// archives, XLSX, DOCX, ODS and PDF documents are supported.
+func (h *Handler) ScanFile(c echo.Context) error {
+	req := c.Request()
+	if limit := h.scanner.MaxFileSize(); limit > 0 {
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return apperror.Respond(c, apperror.New(apperror.CodePayloadTooLarge, "File exceeds the upload limit").WithField("file"))
+		}
+		return apperror.Respond(c, apperror.New(apperror.CodeMissingField, "File is required").WithField("file"))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		h.log(c).Error("Failed to open upload", logging.FieldError, err)
+		return apperror.Respond(c, apperror.Wrap(apperror.CodeInvalidArgument, err, "Invalid file upload").WithField("file"))
+	}
+	defer src.Close()
+
+	name := filepath.Base(file.Filename)
+	findings, err := h.scanner.ScanFile(req.Context(), name, src, nil)
+	if err != nil {
+		h.log(c).Warn("Failed to scan upload", logging.FieldError, err)
+		// Documents above the size limits keep their own code; other files
+		// could not be read
+		var appErr *apperror.Error
+		if !errors.As(err, &appErr) {
+			err = apperror.New(apperror.CodeUnprocessable, err.Error())
+		}
+		return apperror.Respond(c, err)
+	}
+	if findings == nil {
+		findings = []dlp.FileFinding{}
+	}
+
+	return c.JSON(http.StatusOK, ScanFileResponse{
+		File:     name,
+		Findings: findings,
+	})
+}
@@ -1,68 +1,66 @@
 package rest
 
 import (
-	"net/http"
-
-	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/jobs"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/middleware"
+	"credit-card-validator/internal/tenant"
 
 	"github.com/labstack/echo/v4"
-	"github.com/sirupsen/logrus"
 )
 
 type Handler struct {
-	validator *service.Validator
-	logger    *logrus.Logger
-}
-
-type ValidateRequest struct {
-	CardNumber string `json:"card_number" validate:"required"`
+	tenants       *tenant.Registry
+	jobs          *jobs.Manager
+	jobsConfig    *config.JobsConfig
+	scanner       *dlp.Scanner
+	logger        logging.Logger
+	authenticator auth.Authenticator
 }
 
-func NewHandler(validator *service.Validator, logger *logrus.Logger) *Handler {
+// NewHandler creates the REST handler. jobManager may be nil to disable bulk
+// jobs.
+func NewHandler(tenants *tenant.Registry, jobManager *jobs.Manager, jobsConfig *config.JobsConfig, scanner *dlp.Scanner, logger logging.Logger) *Handler {
 	return &Handler{
-		validator: validator,
-		logger:    logger,
+		tenants:    tenants,
+		jobs:       jobManager,
+		jobsConfig: jobsConfig,
+		scanner:    scanner,
+		logger:     logger,
 	}
 }
 
-func (h *Handler) RegisterRoutes(e *echo.Echo) {
-	api := e.Group("/api/v1")
-	api.POST("/validate", h.ValidateCard)
-}
+// RegisterRoutes registers the API routes that have no gRPC counterpart; the
+// others are served by the gateway. When authenticator is not nil every route
+// requires credentials carrying the scope of the route. Requests are served
+// by the validator of the tenant they resolve to.
+func (h *Handler) RegisterRoutes(e *echo.Echo, authenticator auth.Authenticator) {
+	h.authenticator = authenticator
 
-func (h *Handler) ValidateCard(c echo.Context) error {
-	var req ValidateRequest
-	if err := c.Bind(&req); err != nil {
-		h.logger.WithError(err).Error("Failed to bind request")
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request format",
-		})
+	api := e.Group("/api/v1")
+	if authenticator != nil {
+		api.Use(middleware.Authenticate(authenticator))
 	}
+	api.Use(middleware.Tenant(h.tenants))
+	api.POST("/dlp/scan/file", h.ScanFile, h.requireScope(auth.ScopeValidate)...)
 
-	if req.CardNumber == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Card number is required",
-		})
+	if h.jobs != nil {
+		h.registerJobRoutes(api)
 	}
+}
 
-	result, err := h.validator.ValidateCard(c.Request().Context(), req.CardNumber)
-	if err != nil {
-		h.logger.WithError(err).Error("Validation failed")
-
-		var status int
-		switch err {
-		case service.ErrInvalidCardNumber, service.ErrCardNumberTooShort:
-			status = http.StatusBadRequest
-		case service.ErrBINLookupFailed, service.ErrInvalidBINResponse:
-			status = http.StatusServiceUnavailable
-		default:
-			status = http.StatusInternalServerError
-		}
-
-		return c.JSON(status, map[string]string{
-			"error": err.Error(),
-		})
+// requireScope returns the scope check for a route when authentication is enabled
+func (h *Handler) requireScope(scope auth.Scope) []echo.MiddlewareFunc {
+	if h.authenticator == nil {
+		return nil
 	}
+	return []echo.MiddlewareFunc{middleware.RequireScope(scope)}
+}
 
-	return c.JSON(http.StatusOK, result)
+// log returns the request logger of c
+func (h *Handler) log(c echo.Context) logging.Logger {
+	return logging.FromContext(c.Request().Context(), h.logger)
 }
@@ -0,0 +1,136 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/jobs"
+	"credit-card-validator/internal/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+// registerJobRoutes registers the bulk job routes on the API group
+func (h *Handler) registerJobRoutes(api *echo.Group) {
+	scope := h.requireScope(auth.ScopeValidate)
+	api.POST("/jobs", h.SubmitJob, scope...)
+	api.GET("/jobs/:id", h.GetJob, scope...)
+	api.GET("/jobs/:id/results", h.GetJobResults, scope...)
+	api.DELETE("/jobs/:id", h.CancelJob, scope...)
+}
+
+// SubmitJob queues a job for an uploaded CSV or NDJSON file. The format is
+// taken from the "format" form field or the file extension.
+func (h *Handler) SubmitJob(c echo.Context) error {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, h.jobsConfig.MaxUploadSize)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return apperror.Respond(c, apperror.New(apperror.CodePayloadTooLarge, "File exceeds the upload limit").WithField("file"))
+		}
+		return apperror.Respond(c, apperror.New(apperror.CodeMissingField, "File is required").WithField("file"))
+	}
+
+	formatName := c.FormValue("format")
+	if formatName == "" {
+		formatName = filepath.Ext(file.Filename)
+	}
+	format, err := jobs.ParseFormat(formatName)
+	if err != nil {
+		return apperror.Respond(c, apperror.From(err).WithField("format"))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		h.log(c).Error("Failed to open upload", logging.FieldError, err)
+		return apperror.Respond(c, apperror.Wrap(apperror.CodeInvalidArgument, err, "Invalid file upload").WithField("file"))
+	}
+	defer src.Close()
+
+	// Issuer details are only returned to callers allowed to read BIN data
+	ctx := req.Context()
+	job, err := h.jobs.Submit(ctx, file.Filename, format, src, auth.Allowed(ctx, auth.ScopeBINRead))
+	if err != nil {
+		h.log(c).Error("Failed to submit job", logging.FieldError, err)
+		if errors.Is(err, jobs.ErrQueueFull) {
+			return apperror.Respond(c, err)
+		}
+		return apperror.Respond(c, apperror.Wrap(apperror.CodeInternal, err, "Failed to submit job"))
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, "/api/v1/jobs/"+job.ID)
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob returns the status and progress of a job
+func (h *Handler) GetJob(c echo.Context) error {
+	job, err := h.jobs.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// GetJobResults streams the masked results of a finished job as CSV or
+// NDJSON, defaulting to the input format
+func (h *Handler) GetJobResults(c echo.Context) error {
+	ctx := c.Request().Context()
+	job, err := h.jobs.Get(ctx, c.Param("id"))
+	if err != nil {
+		return h.jobError(c, err)
+	}
+
+	format := job.Format
+	if name := c.QueryParam("format"); name != "" {
+		if format, err = jobs.ParseFormat(name); err != nil {
+			return apperror.Respond(c, apperror.From(err).WithField("format"))
+		}
+	}
+
+	// Open the results before sending headers, so jobs without results get
+	// an error response
+	results, err := h.jobs.OpenResults(ctx, job.ID)
+	if err != nil {
+		return h.jobError(c, err)
+	}
+	defer results.Close()
+
+	contentType := "application/x-ndjson"
+	if format == jobs.FormatCSV {
+		contentType = "text/csv"
+	}
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\""+job.ID+"-results."+string(format)+"\"")
+	c.Response().WriteHeader(http.StatusOK)
+
+	if err := jobs.WriteResults(c.Response(), results, format); err != nil {
+		// Headers are already sent, so the error can only be logged
+		h.log(c).Error("Failed to write job results", "job_id", job.ID, logging.FieldError, err)
+	}
+	return nil
+}
+
+// CancelJob cancels a queued or running job
+func (h *Handler) CancelJob(c echo.Context) error {
+	job, err := h.jobs.Cancel(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.jobError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// jobError writes the problem details of a failed job request. The job
+// errors carry their own status; any other error is logged as unexpected.
+func (h *Handler) jobError(c echo.Context, err error) error {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		h.log(c).Error("Job request failed", logging.FieldError, err)
+	}
+	return apperror.Respond(c, err)
+}
@@ -0,0 +1,304 @@
+// Package apperror defines the errors reported to API clients. Each error
+// carries a stable code that clients can match on, which determines the HTTP
+// status of REST responses and the status code of gRPC calls.
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Domain identifies the service in the google.rpc.ErrorInfo of gRPC errors
+const Domain = "credit-card-validator"
+
+// Code is a stable, machine-readable error code
+type Code string
+
+// General error codes, one per gRPC status code
+const (
+	CodeInvalidArgument    Code = "INVALID_ARGUMENT"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeUnauthenticated    Code = "UNAUTHENTICATED"
+	CodePermissionDenied   Code = "PERMISSION_DENIED"
+	CodeResourceExhausted  Code = "RESOURCE_EXHAUSTED"
+	CodeFailedPrecondition Code = "FAILED_PRECONDITION"
+	CodeConflict           Code = "CONFLICT"
+	CodeUnimplemented      Code = "UNIMPLEMENTED"
+	CodeUnavailable        Code = "UNAVAILABLE"
+	CodeDeadlineExceeded   Code = "DEADLINE_EXCEEDED"
+	CodeCanceled           Code = "CANCELED"
+	CodeInternal           Code = "INTERNAL"
+)
+
+// Error codes of specific failures
+const (
+	CodeMissingField        Code = "MISSING_FIELD"
+	CodeInvalidCardNumber   Code = "INVALID_CARD_NUMBER"
+	CodeCardNumberTooShort  Code = "CARD_NUMBER_TOO_SHORT"
+	CodeBatchTooLarge       Code = "BATCH_TOO_LARGE"
+	CodePayloadTooLarge     Code = "PAYLOAD_TOO_LARGE"
+	CodeTextTooLarge        Code = "TEXT_TOO_LARGE"
+	CodeDocumentTooLarge    Code = "DOCUMENT_TOO_LARGE"
+	CodeUnsupportedFormat   Code = "UNSUPPORTED_FORMAT"
+	CodeUnprocessable       Code = "UNPROCESSABLE_CONTENT"
+	CodeUnknownTenant       Code = "UNKNOWN_TENANT"
+	CodeTenantMismatch      Code = "TENANT_MISMATCH"
+	CodeRateLimited         Code = "RATE_LIMITED"
+	CodeJobNotFound         Code = "JOB_NOT_FOUND"
+	CodeJobNotFinished      Code = "JOB_NOT_FINISHED"
+	CodeJobFinished         Code = "JOB_FINISHED"
+	CodeJobResultsNotFound  Code = "JOB_RESULTS_NOT_FOUND"
+	CodeQueueFull           Code = "QUEUE_FULL"
+	CodeBINLookupFailed     Code = "BIN_LOOKUP_FAILED"
+	CodeInvalidBINResponse  Code = "INVALID_BIN_RESPONSE"
+	CodeAuditLogUnavailable Code = "AUDIT_LOG_UNAVAILABLE"
+)
+
+// mapping is the HTTP status and gRPC code of an error code
+type mapping struct {
+	status int
+	code   codes.Code
+}
+
+var mappings = map[Code]mapping{
+	CodeInvalidArgument:    {http.StatusBadRequest, codes.InvalidArgument},
+	CodeNotFound:           {http.StatusNotFound, codes.NotFound},
+	CodeUnauthenticated:    {http.StatusUnauthorized, codes.Unauthenticated},
+	CodePermissionDenied:   {http.StatusForbidden, codes.PermissionDenied},
+	CodeResourceExhausted:  {http.StatusTooManyRequests, codes.ResourceExhausted},
+	CodeFailedPrecondition: {http.StatusBadRequest, codes.FailedPrecondition},
+	CodeConflict:           {http.StatusConflict, codes.Aborted},
+	CodeUnimplemented:      {http.StatusNotImplemented, codes.Unimplemented},
+	CodeUnavailable:        {http.StatusServiceUnavailable, codes.Unavailable},
+	CodeDeadlineExceeded:   {http.StatusGatewayTimeout, codes.DeadlineExceeded},
+	CodeCanceled:           {499, codes.Canceled},
+	CodeInternal:           {http.StatusInternalServerError, codes.Internal},
+
+	CodeMissingField:        {http.StatusBadRequest, codes.InvalidArgument},
+	CodeInvalidCardNumber:   {http.StatusBadRequest, codes.InvalidArgument},
+	CodeCardNumberTooShort:  {http.StatusBadRequest, codes.InvalidArgument},
+	CodeBatchTooLarge:       {http.StatusBadRequest, codes.InvalidArgument},
+	CodePayloadTooLarge:     {http.StatusRequestEntityTooLarge, codes.InvalidArgument},
+	CodeTextTooLarge:        {http.StatusRequestEntityTooLarge, codes.InvalidArgument},
+	CodeDocumentTooLarge:    {http.StatusRequestEntityTooLarge, codes.InvalidArgument},
+	CodeUnsupportedFormat:   {http.StatusBadRequest, codes.InvalidArgument},
+	CodeUnprocessable:       {http.StatusUnprocessableEntity, codes.InvalidArgument},
+	CodeUnknownTenant:       {http.StatusBadRequest, codes.InvalidArgument},
+	CodeTenantMismatch:      {http.StatusForbidden, codes.PermissionDenied},
+	CodeRateLimited:         {http.StatusTooManyRequests, codes.ResourceExhausted},
+	CodeJobNotFound:         {http.StatusNotFound, codes.NotFound},
+	CodeJobNotFinished:      {http.StatusConflict, codes.FailedPrecondition},
+	CodeJobFinished:         {http.StatusConflict, codes.FailedPrecondition},
+	CodeJobResultsNotFound:  {http.StatusNotFound, codes.NotFound},
+	CodeQueueFull:           {http.StatusServiceUnavailable, codes.Unavailable},
+	CodeBINLookupFailed:     {http.StatusBadGateway, codes.Unavailable},
+	CodeInvalidBINResponse:  {http.StatusBadGateway, codes.Unavailable},
+	CodeAuditLogUnavailable: {http.StatusServiceUnavailable, codes.Unavailable},
+}
+
+// grpcCodes maps gRPC codes without error details to general error codes
+var grpcCodes = map[codes.Code]Code{
+	codes.InvalidArgument:    CodeInvalidArgument,
+	codes.OutOfRange:         CodeInvalidArgument,
+	codes.NotFound:           CodeNotFound,
+	codes.AlreadyExists:      CodeConflict,
+	codes.Aborted:            CodeConflict,
+	codes.Unauthenticated:    CodeUnauthenticated,
+	codes.PermissionDenied:   CodePermissionDenied,
+	codes.ResourceExhausted:  CodeResourceExhausted,
+	codes.FailedPrecondition: CodeFailedPrecondition,
+	codes.Unimplemented:      CodeUnimplemented,
+	codes.Unavailable:        CodeUnavailable,
+	codes.DeadlineExceeded:   CodeDeadlineExceeded,
+	codes.Canceled:           CodeCanceled,
+}
+
+// httpCodes maps HTTP statuses, such as those of Echo errors, to general
+// error codes
+var httpCodes = map[int]Code{
+	http.StatusBadRequest:            CodeInvalidArgument,
+	http.StatusUnauthorized:          CodeUnauthenticated,
+	http.StatusForbidden:             CodePermissionDenied,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeUnimplemented,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedFormat,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusNotImplemented:        CodeUnimplemented,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusGatewayTimeout:        CodeDeadlineExceeded,
+}
+
+// HTTPStatus returns the HTTP status of responses failing with c. Unknown
+// codes are internal errors.
+func (c Code) HTTPStatus() int {
+	if m, ok := mappings[c]; ok {
+		return m.status
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the gRPC status code of calls failing with c
+func (c Code) GRPCCode() codes.Code {
+	if m, ok := mappings[c]; ok {
+		return m.code
+	}
+	return codes.Internal
+}
+
+// FieldViolation describes why a request field is invalid. Field is the path
+// of the field, e.g. card_numbers[2], and empty for the request as a whole.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error reported to clients. Message is safe to return to them;
+// the underlying cause in Err is only logged.
+type Error struct {
+	Code    Code
+	Message string
+	Fields  []FieldViolation
+	Err     error
+}
+
+// New returns an error with code and message
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf returns an error with code and a formatted message
+func Newf(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap returns an error with code and message caused by err
+func Wrap(code Code, err error, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same code, so errors with a
+// given code match the sentinel declaring it
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithField returns a copy of e reporting the request field as invalid
+func (e *Error) WithField(field string) *Error {
+	copied := *e
+	copied.Fields = append(append([]FieldViolation(nil), e.Fields...), FieldViolation{Field: field, Message: e.Message})
+	return &copied
+}
+
+// GRPCStatus returns the status of calls failing with e. It carries the code
+// in a google.rpc.ErrorInfo and the invalid fields in a google.rpc.BadRequest.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code.GRPCCode(), e.Message)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(e.Code), Domain: Domain}}
+	if len(e.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(e.Fields))
+		for i, field := range e.Fields {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message}
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// From returns err as an *Error. Errors wrapping an *Error keep its code and,
+// unless it is a server error, take the message of err; gRPC errors are read
+// from their status and details. Other errors are internal errors whose
+// message does not reveal the cause.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		if appErr == err {
+			return appErr
+		}
+		copied := *appErr
+		if appErr.Code.HTTPStatus() < http.StatusInternalServerError {
+			copied.Message = err.Error()
+		}
+		return &copied
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return FromStatus(st)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(CodeDeadlineExceeded, err, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return Wrap(CodeCanceled, err, "request canceled")
+	default:
+		return Wrap(CodeInternal, err, "internal error")
+	}
+}
+
+// FromStatus returns the error of a gRPC status, taking the code from its
+// ErrorInfo and the invalid fields from its BadRequest details
+func FromStatus(st *status.Status) *Error {
+	code, ok := grpcCodes[st.Code()]
+	if !ok {
+		code = CodeInternal
+	}
+	e := &Error{Code: code, Message: st.Message()}
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() == Domain && d.GetReason() != "" {
+				e.Code = Code(d.GetReason())
+			}
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				e.Fields = append(e.Fields, FieldViolation{Field: v.GetField(), Message: v.GetDescription()})
+			}
+		}
+	}
+	return e
+}
+
+// FromHTTPStatus returns an error with the code of an HTTP status
+func FromHTTPStatus(statusCode int, message string) *Error {
+	code, ok := httpCodes[statusCode]
+	if !ok {
+		code = CodeInternal
+		if statusCode < http.StatusInternalServerError {
+			code = CodeInvalidArgument
+		}
+	}
+	return New(code, message)
+}
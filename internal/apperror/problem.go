@@ -0,0 +1,94 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ContentType is the media type of problem details responses
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body. Error and Fields repeat
+// Detail and InvalidParams under the names of the former error body, so
+// existing clients keep working.
+type Problem struct {
+	Type      string           `json:"type"`
+	Title     string           `json:"title"`
+	Status    int              `json:"status"`
+	Detail    string           `json:"detail,omitempty"`
+	Instance  string           `json:"instance,omitempty"`
+	Code      Code             `json:"code"`
+	RequestID string           `json:"request_id,omitempty"`
+	Error     string           `json:"error"`
+	Fields    []FieldViolation `json:"fields,omitempty"`
+}
+
+// TypeURI returns the problem type URI of code, e.g.
+// urn:credit-card-validator:problem:invalid-card-number
+func (c Code) TypeURI() string {
+	return "urn:" + Domain + ":problem:" + strings.ToLower(strings.ReplaceAll(string(c), "_", "-"))
+}
+
+// Problem returns the problem details of e for the request path instance
+func (e *Error) Problem(instance string) Problem {
+	status := e.Code.HTTPStatus()
+	title := http.StatusText(status)
+	if title == "" {
+		title = strings.ReplaceAll(string(e.Code), "_", " ")
+	}
+
+	return Problem{
+		Type:     e.Code.TypeURI(),
+		Title:    title,
+		Status:   status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Error:    e.Message,
+		Fields:   e.Fields,
+	}
+}
+
+// Write writes err as a problem details response. The request ID is taken
+// from the X-Request-Id response header.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	problem := From(err).Problem(r.URL.Path)
+	problem.RequestID = w.Header().Get(echo.HeaderXRequestID)
+
+	w.Header().Set(echo.HeaderContentType, ContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// Respond writes err as the problem details response of an Echo request
+func Respond(c echo.Context, err error) error {
+	Write(c.Response(), c.Request(), err)
+	return nil
+}
+
+// HTTPErrorHandler is an Echo error handler writing errors, including those
+// of Echo itself such as unknown routes, as problem details
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message, ok := httpErr.Message.(string)
+		if !ok {
+			message = http.StatusText(httpErr.Code)
+		}
+		err = FromHTTPStatus(httpErr.Code, message)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		c.NoContent(From(err).Code.HTTPStatus())
+		return
+	}
+	Respond(c, err)
+}
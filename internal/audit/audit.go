@@ -0,0 +1,329 @@
+// Package audit writes a tamper-evident, append-only log of validation
+// activity. Every entry is chained to its predecessor with a keyed hash so
+// that edited, removed or reordered entries are detected by Verify, and a
+// signed checkpoint of the last entry detects entries removed from the end.
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"credit-card-validator/internal/config"
+)
+
+// Package-level errors for better error handling
+var (
+	ErrMissingFingerprintKey = errors.New("audit fingerprint key is required")
+	ErrLogClosed             = errors.New("audit log is closed")
+)
+
+const (
+	filePrefix = "audit-"
+	fileSuffix = ".log"
+)
+
+// Entry is a single audit record. Card numbers are only stored masked and as
+// a keyed fingerprint that allows correlating repeated validations.
+type Entry struct {
+	Sequence    uint64    `json:"seq"`
+	Timestamp   time.Time `json:"ts"`
+	RequestID   string    `json:"request_id,omitempty"`
+	Channel     string    `json:"channel"`
+	Client      string    `json:"client,omitempty"`
+	AuthMethod  string    `json:"auth_method,omitempty"`
+	Tenant      string    `json:"tenant,omitempty"`
+	MaskedPAN   string    `json:"masked_pan"`
+	Fingerprint string    `json:"fingerprint"`
+	Valid       bool      `json:"valid"`
+	CardType    string    `json:"card_type,omitempty"`
+	Issues      []string  `json:"issues,omitempty"`
+	PrevHash    string    `json:"prev_hash"`
+	Hash        string    `json:"hash"`
+}
+
+// computeHash returns the chain hash of the entry, an HMAC-SHA256 with key
+// covering every field but Hash. Without the key a modified entry cannot be
+// given a valid hash.
+func (e Entry) computeHash(key []byte) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// ChainKey returns the key of the entry hashes and checkpoints,
+// AUDIT_CHAIN_KEY or the fingerprint key when it is not set
+func ChainKey(config *config.AuditConfig) []byte {
+	if config.ChainKey != "" {
+		return []byte(config.ChainKey)
+	}
+	return []byte(config.FingerprintKey)
+}
+
+// Logger appends entries to rotating files in a directory
+type Logger struct {
+	config   *config.AuditConfig
+	key      []byte
+	chainKey []byte
+
+	mu       sync.Mutex
+	file     *os.File
+	index    int
+	size     int64
+	sequence uint64
+	lastHash string
+}
+
+// NewLogger opens the audit directory and resumes the chain from the last
+// written entry. A log that ends before its checkpoint was truncated and is
+// not resumed.
+func NewLogger(config *config.AuditConfig) (*Logger, error) {
+	if config.FingerprintKey == "" {
+		return nil, ErrMissingFingerprintKey
+	}
+
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	l := &Logger{
+		config:   config,
+		key:      []byte(config.FingerprintKey),
+		chainKey: ChainKey(config),
+		index:    1,
+	}
+
+	files, err := listFiles(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		last := files[len(files)-1]
+		l.index = last.index
+
+		entry, err := lastEntry(last.path)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			l.sequence = entry.Sequence
+			l.lastHash = entry.Hash
+		} else if len(files) > 1 {
+			// The newest file is empty, continue from the previous one
+			previous, err := lastEntry(files[len(files)-2].path)
+			if err != nil {
+				return nil, err
+			}
+			if previous != nil {
+				l.sequence = previous.Sequence
+				l.lastHash = previous.Hash
+			}
+		}
+	}
+
+	checkpoint, err := readCheckpoint(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil && checkpoint.Sequence > l.sequence {
+		return nil, fmt.Errorf("%w: log ends at sequence %d before checkpoint %d", ErrTampered, l.sequence, checkpoint.Sequence)
+	}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Fingerprint returns the keyed fingerprint of a card number
+func (l *Logger) Fingerprint(cardNumber string) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(cardNumber))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Record chains and appends the entry. Sequence, timestamp and hashes are
+// assigned by the logger.
+func (l *Logger) Record(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return ErrLogClosed
+	}
+
+	entry.Sequence = l.sequence + 1
+	entry.Timestamp = time.Now().UTC()
+	entry.PrevHash = l.lastHash
+
+	hash, err := entry.computeHash(l.chainKey)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	if l.config.MaxFileSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.config.MaxFileSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if l.config.Sync {
+		if err := l.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync audit file: %w", err)
+		}
+	}
+
+	l.sequence = entry.Sequence
+	l.lastHash = entry.Hash
+
+	return writeCheckpoint(l.config.Dir, NewCheckpoint(l.chainKey, l.sequence, l.lastHash), l.config.Sync)
+}
+
+// Close closes the current audit file
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// open opens the current file for appending
+func (l *Logger) open() error {
+	path := filepath.Join(l.config.Dir, fileName(l.index))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit file: %w", err)
+	}
+
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// rotate closes the current file and starts the next one
+func (l *Logger) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit file: %w", err)
+	}
+	l.index++
+	return l.open()
+}
+
+// auditFile is an audit file with its rotation index
+type auditFile struct {
+	path  string
+	index int
+}
+
+// fileName returns the name of the file with the given rotation index
+func fileName(index int) string {
+	return fmt.Sprintf("%s%06d%s", filePrefix, index, fileSuffix)
+}
+
+// listFiles returns the audit files in dir ordered by rotation index
+func listFiles(dir string) ([]auditFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit directory: %w", err)
+	}
+
+	var files []auditFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		files = append(files, auditFile{path: filepath.Join(dir, name), index: index})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].index < files[j].index
+	})
+
+	return files, nil
+}
+
+// lastEntry returns the last entry of a file, or nil for an empty file
+func lastEntry(path string) (*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+
+	var last *Entry
+	err = scanEntries(file, func(_ int, entry *Entry) error {
+		last = entry
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return last, nil
+}
+
+// scanEntries decodes one entry per line and calls fn with its line number
+func scanEntries(r io.Reader, fn func(line int, entry *Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("line %d: malformed entry: %w", line, err)
+		}
+		if err := fn(line, &entry); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// checkpointFile is the name of the checkpoint in the audit directory
+const checkpointFile = "checkpoint.json"
+
+// Checkpoint is a signed record of the last entry of the log. The logger
+// keeps one next to the audit files; exported copies kept elsewhere also
+// detect a log and checkpoint that were both rolled back.
+type Checkpoint struct {
+	Sequence  uint64 `json:"seq"`
+	Hash      string `json:"hash"`
+	Signature string `json:"signature"`
+}
+
+// NewCheckpoint returns the checkpoint of the entry with the given sequence
+// and hash, signed with the chain key
+func NewCheckpoint(key []byte, sequence uint64, hash string) *Checkpoint {
+	return &Checkpoint{
+		Sequence:  sequence,
+		Hash:      hash,
+		Signature: signCheckpoint(key, sequence, hash),
+	}
+}
+
+// Valid reports whether the checkpoint was signed with key
+func (c *Checkpoint) Valid(key []byte) bool {
+	return hmac.Equal([]byte(c.Signature), []byte(signCheckpoint(key, c.Sequence, c.Hash)))
+}
+
+// ReadCheckpoint reads an exported checkpoint
+func ReadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("malformed audit checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// signCheckpoint returns the signature of a sequence and hash
+func signCheckpoint(key []byte, sequence uint64, hash string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("checkpoint:" + strconv.FormatUint(sequence, 10) + ":" + hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readCheckpoint reads the checkpoint of dir, or nil when there is none
+func readCheckpoint(dir string) (*Checkpoint, error) {
+	checkpoint, err := ReadCheckpoint(filepath.Join(dir, checkpointFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return checkpoint, err
+}
+
+// writeCheckpoint replaces the checkpoint of dir
+func writeCheckpoint(dir string, checkpoint *Checkpoint, sync bool) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, checkpointFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write audit checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil && sync {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(dir, checkpointFile))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write audit checkpoint: %w", err)
+	}
+
+	return nil
+}
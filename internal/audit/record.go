@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"context"
+
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+)
+
+// Channels through which validations are requested
+const (
+	ChannelREST = "rest"
+	ChannelGRPC = "grpc"
+	ChannelJob  = "job"
+	// ChannelWeb is gRPC-Web and Connect calls made on the HTTP port
+	ChannelWeb = "web"
+)
+
+// RecordValidation records a validation result together with the client
+// identity and tenant carried by ctx
+func (l *Logger) RecordValidation(ctx context.Context, channel, requestID string, result *service.ValidationResult) error {
+	entry := Entry{
+		RequestID:   requestID,
+		Channel:     channel,
+		MaskedPAN:   service.MaskCardNumber(result.CardNumber),
+		Fingerprint: l.Fingerprint(result.CardNumber),
+		Valid:       result.Valid,
+		CardType:    result.CardType.String(),
+	}
+
+	for _, issue := range result.Issues {
+		entry.Issues = append(entry.Issues, issue.String())
+	}
+
+	if identity, ok := auth.FromContext(ctx); ok {
+		entry.Client = identity.Subject
+		entry.AuthMethod = identity.Method
+		if identity.CertSubject != "" {
+			entry.Client += " (" + identity.CertSubject + ")"
+		}
+	}
+
+	if t, ok := tenant.FromContext(ctx); ok {
+		entry.Tenant = t.ID
+	}
+
+	return l.Record(entry)
+}
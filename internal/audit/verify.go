@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrTampered is returned when the audit chain does not verify
+var ErrTampered = errors.New("audit log verification failed")
+
+// Report summarizes a verified audit directory
+type Report struct {
+	Files    int
+	Entries  uint64
+	LastHash string
+	// Checkpoint is the signed checkpoint of the last entry, to be kept
+	// outside the audit directory and passed to later verifications
+	Checkpoint *Checkpoint
+}
+
+// Verify checks every file in dir with the chain key: rotation indexes must
+// be contiguous, sequence numbers must increase by one and each entry must
+// hash to its recorded value and link to the hash of its predecessor. The
+// log must also contain the entry of the checkpoint in dir and of external,
+// an exported checkpoint that may be nil, so entries removed from the end
+// are detected.
+func Verify(dir string, key []byte, external *Checkpoint) (*Report, error) {
+	files, err := listFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint, err := readCheckpoint(dir)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil && len(files) > 0 {
+		return nil, fmt.Errorf("%w: missing %s", ErrTampered, checkpointFile)
+	}
+
+	var checkpoints []*Checkpoint
+	for _, c := range []*Checkpoint{checkpoint, external} {
+		if c == nil {
+			continue
+		}
+		if !c.Valid(key) {
+			return nil, fmt.Errorf("%w: checkpoint at sequence %d has an invalid signature", ErrTampered, c.Sequence)
+		}
+		checkpoints = append(checkpoints, c)
+	}
+
+	report := &Report{}
+	for i, f := range files {
+		if i > 0 && f.index != files[i-1].index+1 {
+			return report, fmt.Errorf("%w: missing file %s", ErrTampered, fileName(files[i-1].index+1))
+		}
+
+		if err := verifyFile(f.path, key, checkpoints, report); err != nil {
+			return report, err
+		}
+		report.Files++
+	}
+
+	for _, c := range checkpoints {
+		if report.Entries < c.Sequence {
+			return report, fmt.Errorf("%w: log ends at sequence %d before checkpoint %d", ErrTampered, report.Entries, c.Sequence)
+		}
+	}
+	if report.Entries > 0 {
+		report.Checkpoint = NewCheckpoint(key, report.Entries, report.LastHash)
+	}
+
+	return report, nil
+}
+
+// verifyFile checks the entries of a single file, continuing the chain in
+// report. Entries with the sequence of a checkpoint must have its hash.
+func verifyFile(path string, key []byte, checkpoints []*Checkpoint, report *Report) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+
+	name := filepath.Base(path)
+	err = scanEntries(file, func(line int, entry *Entry) error {
+		if entry.Sequence != report.Entries+1 {
+			return fmt.Errorf("%w: %s line %d: sequence %d follows %d", ErrTampered, name, line, entry.Sequence, report.Entries)
+		}
+		if entry.PrevHash != report.LastHash {
+			return fmt.Errorf("%w: %s line %d: chain broken before sequence %d", ErrTampered, name, line, entry.Sequence)
+		}
+
+		hash, err := entry.computeHash(key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(hash), []byte(entry.Hash)) {
+			return fmt.Errorf("%w: %s line %d: entry %d was modified", ErrTampered, name, line, entry.Sequence)
+		}
+		for _, c := range checkpoints {
+			if c.Sequence == entry.Sequence && c.Hash != entry.Hash {
+				return fmt.Errorf("%w: %s line %d: entry %d does not match its checkpoint", ErrTampered, name, line, entry.Sequence)
+			}
+		}
+
+		report.Entries = entry.Sequence
+		report.LastHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrTampered) {
+		return fmt.Errorf("%w: %s: %v", ErrTampered, name, err)
+	}
+
+	return err
+}
@@ -0,0 +1,128 @@
+// Package auth provides client authentication and scope based authorization
+// shared by the REST and gRPC APIs.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Package-level errors for better error handling
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInsufficientScope  = errors.New("insufficient scope")
+	ErrKeyNotFound        = errors.New("API key not found")
+)
+
+// Scope represents a permission granted to a client
+type Scope string
+
+// Supported scopes
+const (
+	ScopeValidate Scope = "validate"
+	ScopeBINRead  Scope = "bin:read"
+	ScopeTokenize Scope = "tokenize"
+	ScopeAdmin    Scope = "admin"
+)
+
+// String returns the string representation of Scope
+func (s Scope) String() string {
+	return string(s)
+}
+
+// IsValid checks if the scope is a known scope
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeValidate, ScopeBINRead, ScopeTokenize, ScopeAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseScopes parses a comma separated list of scopes
+func ParseScopes(value string) ([]Scope, error) {
+	var scopes []Scope
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		scope := Scope(part)
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("unknown scope %q", part)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// Identity describes an authenticated client
+type Identity struct {
+	// Subject identifies the client, e.g. the API key ID
+	Subject string `json:"subject"`
+	// Method is the authentication method that produced the identity
+	Method string `json:"method"`
+	// Scopes granted to the client
+	Scopes []Scope `json:"scopes"`
+	// Tenant the client is bound to, empty when not bound
+	Tenant string `json:"tenant,omitempty"`
+	// CertSubject is the subject of the verified client certificate, if any
+	CertSubject string `json:"cert_subject,omitempty"`
+}
+
+// HasScope reports whether the identity was granted the scope. The admin
+// scope implies every other scope.
+func (i *Identity) HasScope(scope Scope) bool {
+	if i == nil {
+		return false
+	}
+	for _, s := range i.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Credentials holds the raw credentials presented by a client
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+}
+
+// Empty reports whether no credentials were presented
+func (c Credentials) Empty() bool {
+	return c.APIKey == "" && c.BearerToken == ""
+}
+
+// Authenticator verifies client credentials
+type Authenticator interface {
+	Authenticate(ctx context.Context, creds Credentials) (*Identity, error)
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx carrying the identity
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity stored in ctx, if any
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// Allowed reports whether the caller in ctx may use scope. Requests without an
+// identity only reach the APIs when authentication is disabled and are allowed.
+func Allowed(ctx context.Context, scope Scope) bool {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return true
+	}
+	return identity.HasScope(scope)
+}
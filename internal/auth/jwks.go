@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrKeyNotInJWKS is returned when a token references an unknown signing key
+var ErrKeyNotInJWKS = errors.New("signing key not found in JWKS")
+
+// minRefreshInterval limits JWKS reloads triggered by unknown key IDs and
+// retries after a failed reload
+const minRefreshInterval = 10 * time.Second
+
+// jsonWebKey is a single key of a JSON Web Key Set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS resolves token signing keys from a JSON Web Key Set loaded from a
+// local file or fetched from a URL
+type JWKS struct {
+	file       string
+	url        string
+	refresh    time.Duration
+	httpClient *http.Client
+
+	// reloads makes concurrent callers share a single reload
+	reloads singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	loadedAt    time.Time
+	attemptedAt time.Time
+}
+
+// NewJWKS loads the key set from file or, when file is empty, from url.
+// Keys are refreshed once they are older than refresh.
+func NewJWKS(ctx context.Context, file, url string, refresh time.Duration, httpClient *http.Client) (*JWKS, error) {
+	if file == "" && url == "" {
+		return nil, errors.New("a JWKS file or URL is required")
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	j := &JWKS{
+		file:       file,
+		url:        url,
+		refresh:    refresh,
+		httpClient: httpClient,
+	}
+
+	if err := j.load(ctx); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Key returns the public key with the given key ID. An empty kid matches the
+// only key of a single key set.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.RLock()
+	canRetry := time.Since(j.attemptedAt) > minRefreshInterval
+	failed := j.attemptedAt.After(j.loadedAt)
+	stale := j.refresh > 0 && time.Since(j.loadedAt) > j.refresh && (!failed || canRetry)
+	key, found := j.lookup(kid)
+	j.mu.RUnlock()
+
+	// Reload when keys expired or when the issuer may have rotated keys. A
+	// failed reload is not retried before minRefreshInterval, so tokens with
+	// unknown key IDs cannot hammer an unavailable key endpoint.
+	if stale || (!found && canRetry) {
+		// The reload is shared, so it must not fail when the caller that
+		// started it goes away
+		_, err, _ := j.reloads.Do("load", func() (interface{}, error) {
+			return nil, j.load(context.WithoutCancel(ctx))
+		})
+		if err != nil {
+			if found {
+				return key, nil
+			}
+			return nil, err
+		}
+
+		j.mu.RLock()
+		key, found = j.lookup(kid)
+		j.mu.RUnlock()
+	}
+
+	if !found {
+		return nil, ErrKeyNotInJWKS
+	}
+
+	return key, nil
+}
+
+// lookup finds a key by ID. Callers must hold the read lock.
+func (j *JWKS) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// load reads and parses the key set
+func (j *JWKS) load(ctx context.Context) error {
+	j.mu.Lock()
+	j.attemptedAt = time.Now()
+	j.mu.Unlock()
+
+	data, err := j.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return fmt.Errorf("invalid JWKS key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.loadedAt = time.Now()
+	j.mu.Unlock()
+
+	return nil
+}
+
+// read returns the raw key set from the file or URL
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if j.file != "" {
+		data, err := os.ReadFile(j.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// publicKey converts the JWK to a crypto public key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
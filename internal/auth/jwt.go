@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"credit-card-validator/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingMethods lists the accepted asymmetric JWT algorithms
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// JWTVerifier authenticates bearer tokens signed by keys of a JWKS
+type JWTVerifier struct {
+	keys         *JWKS
+	parser       *jwt.Parser
+	scopeClaim   string
+	scopeMapping map[string]Scope
+	tenantClaim  string
+}
+
+// NewJWTVerifier creates a verifier checking issuer, audience and expiry with
+// the configured clock skew tolerance. Issuer and audience are required, so
+// tokens minted for other services by the same identity provider are
+// rejected. The scope mapping translates claim values to scopes; values that
+// are scopes themselves are accepted unmapped.
+func NewJWTVerifier(keys *JWKS, config *config.AuthConfig) (*JWTVerifier, error) {
+	if config.JWTIssuer == "" {
+		return nil, errors.New("JWT_ISSUER is required when JWT_ENABLED is set")
+	}
+	if config.JWTAudience == "" {
+		return nil, errors.New("JWT_AUDIENCE is required when JWT_ENABLED is set")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithLeeway(config.JWTClockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(config.JWTIssuer),
+		jwt.WithAudience(config.JWTAudience),
+	}
+
+	scopeMapping, err := ParseScopeMapping(config.JWTScopeMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeClaim := config.JWTScopeClaim
+	if scopeClaim == "" {
+		scopeClaim = "scope"
+	}
+
+	return &JWTVerifier{
+		keys:         keys,
+		parser:       jwt.NewParser(options...),
+		scopeClaim:   scopeClaim,
+		scopeMapping: scopeMapping,
+		tenantClaim:  config.JWTTenantClaim,
+	}, nil
+}
+
+// Authenticate verifies the bearer token and maps its claims to an identity
+func (v *JWTVerifier) Authenticate(ctx context.Context, creds Credentials) (*Identity, error) {
+	if creds.BearerToken == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(creds.BearerToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidCredentials)
+	}
+
+	identity := &Identity{
+		Subject: subject,
+		Method:  "jwt",
+		Scopes:  v.scopes(claims),
+	}
+	if v.tenantClaim != "" {
+		identity.Tenant, _ = claims[v.tenantClaim].(string)
+	}
+
+	return identity, nil
+}
+
+// scopes maps the scope claim, a space separated string or a list, to scopes
+func (v *JWTVerifier) scopes(claims jwt.MapClaims) []Scope {
+	var values []string
+	switch raw := claims[v.scopeClaim].(type) {
+	case string:
+		values = strings.Fields(raw)
+	case []interface{}:
+		for _, item := range raw {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var scopes []Scope
+	for _, value := range values {
+		if scope, ok := v.scopeMapping[value]; ok {
+			scopes = append(scopes, scope)
+		} else if Scope(value).IsValid() {
+			scopes = append(scopes, Scope(value))
+		}
+	}
+
+	return scopes
+}
+
+// ParseScopeMapping parses a mapping of the form "claim=scope,claim=scope"
+func ParseScopeMapping(value string) (map[string]Scope, error) {
+	mapping := make(map[string]Scope)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		claim, scope, ok := strings.Cut(pair, "=")
+		claim, scope = strings.TrimSpace(claim), strings.TrimSpace(scope)
+		if !ok || claim == "" || !Scope(scope).IsValid() {
+			return nil, fmt.Errorf("invalid scope mapping %q", pair)
+		}
+		mapping[claim] = Scope(scope)
+	}
+	return mapping, nil
+}
+
+// Chain tries each authenticator in turn and returns the first identity
+func Chain(authenticators ...Authenticator) Authenticator {
+	return chain(authenticators)
+}
+
+type chain []Authenticator
+
+func (c chain) Authenticate(ctx context.Context, creds Credentials) (*Identity, error) {
+	err := ErrMissingCredentials
+	for _, authenticator := range c {
+		identity, authErr := authenticator.Authenticate(ctx, creds)
+		if authErr == nil {
+			return identity, nil
+		}
+		// Report the most specific failure, not that other credentials were absent
+		if !errors.Is(authErr, ErrMissingCredentials) {
+			err = authErr
+		}
+	}
+	return nil, err
+}
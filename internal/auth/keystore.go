@@ -0,0 +1,285 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keyPrefix marks tokens issued by the key store
+const keyPrefix = "ccv"
+
+// APIKey is a stored API key. Only the SHA-256 hash of the secret is kept.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Tenant    string     `json:"tenant,omitempty"`
+	Hash      string     `json:"hash"`
+	Scopes    []Scope    `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Revoked reports whether the key has been revoked
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// KeyStore is a file backed store of hashed API keys
+type KeyStore struct {
+	path string
+
+	mu      sync.RWMutex
+	keys    map[string]*APIKey
+	modTime time.Time
+	size    int64
+}
+
+// NewKeyStore opens the key store at path. A missing file is treated as an
+// empty store and is created on the first write.
+func NewKeyStore(path string) (*KeyStore, error) {
+	s := &KeyStore{
+		path: path,
+		keys: make(map[string]*APIKey),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Create generates a new API key bound to tenant, which may be empty, and
+// returns its plaintext token, which is not recoverable afterwards.
+func (s *KeyStore) Create(name, tenant string, scopes []Scope) (string, *APIKey, error) {
+	if len(scopes) == 0 {
+		return "", nil, errors.New("at least one scope is required")
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := &APIKey{
+		ID:        id,
+		Name:      name,
+		Tenant:    tenant,
+		Hash:      hashSecret(secret),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[id] = key
+	if err := s.save(); err != nil {
+		delete(s.keys, id)
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("%s_%s_%s", keyPrefix, id, secret), key, nil
+}
+
+// List returns all keys ordered by creation time
+func (s *KeyStore) List() []*APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys
+}
+
+// Revoke marks the key with the given ID as revoked
+func (s *KeyStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if key.Revoked() {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	key.RevokedAt = &now
+
+	return s.save()
+}
+
+// Authenticate verifies an API key token against the store
+func (s *KeyStore) Authenticate(_ context.Context, creds Credentials) (*Identity, error) {
+	if creds.APIKey == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	id, secret, ok := parseToken(creds.APIKey)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	// Pick up keys created or revoked by the keys command while running
+	if err := s.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	key, found := s.keys[id]
+	s.mu.RUnlock()
+
+	if !found || key.Revoked() {
+		return nil, ErrInvalidCredentials
+	}
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{
+		Subject: key.ID,
+		Method:  "api_key",
+		Scopes:  key.Scopes,
+		Tenant:  key.Tenant,
+	}, nil
+}
+
+// load reads the key file into memory
+func (s *KeyStore) load() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat key file: %w", err)
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var keys []*APIKey
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return fmt.Errorf("failed to decode key file: %w", err)
+		}
+	}
+
+	s.keys = make(map[string]*APIKey, len(keys))
+	for _, key := range keys {
+		s.keys[key.ID] = key
+	}
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+
+	return nil
+}
+
+// reloadIfChanged reloads the key file when it was modified on disk
+func (s *KeyStore) reloadIfChanged() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat key file: %w", err)
+	}
+
+	s.mu.RLock()
+	changed := !info.ModTime().Equal(s.modTime) || info.Size() != s.size
+	s.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+// save atomically writes the key file. Callers must hold the write lock.
+func (s *KeyStore) save() error {
+	keys := make([]*APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode key file: %w", err)
+	}
+
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create key directory: %w", err)
+		}
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace key file: %w", err)
+	}
+
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+		s.size = info.Size()
+	}
+
+	return nil
+}
+
+// parseToken splits a token of the form ccv_<id>_<secret>
+func parseToken(token string) (id, secret string, ok bool) {
+	parts := strings.Split(token, "_")
+	if len(parts) != 3 || parts[0] != keyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// hashSecret returns the hex encoded SHA-256 hash of the secret
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes hex encoded
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,253 @@
+// Package certs loads server certificates and client CA bundles from disk and
+// keeps them up to date without restarting the servers.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Package-level errors for better error handling
+var (
+	ErrNoCertificate   = errors.New("TLS certificate and key files are required")
+	ErrInvalidCABundle = errors.New("client CA bundle contains no certificates")
+	ErrUnknownVersion  = errors.New("unsupported TLS version")
+	ErrUnknownAuthMode = errors.New("unsupported client auth mode")
+)
+
+// ClientAuth controls whether clients must present a certificate
+type ClientAuth string
+
+// Supported client auth modes
+const (
+	ClientAuthNone     ClientAuth = "none"
+	ClientAuthOptional ClientAuth = "optional"
+	ClientAuthRequire  ClientAuth = "require"
+)
+
+// tlsClientAuth converts the mode to the crypto/tls equivalent
+func (c ClientAuth) tlsClientAuth() (tls.ClientAuthType, error) {
+	switch c {
+	case ClientAuthNone, "":
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("%w: %q", ErrUnknownAuthMode, string(c))
+	}
+}
+
+// Shared returns the mode of a listener serving clients with modes c and
+// other. When they differ, certificates are verified if given and each server
+// enforces its own mode, since the protocol is only known after the handshake.
+func (c ClientAuth) Shared(other ClientAuth) ClientAuth {
+	if c == "" {
+		c = ClientAuthNone
+	}
+	if other == "" {
+		other = ClientAuthNone
+	}
+	if _, err := other.tlsClientAuth(); err != nil || c == other {
+		return other
+	}
+	if _, err := c.tlsClientAuth(); err != nil {
+		return c
+	}
+	return ClientAuthOptional
+}
+
+// Reloader serves the current certificate and client CA pool, reloading them
+// when the files change on disk
+type Reloader struct {
+	config *config.TLSConfig
+	logger logging.Logger
+
+	minVersion uint16
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// NewReloader loads the configured certificate files. nil logger logs with a
+// default logrus logger.
+func NewReloader(config *config.TLSConfig, logger logging.Logger) (*Reloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, ErrNoCertificate
+	}
+
+	if logger == nil {
+		logger = logging.FromLogrus(logrus.New())
+	}
+
+	minVersion, err := parseVersion(config.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{
+		config:     config,
+		logger:     logger,
+		minVersion: minVersion,
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ServerConfig returns a TLS configuration for a server using the given
+// client auth mode. Certificates and CAs are resolved per handshake so that
+// reloads apply to new connections immediately.
+func (r *Reloader) ServerConfig(clientAuth ClientAuth) (*tls.Config, error) {
+	authType, err := clientAuth.tlsClientAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	if authType != tls.NoClientCert && r.config.ClientCAFile == "" {
+		return nil, errors.New("client CA file is required for client certificate authentication")
+	}
+
+	base := &tls.Config{
+		MinVersion: r.minVersion,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.Certificates = []tls.Certificate{*r.cert}
+		cfg.ClientAuth = authType
+		cfg.ClientCAs = r.clientCA
+		return cfg, nil
+	}
+
+	return base, nil
+}
+
+// Watch polls the certificate files until ctx is done
+func (r *Reloader) Watch(ctx context.Context) {
+	interval := r.config.ReloadInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				r.logger.Error("Failed to reload TLS certificates, keeping previous ones", logging.FieldError, err)
+				continue
+			}
+			r.logger.Info("TLS certificates reloaded")
+		}
+	}
+}
+
+// reload reads the certificate, key and CA bundle from disk
+func (r *Reloader) reload() error {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		data, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return ErrInvalidCABundle
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCA = pool
+	r.modTimes = modTimes
+	r.mu.Unlock()
+
+	return nil
+}
+
+// changed reports whether any watched file was modified since the last load
+func (r *Reloader) changed() bool {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		// Files may be missing briefly while being replaced
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for path, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// statFiles returns the modification times of the watched files
+func (r *Reloader) statFiles() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time, 3)
+	for _, path := range []string{r.config.CertFile, r.config.KeyFile, r.config.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// parseVersion converts a version such as "1.2" to its crypto/tls constant
+func parseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownVersion, version)
+	}
+}
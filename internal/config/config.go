@@ -7,11 +7,26 @@ import (
 )
 
 type Config struct {
-	Port           int             `mapstructure:"PORT"`
-	GRPCPort       int             `mapstructure:"GRPC_PORT"`
-	LogLevel       string          `mapstructure:"LOG_LEVEL"`
-	MetricsEnabled bool            `mapstructure:"METRICS_ENABLED"`
+	Port     int `mapstructure:"PORT"`
+	GRPCPort int `mapstructure:"GRPC_PORT"`
+	// SinglePort serves gRPC on Port alongside HTTP instead of on GRPCPort
+	SinglePort     bool   `mapstructure:"SINGLE_PORT"`
+	LogLevel       string `mapstructure:"LOG_LEVEL"`
+	MetricsEnabled bool   `mapstructure:"METRICS_ENABLED"`
+	// MaxRequestSize limits the JSON bodies of REST validation and scan requests
+	MaxRequestSize int64           `mapstructure:"MAX_REQUEST_SIZE"`
 	Validator      ValidatorConfig `mapstructure:",squash"`
+	Auth           AuthConfig      `mapstructure:",squash"`
+	TLS            TLSConfig       `mapstructure:",squash"`
+	Tenants        TenantsConfig   `mapstructure:",squash"`
+	Audit          AuditConfig     `mapstructure:",squash"`
+	Batch          BatchConfig     `mapstructure:",squash"`
+	Jobs           JobsConfig      `mapstructure:",squash"`
+	DLP            DLPConfig       `mapstructure:",squash"`
+	Proxy          ProxyConfig     `mapstructure:",squash"`
+	Syslog         SyslogConfig    `mapstructure:",squash"`
+	Health         HealthConfig    `mapstructure:",squash"`
+	Tracing        TracingConfig   `mapstructure:",squash"`
 }
 
 type ValidatorConfig struct {
@@ -19,19 +34,187 @@ type ValidatorConfig struct {
 	HTTPTimeout     time.Duration `mapstructure:"HTTP_TIMEOUT"`
 	BINServiceURL   string        `mapstructure:"BIN_SERVICE_URL"`
 	MaskSensitive   bool          `mapstructure:"MASK_SENSITIVE"`
+	// AcceptedCardTypes restricts valid cards to these types; empty accepts all
+	AcceptedCardTypes []string `mapstructure:"ACCEPTED_CARD_TYPES"`
+}
+
+type AuthConfig struct {
+	Enabled     bool   `mapstructure:"AUTH_ENABLED"`
+	APIKeysFile string `mapstructure:"API_KEYS_FILE"`
+
+	JWTEnabled      bool          `mapstructure:"JWT_ENABLED"`
+	JWKSFile        string        `mapstructure:"JWT_JWKS_FILE"`
+	JWKSURL         string        `mapstructure:"JWT_JWKS_URL"`
+	JWKSRefresh     time.Duration `mapstructure:"JWT_JWKS_REFRESH"`
+	JWTIssuer       string        `mapstructure:"JWT_ISSUER"`
+	JWTAudience     string        `mapstructure:"JWT_AUDIENCE"`
+	JWTClockSkew    time.Duration `mapstructure:"JWT_CLOCK_SKEW"`
+	JWTScopeClaim   string        `mapstructure:"JWT_SCOPE_CLAIM"`
+	JWTScopeMapping string        `mapstructure:"JWT_SCOPE_MAPPING"`
+	JWTTenantClaim  string        `mapstructure:"JWT_TENANT_CLAIM"`
+}
+
+type TLSConfig struct {
+	CertFile       string        `mapstructure:"TLS_CERT_FILE"`
+	KeyFile        string        `mapstructure:"TLS_KEY_FILE"`
+	ClientCAFile   string        `mapstructure:"TLS_CLIENT_CA_FILE"`
+	MinVersion     string        `mapstructure:"TLS_MIN_VERSION"`
+	GRPCClientAuth string        `mapstructure:"TLS_GRPC_CLIENT_AUTH"`
+	HTTPClientAuth string        `mapstructure:"TLS_HTTP_CLIENT_AUTH"`
+	ReloadInterval time.Duration `mapstructure:"TLS_RELOAD_INTERVAL"`
+}
+
+// Enabled reports whether a server certificate is configured
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+type AuditConfig struct {
+	Enabled        bool   `mapstructure:"AUDIT_ENABLED"`
+	Dir            string `mapstructure:"AUDIT_DIR"`
+	FingerprintKey string `mapstructure:"AUDIT_FINGERPRINT_KEY"`
+	ChainKey       string `mapstructure:"AUDIT_CHAIN_KEY"`
+	MaxFileSize    int64  `mapstructure:"AUDIT_MAX_FILE_SIZE"`
+	Sync           bool   `mapstructure:"AUDIT_SYNC"`
+}
+
+type BatchConfig struct {
+	MaxItems          int `mapstructure:"BATCH_MAX_ITEMS"`
+	Concurrency       int `mapstructure:"BATCH_CONCURRENCY"`
+	StreamConcurrency int `mapstructure:"STREAM_CONCURRENCY"`
+}
+
+type JobsConfig struct {
+	Enabled       bool   `mapstructure:"JOBS_ENABLED"`
+	Dir           string `mapstructure:"JOBS_DIR"`
+	Workers       int    `mapstructure:"JOBS_WORKERS"`
+	QueueSize     int    `mapstructure:"JOBS_QUEUE_SIZE"`
+	MaxUploadSize int64  `mapstructure:"JOBS_MAX_UPLOAD_SIZE"`
+}
+
+type DLPConfig struct {
+	MaxTextSize int   `mapstructure:"DLP_MAX_TEXT_SIZE"`
+	MaxFileSize int64 `mapstructure:"DLP_MAX_FILE_SIZE"`
+}
+
+type SyslogConfig struct {
+	Enabled        bool          `mapstructure:"SYSLOG_ENABLED"`
+	UDPAddr        string        `mapstructure:"SYSLOG_UDP_ADDR"`
+	TCPAddr        string        `mapstructure:"SYSLOG_TCP_ADDR"`
+	Forward        string        `mapstructure:"SYSLOG_FORWARD"`
+	ForwardTimeout time.Duration `mapstructure:"SYSLOG_FORWARD_TIMEOUT"`
+	MaxMessageSize int           `mapstructure:"SYSLOG_MAX_MESSAGE_SIZE"`
+	// Sources lists the hostnames counted separately in the syslog metrics;
+	// messages from other hosts are counted as "other"
+	Sources []string `mapstructure:"SYSLOG_SOURCES"`
+}
+
+type HealthConfig struct {
+	CheckInterval time.Duration `mapstructure:"HEALTH_CHECK_INTERVAL"`
+	CheckTimeout  time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+	// DrainDelay is how long readiness fails before the listeners close
+	DrainDelay time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+}
+
+type TracingConfig struct {
+	Enabled bool `mapstructure:"TRACING_ENABLED"`
+	// Endpoint is the host:port of the OTLP gRPC collector
+	Endpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure bool   `mapstructure:"OTEL_EXPORTER_OTLP_INSECURE"`
+	// SampleRatio is the fraction of new traces recorded; calls whose
+	// caller sampled the trace are always recorded
+	SampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	ServiceName string  `mapstructure:"OTEL_SERVICE_NAME"`
 }
 
 // Load returns merged service and validator configuration
 func Load() *Config {
 	viper.SetDefault("PORT", 8080)
 	viper.SetDefault("GRPC_PORT", 9090)
+	viper.SetDefault("SINGLE_PORT", false)
 	viper.SetDefault("LOG_LEVEL", "info")
 	viper.SetDefault("METRICS_ENABLED", true)
+	viper.SetDefault("MAX_REQUEST_SIZE", 2097152)
 
 	viper.SetDefault("ENABLE_BIN_LOOKUP", true)
 	viper.SetDefault("HTTP_TIMEOUT", "10s")
 	viper.SetDefault("BIN_SERVICE_URL", "https://lookup.binlist.net")
 	viper.SetDefault("MASK_SENSITIVE", true)
+	viper.SetDefault("ACCEPTED_CARD_TYPES", "")
+
+	viper.SetDefault("AUTH_ENABLED", false)
+	viper.SetDefault("API_KEYS_FILE", "data/api_keys.json")
+	viper.SetDefault("JWT_ENABLED", false)
+	viper.SetDefault("JWT_JWKS_FILE", "")
+	viper.SetDefault("JWT_JWKS_URL", "")
+	viper.SetDefault("JWT_JWKS_REFRESH", "5m")
+	viper.SetDefault("JWT_ISSUER", "")
+	viper.SetDefault("JWT_AUDIENCE", "")
+	viper.SetDefault("JWT_CLOCK_SKEW", "30s")
+	viper.SetDefault("JWT_SCOPE_CLAIM", "scope")
+	viper.SetDefault("JWT_SCOPE_MAPPING", "")
+	viper.SetDefault("JWT_TENANT_CLAIM", "tenant")
+
+	viper.SetDefault("TLS_CERT_FILE", "")
+	viper.SetDefault("TLS_KEY_FILE", "")
+	viper.SetDefault("TLS_CLIENT_CA_FILE", "")
+	viper.SetDefault("TLS_MIN_VERSION", "1.2")
+	viper.SetDefault("TLS_GRPC_CLIENT_AUTH", "require")
+	viper.SetDefault("TLS_HTTP_CLIENT_AUTH", "none")
+	viper.SetDefault("TLS_RELOAD_INTERVAL", "30s")
+
+	viper.SetDefault("TENANTS_FILE", "")
+	viper.SetDefault("TENANT_HEADER", "X-Tenant-ID")
+	viper.SetDefault("RATE_LIMIT", 0)
+	viper.SetDefault("RATE_BURST", 20)
+
+	viper.SetDefault("AUDIT_ENABLED", false)
+	viper.SetDefault("AUDIT_DIR", "data/audit")
+	viper.SetDefault("AUDIT_FINGERPRINT_KEY", "")
+	viper.SetDefault("AUDIT_CHAIN_KEY", "")
+	viper.SetDefault("AUDIT_MAX_FILE_SIZE", 100*1024*1024)
+	viper.SetDefault("AUDIT_SYNC", true)
+
+	viper.SetDefault("BATCH_MAX_ITEMS", 1000)
+	viper.SetDefault("BATCH_CONCURRENCY", 8)
+	viper.SetDefault("STREAM_CONCURRENCY", 16)
+
+	viper.SetDefault("JOBS_ENABLED", false)
+	viper.SetDefault("JOBS_DIR", "data/jobs")
+	viper.SetDefault("JOBS_WORKERS", 2)
+	viper.SetDefault("JOBS_QUEUE_SIZE", 1000)
+	viper.SetDefault("JOBS_MAX_UPLOAD_SIZE", 52428800)
+
+	viper.SetDefault("DLP_MAX_TEXT_SIZE", 1048576)
+	viper.SetDefault("DLP_MAX_FILE_SIZE", 20971520)
+
+	viper.SetDefault("PROXY_ENABLED", false)
+	viper.SetDefault("PROXY_PORT", 8081)
+	viper.SetDefault("PROXY_UPSTREAM", "")
+	viper.SetDefault("PROXY_ROUTES_FILE", "")
+	viper.SetDefault("PROXY_DEFAULT_REQUEST", "mask")
+	viper.SetDefault("PROXY_DEFAULT_RESPONSE", "mask")
+	viper.SetDefault("PROXY_MAX_BODY_SIZE", 10485760)
+	viper.SetDefault("PROXY_TOKEN_KEY", "")
+	viper.SetDefault("PROXY_FAIL_CLOSED", true)
+
+	viper.SetDefault("SYSLOG_ENABLED", false)
+	viper.SetDefault("SYSLOG_UDP_ADDR", ":5514")
+	viper.SetDefault("SYSLOG_TCP_ADDR", ":5514")
+	viper.SetDefault("SYSLOG_FORWARD", "")
+	viper.SetDefault("SYSLOG_FORWARD_TIMEOUT", "5s")
+	viper.SetDefault("SYSLOG_MAX_MESSAGE_SIZE", 65536)
+	viper.SetDefault("SYSLOG_SOURCES", "")
+
+	viper.SetDefault("HEALTH_CHECK_INTERVAL", "30s")
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", "5s")
+	viper.SetDefault("SHUTDOWN_DRAIN_DELAY", "5s")
+
+	viper.SetDefault("TRACING_ENABLED", false)
+	viper.SetDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_INSECURE", true)
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("OTEL_SERVICE_NAME", "credit-card-validator")
 
 	viper.AutomaticEnv()
 
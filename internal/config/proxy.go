@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+type ProxyConfig struct {
+	Enabled         bool   `mapstructure:"PROXY_ENABLED"`
+	Port            int    `mapstructure:"PROXY_PORT"`
+	Upstream        string `mapstructure:"PROXY_UPSTREAM"`
+	RoutesFile      string `mapstructure:"PROXY_ROUTES_FILE"`
+	DefaultRequest  string `mapstructure:"PROXY_DEFAULT_REQUEST"`
+	DefaultResponse string `mapstructure:"PROXY_DEFAULT_RESPONSE"`
+	MaxBodySize     int64  `mapstructure:"PROXY_MAX_BODY_SIZE"`
+	TokenKey        string `mapstructure:"PROXY_TOKEN_KEY"`
+	FailClosed      bool   `mapstructure:"PROXY_FAIL_CLOSED"`
+}
+
+// ProxyRoute configures redaction for requests whose path starts with Path.
+// Empty actions fall back to the proxy defaults.
+type ProxyRoute struct {
+	Path     string   `mapstructure:"path"`
+	Methods  []string `mapstructure:"methods"`
+	Request  string   `mapstructure:"request"`
+	Response string   `mapstructure:"response"`
+}
+
+// LoadProxyRoutes reads the proxy routes from a YAML or JSON file with a
+// top-level routes list
+func LoadProxyRoutes(path string) ([]ProxyRoute, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read proxy routes file: %w", err)
+	}
+
+	var file struct {
+		Routes []ProxyRoute `mapstructure:"routes"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode proxy routes file: %w", err)
+	}
+
+	return file.Routes, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type TenantsConfig struct {
+	TenantsFile  string  `mapstructure:"TENANTS_FILE"`
+	TenantHeader string  `mapstructure:"TENANT_HEADER"`
+	RateLimit    float64 `mapstructure:"RATE_LIMIT"`
+	RateBurst    int     `mapstructure:"RATE_BURST"`
+}
+
+// TenantOverride holds the settings of a single tenant. Unset fields inherit
+// the service wide configuration.
+type TenantOverride struct {
+	EnableBINLookup   *bool          `mapstructure:"enable_bin_lookup"`
+	HTTPTimeout       *time.Duration `mapstructure:"http_timeout"`
+	BINServiceURL     *string        `mapstructure:"bin_service_url"`
+	MaskSensitive     *bool          `mapstructure:"mask_sensitive"`
+	AcceptedCardTypes []string       `mapstructure:"accepted_card_types"`
+	RateLimit         *float64       `mapstructure:"rate_limit"`
+	RateBurst         *int           `mapstructure:"rate_burst"`
+}
+
+// ApplyValidator returns base with the tenant overrides applied
+func (o TenantOverride) ApplyValidator(base ValidatorConfig) ValidatorConfig {
+	cfg := base
+	if o.EnableBINLookup != nil {
+		cfg.EnableBINLookup = *o.EnableBINLookup
+	}
+	if o.HTTPTimeout != nil {
+		cfg.HTTPTimeout = *o.HTTPTimeout
+	}
+	if o.BINServiceURL != nil {
+		cfg.BINServiceURL = *o.BINServiceURL
+	}
+	if o.MaskSensitive != nil {
+		cfg.MaskSensitive = *o.MaskSensitive
+	}
+	if o.AcceptedCardTypes != nil {
+		cfg.AcceptedCardTypes = o.AcceptedCardTypes
+	}
+	return cfg
+}
+
+// ApplyRateLimit returns the rate limit and burst with the tenant overrides applied
+func (o TenantOverride) ApplyRateLimit(limit float64, burst int) (float64, int) {
+	if o.RateLimit != nil {
+		limit = *o.RateLimit
+	}
+	if o.RateBurst != nil {
+		burst = *o.RateBurst
+	}
+	return limit, burst
+}
+
+// LoadTenants reads the tenant overrides from a YAML or JSON file of the form
+//
+//	tenants:
+//	  retail:
+//	    accepted_card_types: [visa, mastercard]
+//	    rate_limit: 50
+func LoadTenants(path string) (map[string]TenantOverride, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var file struct {
+		Tenants map[string]TenantOverride `mapstructure:"tenants"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode tenants file: %w", err)
+	}
+
+	return file.Tenants, nil
+}
@@ -0,0 +1,343 @@
+// Package dlp finds card numbers (PANs) in free text and redacts them.
+//
+// Candidates are runs of 13 to 19 digits, optionally split into groups by
+// single spaces, dashes or dots. A candidate is only reported when it passes
+// the Luhn check and matches a known card scheme, using the same rules as
+// the validator service.
+package dlp
+
+import (
+	"fmt"
+	"strings"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/service"
+)
+
+// ErrTextTooLarge is returned when text exceeds the configured size
+var ErrTextTooLarge = apperror.New(apperror.CodeTextTooLarge, "text exceeds the maximum size")
+
+// Candidate length limits in digits
+const (
+	minDigits = 13
+	maxDigits = 19
+)
+
+// keywordWindow is how many bytes before a candidate are searched for keywords
+const keywordWindow = 32
+
+// keywords raise the confidence of nearby findings
+var keywords = []string{"card", "credit", "debit", "pan", "cc", "visa", "mastercard", "amex", "discover", "acct", "account"}
+
+// Finding is a PAN found in text. Start and End are byte offsets into the
+// scanned text, so text[Start:End] is the PAN as written.
+type Finding struct {
+	Start      int              `json:"start"`
+	End        int              `json:"end"`
+	MaskedPAN  string           `json:"masked_pan"`
+	CardType   service.CardType `json:"card_type"`
+	Confidence float64          `json:"confidence"`
+
+	digits string
+}
+
+// PAN returns the digits of the card number
+func (f Finding) PAN() string {
+	return f.digits
+}
+
+// Result is the outcome of scanning a text
+type Result struct {
+	Findings     []Finding `json:"findings"`
+	RedactedText string    `json:"redacted_text"`
+}
+
+// Scanner finds PANs in text. It is safe for concurrent use.
+type Scanner struct {
+	config    *config.DLPConfig
+	validator *service.Validator
+}
+
+// NewScanner creates a scanner confirming candidates with an offline validator
+func NewScanner(config *config.DLPConfig) (*Scanner, error) {
+	cfg := service.DefaultConfig()
+	cfg.EnableBINLookup = false
+
+	validator, err := service.NewValidator(cfg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create validator: %w", err)
+	}
+
+	return &Scanner{config: config, validator: validator}, nil
+}
+
+// MaxFileSize returns the largest file accepted for upload scanning, 0 for
+// no limit
+func (s *Scanner) MaxFileSize() int64 {
+	return s.config.MaxFileSize
+}
+
+// ScanText scans text submitted to the API and returns the findings with a
+// masked copy of the text
+func (s *Scanner) ScanText(text string) (*Result, error) {
+	if s.config.MaxTextSize > 0 && len(text) > s.config.MaxTextSize {
+		return nil, ErrTextTooLarge
+	}
+
+	findings := s.Scan(text)
+	if findings == nil {
+		findings = []Finding{}
+	}
+
+	return &Result{
+		Findings:     findings,
+		RedactedText: Redact(text, findings, Mask),
+	}, nil
+}
+
+// Scan returns the PANs found in text in order of appearance. Candidates are
+// whole runs of digits, so a PAN is never cut out of a longer number, but
+// letters around it do not hide it: "PAN4111111111111111" is found.
+func (s *Scanner) Scan(text string) []Finding {
+	var findings []Finding
+
+	for i := 0; i < len(text); {
+		if !isDigit(text[i]) {
+			i++
+			continue
+		}
+
+		groups, end := readGroups(text, i)
+		findings = append(findings, s.confirm(text, groups)...)
+		i = end
+	}
+
+	return findings
+}
+
+// group is a run of digits at text[start:end]
+type group struct {
+	start, end int
+}
+
+// readGroups reads digit groups separated by single separators starting at
+// start and returns them with the offset after the last group
+func readGroups(text string, start int) ([]group, int) {
+	var groups []group
+
+	i := start
+	for {
+		g := group{start: i}
+		for i < len(text) && isDigit(text[i]) {
+			i++
+		}
+		g.end = i
+		groups = append(groups, g)
+
+		if i+1 < len(text) && isSeparator(text[i]) && isDigit(text[i+1]) {
+			i++
+			continue
+		}
+		return groups, i
+	}
+}
+
+// confirm returns the PANs formed by consecutive groups. Runs that are too
+// long are split at group boundaries, preferring the longest PAN from the
+// left. An unseparated run is only checked as a whole.
+func (s *Scanner) confirm(text string, groups []group) []Finding {
+	var findings []Finding
+	for first := 0; first < len(groups); {
+		// Extend to the furthest group that keeps the candidate short enough
+		limit, digits := first, 0
+		for limit < len(groups) {
+			digits += groups[limit].end - groups[limit].start
+			if digits > maxDigits {
+				break
+			}
+			limit++
+		}
+
+		found := false
+		for last := limit - 1; last >= first; last-- {
+			if finding, ok := s.check(text, groups[first:last+1]); ok {
+				findings = append(findings, finding)
+				first = last + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			first++
+		}
+	}
+
+	return findings
+}
+
+// check confirms a single candidate
+func (s *Scanner) check(text string, groups []group) (Finding, bool) {
+	start, end := groups[0].start, groups[len(groups)-1].end
+
+	digits := digitsOf(text[start:end])
+	if len(digits) < minDigits || len(digits) > maxDigits {
+		return Finding{}, false
+	}
+
+	result, err := s.validator.ValidateCardSimple(digits)
+	if err != nil || result.CardType == service.CardTypeUnknown {
+		return Finding{}, false
+	}
+	for _, issue := range result.Issues {
+		if issue == service.IssueLuhnCheckFailed {
+			return Finding{}, false
+		}
+	}
+
+	return Finding{
+		Start:      start,
+		End:        end,
+		MaskedPAN:  service.MaskCardNumber(digits),
+		CardType:   result.CardType,
+		Confidence: confidence(text, groups, result.CardType),
+		digits:     digits,
+	}, true
+}
+
+// confidence scores a confirmed candidate between 0 and 1. Every reported
+// PAN passed Luhn and scheme checks; typical grouping, consistent separators
+// and nearby keywords make a real card number more likely.
+func confidence(text string, groups []group, cardType service.CardType) float64 {
+	score := 0.6
+
+	if len(groups) == 1 || standardGrouping(groups, cardType) {
+		score += 0.2
+	}
+
+	if consistentSeparators(text, groups) {
+		score += 0.1
+	}
+
+	from := groups[0].start - keywordWindow
+	if from < 0 {
+		from = 0
+	}
+	before := strings.ToLower(text[from:groups[0].start])
+	for _, keyword := range keywords {
+		if strings.Contains(before, keyword) {
+			score += 0.1
+			break
+		}
+	}
+
+	if score > 1 {
+		score = 1
+	}
+	return score
+}
+
+// standardGrouping reports whether the groups match how cards are printed
+func standardGrouping(groups []group, cardType service.CardType) bool {
+	sizes := make([]int, len(groups))
+	for i, g := range groups {
+		sizes[i] = g.end - g.start
+	}
+
+	switch cardType {
+	case service.CardTypeAmex:
+		return equalSizes(sizes, []int{4, 6, 5})
+	case service.CardTypeDinersClub:
+		return equalSizes(sizes, []int{4, 6, 4})
+	}
+
+	for i, size := range sizes {
+		if size != 4 && !(i == len(sizes)-1 && size < 4) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalSizes compares group sizes
+func equalSizes(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// consistentSeparators reports whether all groups use the same separator
+func consistentSeparators(text string, groups []group) bool {
+	for i := 2; i < len(groups); i++ {
+		if text[groups[i].start-1] != text[groups[1].start-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// Redact replaces every finding in text using replace. Findings must be in
+// order of appearance as returned by Scan.
+func Redact(text string, findings []Finding, replace func(text string, f Finding) string) string {
+	if len(findings) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+
+	last := 0
+	for _, f := range findings {
+		b.WriteString(text[last:f.Start])
+		b.WriteString(replace(text[f.Start:f.End], f))
+		last = f.End
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+// Mask masks every digit but the last four and keeps separators, so the
+// redacted text keeps its length and offsets
+func Mask(original string, f Finding) string {
+	remaining := len(f.digits)
+
+	masked := []byte(original)
+	for i := range masked {
+		if !isDigit(masked[i]) {
+			continue
+		}
+		if remaining > 4 {
+			masked[i] = '*'
+		}
+		remaining--
+	}
+
+	return string(masked)
+}
+
+// digitsOf returns the digits of s
+func digitsOf(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSeparator(c byte) bool {
+	return c == ' ' || c == '-' || c == '.'
+}
@@ -0,0 +1,567 @@
+package dlp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"credit-card-validator/internal/apperror"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// maxDocumentSize limits documents read into memory, which is needed for
+// documents inside archives and uploads
+const maxDocumentSize = 256 << 20
+
+// maxDocumentPartSize limits the decompressed size of one part of an office
+// document
+const maxDocumentPartSize = 256 << 20
+
+// ErrDocumentTooLarge is returned for documents above the size limits
+var ErrDocumentTooLarge = apperror.New(apperror.CodeDocumentTooLarge, "document exceeds the maximum size")
+
+// segment is a piece of document text with its location, such as a cell or
+// a page
+type segment struct {
+	location string
+	text     string
+}
+
+// documentExtractors extract text segments by file extension
+var documentExtractors = map[string]func(r io.ReaderAt, size int64) ([]segment, error){
+	".xlsx": extractXLSX,
+	".docx": extractDOCX,
+	".ods":  extractODS,
+	".pdf":  extractPDF,
+}
+
+// isDocument reports whether name is a supported document
+func isDocument(name string) bool {
+	_, ok := documentExtractors[strings.ToLower(path.Ext(name))]
+	return ok
+}
+
+// scanDocument extracts the text of a document and scans every segment.
+// Offsets of the findings are relative to their segment.
+func (s *Scanner) scanDocument(file, name string, r io.ReaderAt, size int64, opts *FileScanOptions) ([]FileFinding, error) {
+	extract := documentExtractors[strings.ToLower(path.Ext(name))]
+	segments, err := extract(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var minConfidence float64
+	if opts != nil {
+		minConfidence = opts.MinConfidence
+	}
+
+	var findings []FileFinding
+	for _, seg := range segments {
+		for _, f := range s.Scan(seg.text) {
+			if f.Confidence < minConfidence {
+				continue
+			}
+			findings = append(findings, FileFinding{
+				File:       file,
+				Location:   seg.location,
+				Offset:     int64(f.Start),
+				MaskedPAN:  f.MaskedPAN,
+				CardType:   f.CardType.String(),
+				Confidence: f.Confidence,
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// readDocument reads a document into memory so it can be opened at random
+func readDocument(r io.Reader) (*bytes.Reader, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDocumentSize {
+		return nil, ErrDocumentTooLarge
+	}
+	return bytes.NewReader(data), nil
+}
+
+// openPart opens a part of a zip based document
+func openPart(zr *zip.Reader, name string) (io.ReadCloser, error) {
+	file, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &limitedPart{Reader: io.LimitReader(file, maxDocumentPartSize), closer: file}, nil
+}
+
+// limitedPart caps the decompressed size of a document part
+type limitedPart struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (l *limitedPart) Close() error {
+	return l.closer.Close()
+}
+
+// extractXLSX returns the cells of every worksheet as Sheet!A1 segments
+func extractXLSX(r io.ReaderAt, size int64) ([]segment, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open xlsx: %w", err)
+	}
+
+	shared, err := xlsxSharedStrings(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets, err := xlsxSheets(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []segment
+	for _, sheet := range sheets {
+		cells, err := xlsxCells(zr, sheet.path, sheet.name, shared)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %s: %w", sheet.name, err)
+		}
+		segments = append(segments, cells...)
+	}
+
+	return segments, nil
+}
+
+// xlsxSheet is a worksheet and the part holding it
+type xlsxSheet struct {
+	name string
+	path string
+}
+
+// xlsxSheets lists the worksheets in workbook order
+func xlsxSheets(zr *zip.Reader) ([]xlsxSheet, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(zr, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(zr, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	sheets := make([]xlsxSheet, 0, len(workbook.Sheets))
+	for _, sheet := range workbook.Sheets {
+		if target, ok := targets[sheet.ID]; ok {
+			sheets = append(sheets, xlsxSheet{name: sheet.Name, path: target})
+		}
+	}
+	return sheets, nil
+}
+
+// xlsxSharedStrings reads the shared string table
+func xlsxSharedStrings(zr *zip.Reader) ([]string, error) {
+	part, err := openPart(zr, "xl/sharedStrings.xml")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer part.Close()
+
+	var (
+		strs    []string
+		current strings.Builder
+		inText  bool
+	)
+	decoder := xml.NewDecoder(part)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return strs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shared strings: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				strs = append(strs, current.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+}
+
+// xlsxCells reads the non-empty cells of a worksheet
+func xlsxCells(zr *zip.Reader, name, sheet string, shared []string) ([]segment, error) {
+	part, err := openPart(zr, name)
+	if err != nil {
+		return nil, err
+	}
+	defer part.Close()
+
+	var (
+		segments []segment
+		ref      string
+		cellType string
+		value    strings.Builder
+		inValue  bool
+	)
+	decoder := xml.NewDecoder(part)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return segments, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "c":
+				ref, cellType = attr(t, "r"), attr(t, "t")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "c":
+				text := value.String()
+				if cellType == "s" {
+					index, err := strconv.Atoi(strings.TrimSpace(text))
+					if err != nil || index < 0 || index >= len(shared) {
+						continue
+					}
+					text = shared[index]
+				}
+				if text != "" {
+					segments = append(segments, segment{location: sheet + "!" + ref, text: text})
+				}
+			case "v", "t":
+				inValue = false
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+// docxParts matches the parts of a Word document holding text
+var docxParts = []string{"word/document.xml", "word/header*.xml", "word/footer*.xml", "word/footnotes.xml", "word/endnotes.xml", "word/comments.xml"}
+
+// extractDOCX returns every paragraph as a segment located by part and
+// paragraph number, such as "paragraph 12" or "header1 paragraph 1"
+func extractDOCX(r io.ReaderAt, size int64) ([]segment, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open docx: %w", err)
+	}
+
+	var names []string
+	for _, file := range zr.File {
+		for _, pattern := range docxParts {
+			if ok, _ := path.Match(pattern, file.Name); ok {
+				names = append(names, file.Name)
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("failed to open docx: %s is missing", docxParts[0])
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// The body comes first
+		if names[i] == docxParts[0] || names[j] == docxParts[0] {
+			return names[i] == docxParts[0]
+		}
+		return names[i] < names[j]
+	})
+
+	var segments []segment
+	for _, name := range names {
+		prefix := ""
+		if name != docxParts[0] {
+			prefix = strings.TrimSuffix(path.Base(name), ".xml") + " "
+		}
+
+		paragraphs, err := docxParagraphs(zr, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for i, text := range paragraphs {
+			if text != "" {
+				segments = append(segments, segment{location: fmt.Sprintf("%sparagraph %d", prefix, i+1), text: text})
+			}
+		}
+	}
+
+	return segments, nil
+}
+
+// docxParagraphs returns the text of every paragraph of a part. Runs are
+// joined as written; tabs and breaks become spaces.
+func docxParagraphs(zr *zip.Reader, name string) ([]string, error) {
+	part, err := openPart(zr, name)
+	if err != nil {
+		return nil, err
+	}
+	defer part.Close()
+
+	var (
+		paragraphs []string
+		current    strings.Builder
+		inText     bool
+	)
+	decoder := xml.NewDecoder(part)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return paragraphs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				current.Reset()
+			case "t":
+				inText = true
+			case "tab", "br", "cr":
+				current.WriteByte(' ')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p":
+				paragraphs = append(paragraphs, current.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+}
+
+// extractODS returns the cells of every table as Sheet!A1 segments
+func extractODS(r io.ReaderAt, size int64) ([]segment, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ods: %w", err)
+	}
+
+	part, err := openPart(zr, "content.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ods: %w", err)
+	}
+	defer part.Close()
+
+	var (
+		segments   []segment
+		sheet      string
+		row        int
+		rowRepeat  int
+		column     int
+		cellRepeat int
+		value      string
+		text       strings.Builder
+		inCell     bool
+		inText     int
+	)
+	decoder := xml.NewDecoder(part)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return segments, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ods: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "table":
+				sheet, row = attr(t, "name"), 0
+			case "table-row":
+				row++
+				rowRepeat = repeated(t, "number-rows-repeated")
+				column = 0
+			case "table-cell", "covered-table-cell":
+				column++
+				cellRepeat = repeated(t, "number-columns-repeated")
+				value = attr(t, "value")
+				text.Reset()
+				inCell = true
+			case "p":
+				if inCell && text.Len() > 0 {
+					text.WriteByte(' ')
+				}
+				inText++
+			case "s", "tab", "line-break":
+				if inText > 0 {
+					text.WriteByte(' ')
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "table-row":
+				// Repeated rows share the content of the first one
+				row += rowRepeat - 1
+			case "table-cell", "covered-table-cell":
+				inCell = false
+				content := text.String()
+				if content == "" {
+					content = value
+				}
+				if content != "" {
+					segments = append(segments, segment{
+						location: fmt.Sprintf("%s!%s%d", sheet, columnName(column), row),
+						text:     content,
+					})
+				}
+				column += cellRepeat - 1
+			case "p":
+				inText--
+			}
+		case xml.CharData:
+			if inText > 0 {
+				text.Write(t)
+			}
+		}
+	}
+}
+
+// extractPDF returns the text of every page as a "page N" segment
+func extractPDF(r io.ReaderAt, size int64) (segments []segment, err error) {
+	// The PDF reader reports malformed files by panicking
+	defer func() {
+		if rec := recover(); rec != nil {
+			segments, err = nil, fmt.Errorf("failed to read pdf: %v", rec)
+		}
+	}()
+
+	reader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pdf: %w", err)
+	}
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pdf page %d: %w", i, err)
+		}
+		if text != "" {
+			segments = append(segments, segment{location: fmt.Sprintf("page %d", i), text: text})
+		}
+	}
+
+	return segments, nil
+}
+
+// decodePart decodes an XML part of a zip based document
+func decodePart(zr *zip.Reader, name string, v any) error {
+	part, err := openPart(zr, name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer part.Close()
+
+	if err := xml.NewDecoder(part).Decode(v); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
+
+// attr returns the value of an attribute by local name
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// repeated returns the repeat count of an ODS row or cell
+func repeated(e xml.StartElement, name string) int {
+	n, err := strconv.Atoi(attr(e, name))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// columnName converts a 1-based column number to letters, 1 = A, 27 = AA
+func columnName(n int) string {
+	var name []byte
+	for n > 0 {
+		n--
+		name = append([]byte{byte('A' + n%26)}, name...)
+		n /= 26
+	}
+	return string(name)
+}
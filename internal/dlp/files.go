@@ -0,0 +1,415 @@
+package dlp
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxArchiveDepth limits how deeply archives inside archives are opened
+const maxArchiveDepth = 3
+
+// defaultMaxLineSize is the longest line scanned at once; longer lines are
+// scanned in chunks
+const defaultMaxLineSize = 1 << 20
+
+// maxCarry bounds the digits and separators held back from one chunk of a
+// long line for the next, whatever the chunk size
+const maxCarry = 1 << 20
+
+// FileFinding is a PAN found in a file. Members of archives are named
+// archive!member. Findings in text files carry the line and the byte offset
+// in the (decompressed) file. Findings in documents carry the location, such
+// as Sheet1!B7 or page 3, and the byte offset in the text at that location.
+type FileFinding struct {
+	File       string  `json:"file"`
+	Line       int     `json:"line,omitempty"`
+	Location   string  `json:"location,omitempty"`
+	Offset     int64   `json:"offset"`
+	MaskedPAN  string  `json:"masked_pan"`
+	CardType   string  `json:"card_type"`
+	Confidence float64 `json:"confidence"`
+}
+
+// FileScanOptions controls which files are scanned and how
+type FileScanOptions struct {
+	// Include and Exclude are glob patterns matched against the base name and
+	// the slash separated path. Files are scanned when they match an include
+	// pattern (or none are given) and no exclude pattern. Excluded
+	// directories are skipped entirely. Archives are opened unless excluded
+	// and the patterns apply to their members.
+	Include []string
+	Exclude []string
+
+	// Workers is the number of files scanned in parallel
+	Workers int
+
+	// MinConfidence drops findings with a lower confidence
+	MinConfidence float64
+
+	// MaxLineSize is the longest line scanned at once
+	MaxLineSize int
+}
+
+// FileStats summarizes a file scan
+type FileStats struct {
+	Files    int64
+	Bytes    int64
+	Findings int64
+	Errors   int64
+}
+
+// FileReport receives the findings, or the error, of each scanned file
+type FileReport func(file string, findings []FileFinding, err error)
+
+// ScanFiles scans the files below roots. Gzip and tar archives are opened
+// and their members scanned. report is called once per scanned file and
+// never concurrently.
+func (s *Scanner) ScanFiles(ctx context.Context, roots []string, opts FileScanOptions, report FileReport) (*FileStats, error) {
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		stats    FileStats
+		reportMu sync.Mutex
+		wg       sync.WaitGroup
+	)
+	emit := func(file string, findings []FileFinding, err error) {
+		if err != nil {
+			atomic.AddInt64(&stats.Errors, 1)
+		}
+		atomic.AddInt64(&stats.Findings, int64(len(findings)))
+
+		reportMu.Lock()
+		defer reportMu.Unlock()
+		report(file, findings, err)
+	}
+
+	paths := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range paths {
+				s.scanPath(ctx, name, &opts, &stats, emit)
+			}
+		}()
+	}
+
+	var walkErr error
+	for _, root := range roots {
+		walkErr = filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				emit(name, nil, err)
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if d.IsDir() {
+				if name != root && matchAny(opts.Exclude, name) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			select {
+			case paths <- name:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			return nil
+		})
+		if walkErr != nil {
+			break
+		}
+	}
+
+	close(paths)
+	wg.Wait()
+
+	return &stats, walkErr
+}
+
+// scanPath scans one file from disk
+func (s *Scanner) scanPath(ctx context.Context, name string, opts *FileScanOptions, stats *FileStats, emit FileReport) {
+	file, err := os.Open(name)
+	if err != nil {
+		emit(name, nil, err)
+		return
+	}
+	defer file.Close()
+
+	s.scanEntry(ctx, name, name, file, opts, stats, emit, 0)
+}
+
+// scanEntry scans a file reported as display. The type of the file and the
+// patterns it must match are taken from name, which drops compression
+// suffixes and archive paths. Archives are opened unless excluded.
+func (s *Scanner) scanEntry(ctx context.Context, display, name string, r io.Reader, opts *FileScanOptions, stats *FileStats, emit FileReport, depth int) {
+	if ctx.Err() != nil || matchAny(opts.Exclude, name) {
+		return
+	}
+
+	lower := strings.ToLower(name)
+	if depth < maxArchiveDepth {
+		switch {
+		case strings.HasSuffix(lower, ".tar"):
+			s.scanTar(ctx, display, r, opts, stats, emit, depth)
+			return
+		case strings.HasSuffix(lower, ".tgz"):
+			s.scanGzip(ctx, display, name[:len(name)-len(".tgz")]+".tar", r, opts, stats, emit, depth)
+			return
+		case strings.HasSuffix(lower, ".gz"):
+			s.scanGzip(ctx, display, name[:len(name)-len(".gz")], r, opts, stats, emit, depth)
+			return
+		}
+	}
+
+	if len(opts.Include) > 0 && !matchAny(opts.Include, name) && !matchAny(opts.Include, display) {
+		return
+	}
+
+	if isDocument(name) {
+		s.scanDocumentEntry(display, name, r, opts, stats, emit)
+		return
+	}
+
+	counter := &countingReader{r: r}
+	findings, err := s.ScanStream(display, counter, opts)
+	atomic.AddInt64(&stats.Files, 1)
+	atomic.AddInt64(&stats.Bytes, counter.n)
+	emit(display, findings, err)
+}
+
+// scanDocumentEntry scans an office document or PDF. Files on disk are read
+// in place, other documents are read into memory first.
+func (s *Scanner) scanDocumentEntry(display, name string, r io.Reader, opts *FileScanOptions, stats *FileStats, emit FileReport) {
+	var (
+		readerAt io.ReaderAt
+		size     int64
+	)
+	if file, ok := r.(*os.File); ok {
+		info, err := file.Stat()
+		if err != nil {
+			emit(display, nil, err)
+			return
+		}
+		readerAt, size = file, info.Size()
+	} else {
+		data, err := readDocument(r)
+		if err != nil {
+			emit(display, nil, err)
+			return
+		}
+		readerAt, size = data, data.Size()
+	}
+
+	findings, err := s.scanDocument(display, name, readerAt, size, opts)
+	atomic.AddInt64(&stats.Files, 1)
+	atomic.AddInt64(&stats.Bytes, size)
+	emit(display, findings, err)
+}
+
+// scanGzip decompresses a gzip file and scans its content as inner
+func (s *Scanner) scanGzip(ctx context.Context, display, inner string, r io.Reader, opts *FileScanOptions, stats *FileStats, emit FileReport, depth int) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		emit(display, nil, fmt.Errorf("failed to open gzip: %w", err))
+		return
+	}
+	defer gz.Close()
+
+	s.scanEntry(ctx, display, inner, gz, opts, stats, emit, depth+1)
+}
+
+// scanTar scans the regular files of a tar archive
+func (s *Scanner) scanTar(ctx context.Context, display string, r io.Reader, opts *FileScanOptions, stats *FileStats, emit FileReport, depth int) {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			emit(display, nil, fmt.Errorf("failed to read tar: %w", err))
+			return
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		s.scanEntry(ctx, display+"!"+header.Name, header.Name, tr, opts, stats, emit, depth+1)
+	}
+}
+
+// ScanFile scans a single file read from r, such as an upload. Archives and
+// documents are recognized by name. The first error is returned with the
+// findings made before or after it.
+func (s *Scanner) ScanFile(ctx context.Context, name string, r io.Reader, opts *FileScanOptions) ([]FileFinding, error) {
+	if opts == nil {
+		opts = &FileScanOptions{}
+	}
+
+	var (
+		stats    FileStats
+		findings []FileFinding
+		firstErr error
+	)
+	s.scanEntry(ctx, name, name, r, opts, &stats, func(file string, found []FileFinding, err error) {
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", file, err)
+		}
+		findings = append(findings, found...)
+	}, 0)
+
+	return findings, firstErr
+}
+
+// ScanStream scans r line by line and returns the findings named file
+func (s *Scanner) ScanStream(file string, r io.Reader, opts *FileScanOptions) ([]FileFinding, error) {
+	maxLineSize := defaultMaxLineSize
+	var minConfidence float64
+	if opts != nil {
+		if opts.MaxLineSize > 0 {
+			maxLineSize = opts.MaxLineSize
+		}
+		minConfidence = opts.MinConfidence
+	}
+
+	reader := bufio.NewReaderSize(r, maxLineSize)
+
+	var (
+		findings []FileFinding
+		carry    []byte
+		offset   int64
+		line     = 1
+	)
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		text := append(carry, chunk...)
+
+		// A PAN cut by the end of a long line's chunk is held back and
+		// scanned with the next chunk
+		scanned, carried := len(text), len(text)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			scanned, carried = splitRun(text)
+		}
+
+		for _, f := range s.Scan(string(text[:scanned])) {
+			if f.Confidence < minConfidence {
+				continue
+			}
+			findings = append(findings, FileFinding{
+				File:       file,
+				Line:       line,
+				Offset:     offset + int64(f.Start),
+				MaskedPAN:  f.MaskedPAN,
+				CardType:   f.CardType.String(),
+				Confidence: f.Confidence,
+			})
+		}
+
+		offset += int64(carried)
+		carry = append([]byte(nil), text[carried:]...)
+		if bytes.HasSuffix(chunk, []byte{'\n'}) {
+			line++
+		}
+
+		switch {
+		case err == nil, errors.Is(err, bufio.ErrBufferFull):
+			// A long line continues in the next chunk
+		case errors.Is(err, io.EOF):
+			return findings, nil
+		default:
+			return findings, err
+		}
+	}
+}
+
+// splitRun splits a chunk that ends mid-line before the run of digit groups
+// at its end, which may continue in the next chunk. It returns the length of
+// the text to scan now and the offset of the text carried into the next
+// chunk. A group longer than maxDigits ends every candidate before it, so the
+// run is only carried from the last such group, and only the last
+// maxDigits+1 digits of that group, enough to keep it too long for a PAN.
+// Runs longer than maxCarry are cut.
+func splitRun(text []byte) (int, int) {
+	start := len(text)
+	for start > 0 && (isDigit(text[start-1]) || isSeparator(text[start-1])) {
+		start--
+	}
+
+	scanned, carried := start, start
+	for end := len(text); end > start; end-- {
+		if !isDigit(text[end-1]) {
+			continue
+		}
+		begin := end
+		for begin > start && isDigit(text[begin-1]) {
+			begin--
+		}
+		if end-begin > maxDigits {
+			scanned, carried = begin, end-maxDigits-1
+			break
+		}
+		end = begin
+	}
+
+	if len(text)-carried > maxCarry {
+		scanned, carried = len(text)-maxCarry, len(text)-maxCarry
+	}
+	return scanned, carried
+}
+
+// matchAny matches name, and its base name, against the patterns
+func matchAny(patterns []string, name string) bool {
+	slashed := filepath.ToSlash(name)
+	base := path.Base(slashed)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, slashed); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,240 @@
+// Package health reports liveness and readiness over HTTP and the standard
+// grpc.health.v1 service. Readiness combines dependency checks, which run in
+// the background so probes stay cheap, with the shutdown state: once the
+// server starts draining it reports not ready until it exits.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Report values
+const (
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+
+	CheckOK       = "ok"
+	CheckPending  = "pending"
+	CheckDraining = "draining"
+	CheckDegraded = "degraded"
+)
+
+// ShutdownCheck names the shutdown state in reports
+const ShutdownCheck = "shutdown"
+
+// CheckFunc checks a dependency; a non-nil error makes the server not ready
+type CheckFunc func(ctx context.Context) error
+
+// Report is the readiness of the server and the state of every check, "ok"
+// or the reason it fails. Failing optional checks are reported as
+// "degraded: <reason>".
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// check is a registered check and its latest result
+type check struct {
+	name     string
+	fn       CheckFunc
+	optional bool
+	done     bool
+	err      error
+}
+
+// Checker runs the readiness checks and publishes their outcome
+type Checker struct {
+	config *config.HealthConfig
+	logger logging.Logger
+
+	mu       sync.RWMutex
+	checks   []*check
+	draining bool
+
+	grpcServer *grpchealth.Server
+	services   []string
+}
+
+// NewChecker creates a checker without checks, ready until draining
+func NewChecker(config *config.HealthConfig, logger logging.Logger) *Checker {
+	if logger == nil {
+		logger = logging.FromLogrus(logrus.New())
+	}
+	return &Checker{
+		config:     config,
+		logger:     logger,
+		grpcServer: grpchealth.NewServer(),
+	}
+}
+
+// Add registers a check. It counts as pending, and the server as not ready,
+// until it has run once.
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, &check{name: name, fn: fn})
+}
+
+// AddOptional registers a check of a dependency the server can work without.
+// Its state is reported, but it never makes the server not ready.
+func (c *Checker) AddOptional(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, &check{name: name, fn: fn, optional: true})
+}
+
+// Run runs the checks immediately and then every check interval until ctx is done
+func (c *Checker) Run(ctx context.Context) {
+	c.Refresh(ctx)
+
+	ticker := time.NewTicker(c.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh runs every check once, concurrently, and publishes the result
+func (c *Checker) Refresh(ctx context.Context) {
+	c.mu.RLock()
+	checks := append([]*check(nil), c.checks...)
+	c.mu.RUnlock()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, chk := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.config.CheckTimeout)
+			defer cancel()
+			errs[i] = chk.fn(checkCtx)
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	for i, chk := range checks {
+		switch {
+		case errs[i] != nil && (chk.err == nil || !chk.done):
+			c.logger.Warn("Readiness check failing", "check", chk.name, logging.FieldError, errs[i])
+		case errs[i] == nil && chk.err != nil:
+			c.logger.Info("Readiness check recovered", "check", chk.name)
+		}
+		chk.done, chk.err = true, errs[i]
+	}
+	c.mu.Unlock()
+
+	c.publish()
+}
+
+// Drain marks the server as shutting down. Readiness fails from now on, so
+// load balancers stop sending traffic before the listeners close.
+func (c *Checker) Drain() {
+	c.mu.Lock()
+	c.draining = true
+	c.mu.Unlock()
+
+	// Shutdown sets every service to NOT_SERVING and ignores later updates
+	c.grpcServer.Shutdown()
+}
+
+// Report returns the current readiness
+func (c *Checker) Report() Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]string, len(c.checks)+1)}
+	for _, chk := range c.checks {
+		switch {
+		case !chk.done:
+			report.Checks[chk.name] = CheckPending
+		case chk.err != nil && chk.optional:
+			report.Checks[chk.name] = CheckDegraded + ": " + chk.err.Error()
+		case chk.err != nil:
+			report.Checks[chk.name] = chk.err.Error()
+		default:
+			report.Checks[chk.name] = CheckOK
+		}
+		if !chk.optional && report.Checks[chk.name] != CheckOK {
+			report.Status = StatusNotReady
+		}
+	}
+
+	report.Checks[ShutdownCheck] = CheckOK
+	if c.draining {
+		report.Checks[ShutdownCheck] = CheckDraining
+		report.Status = StatusNotReady
+	}
+	return report
+}
+
+// Ready reports whether the server can take traffic
+func (c *Checker) Ready() bool {
+	return c.Report().Status == StatusReady
+}
+
+// RegisterGRPC registers the grpc.health.v1 service on server. The overall
+// status, "", and that of every named service follow readiness.
+func (c *Checker) RegisterGRPC(server *grpc.Server, services ...string) {
+	c.mu.Lock()
+	c.services = services
+	c.mu.Unlock()
+
+	healthpb.RegisterHealthServer(server, c.grpcServer)
+	c.publish()
+}
+
+// publish sets the gRPC serving status from the readiness
+func (c *Checker) publish() {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if c.Ready() {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	c.mu.RLock()
+	services := c.services
+	c.mu.RUnlock()
+
+	c.grpcServer.SetServingStatus("", status)
+	for _, service := range services {
+		c.grpcServer.SetServingStatus(service, status)
+	}
+}
+
+// LivezHandler reports that the process is running. It does not depend on
+// any check, so a failing dependency never gets the server restarted.
+func (c *Checker) LivezHandler() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, map[string]string{"status": "alive"})
+	}
+}
+
+// ReadyzHandler reports readiness with 200, or 503 and the failing checks
+func (c *Checker) ReadyzHandler() echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		report := c.Report()
+		if report.Status != StatusReady {
+			return ctx.JSON(http.StatusServiceUnavailable, report)
+		}
+		return ctx.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,91 @@
+// Package jobs runs asynchronous validation of uploaded CSV and NDJSON files.
+// Job state is kept in an embedded bbolt database so that queued and running
+// jobs survive restarts.
+package jobs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"credit-card-validator/internal/apperror"
+)
+
+// Package-level errors for better error handling
+var (
+	ErrJobNotFound      = apperror.New(apperror.CodeJobNotFound, "job not found")
+	ErrJobNotFinished   = apperror.New(apperror.CodeJobNotFinished, "job has not finished")
+	ErrJobFinished      = apperror.New(apperror.CodeJobFinished, "job has already finished")
+	ErrUnsupportedInput = apperror.New(apperror.CodeUnsupportedFormat, "unsupported file format")
+	ErrNoResults        = apperror.New(apperror.CodeJobResultsNotFound, "job finished without results")
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+// Job statuses
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Finished reports whether the job reached a terminal state
+func (s Status) Finished() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCanceled
+}
+
+// Format is a supported input or results file format
+type Format string
+
+// Supported formats
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat parses a format name or file extension
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(value, ".")) {
+	case "csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedInput, value)
+	}
+}
+
+// Job describes a bulk validation job
+type Job struct {
+	ID          string     `json:"id"`
+	Status      Status     `json:"status"`
+	Format      Format     `json:"format"`
+	FileName    string     `json:"file_name,omitempty"`
+	Tenant      string     `json:"tenant"`
+	Owner       string     `json:"owner,omitempty"`
+	AuthMethod  string     `json:"-"`
+	EnrichBIN   bool       `json:"enrich_bin"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Valid       int        `json:"valid"`
+	Invalid     int        `json:"invalid"`
+	Errors      int        `json:"errors"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Progress returns the processed share of the job between 0 and 1
+func (j *Job) Progress() float64 {
+	if j.Total == 0 {
+		if j.Status.Finished() {
+			return 1
+		}
+		return 0
+	}
+	return float64(j.Processed) / float64(j.Total)
+}
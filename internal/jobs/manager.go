@@ -0,0 +1,606 @@
+package jobs
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrQueueFull is returned when no more jobs can be queued
+var ErrQueueFull = apperror.New(apperror.CodeQueueFull, "job queue is full")
+
+const (
+	inputFileName   = "input"
+	resultsFileName = "results.ndjson"
+
+	// progressInterval is the number of rows between persisted progress updates
+	progressInterval = 100
+)
+
+// jobIDPattern guards file system paths derived from job IDs
+var jobIDPattern = regexp.MustCompile(`^[a-f0-9]{32}$`)
+
+// Manager queues, runs and persists bulk validation jobs
+type Manager struct {
+	config   *config.JobsConfig
+	store    *store
+	tenants  *tenant.Registry
+	auditLog *audit.Logger
+	logger   logging.Logger
+
+	queue chan string
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+// NewManager opens the job store in the configured directory. auditLog may
+// be nil to disable auditing; nil logger logs with a default logrus logger.
+func NewManager(config *config.JobsConfig, tenants *tenant.Registry, auditLog *audit.Logger, logger logging.Logger) (*Manager, error) {
+	if logger == nil {
+		logger = logging.FromLogrus(logrus.New())
+	}
+
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+
+	store, err := openStore(filepath.Join(config.Dir, "jobs.db"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		config:   config,
+		store:    store,
+		tenants:  tenants,
+		auditLog: auditLog,
+		logger:   logger,
+		queue:    make(chan string, config.QueueSize),
+		running:  make(map[string]context.CancelFunc),
+	}, nil
+}
+
+// Start requeues jobs interrupted by a restart and runs the workers until
+// ctx is done
+func (m *Manager) Start(ctx context.Context) error {
+	pending, err := m.store.unfinished()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+
+	for _, job := range pending {
+		// Interrupted jobs restart from the beginning of their input
+		if job.Status == StatusRunning {
+			if _, err := m.store.update(job.ID, func(j *Job) error {
+				j.Status = StatusQueued
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case m.queue <- job.ID:
+		default:
+			m.logger.Warn("Job queue full, job stays queued until next restart", "job_id", job.ID)
+		}
+	}
+
+	workers := m.config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		m.wg.Add(1)
+		go m.worker(ctx)
+	}
+
+	return nil
+}
+
+// Close waits for the workers to stop and closes the store. Jobs still
+// running are resumed on the next start.
+func (m *Manager) Close() error {
+	m.wg.Wait()
+	return m.store.close()
+}
+
+// Submit stores the uploaded file and queues a job for it. The job belongs to
+// the tenant and identity carried by ctx.
+func (m *Manager) Submit(ctx context.Context, fileName string, format Format, r io.Reader, enrichBIN bool) (*Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := m.jobDir(id)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+
+	input, err := os.OpenFile(filepath.Join(dir, inputFileName), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create input file: %w", err)
+	}
+	_, err = io.Copy(input, r)
+	if closeErr := input.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to store input file: %w", err)
+	}
+
+	now := time.Now().UTC()
+	job := &Job{
+		ID:        id,
+		Status:    StatusQueued,
+		Format:    format,
+		FileName:  filepath.Base(fileName),
+		Tenant:    m.tenantID(ctx),
+		EnrichBIN: enrichBIN,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if identity, ok := auth.FromContext(ctx); ok {
+		job.Owner = identity.Subject
+		job.AuthMethod = identity.Method
+	}
+
+	if err := m.store.put(job); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	select {
+	case m.queue <- id:
+	default:
+		m.store.update(id, func(j *Job) error {
+			j.Status = StatusFailed
+			j.Error = ErrQueueFull.Error()
+			return nil
+		})
+		m.removeInput(ctx, id)
+		return nil, ErrQueueFull
+	}
+
+	return job, nil
+}
+
+// Get returns the job if it belongs to the tenant in ctx
+func (m *Manager) Get(ctx context.Context, id string) (*Job, error) {
+	if !jobIDPattern.MatchString(id) {
+		return nil, ErrJobNotFound
+	}
+
+	job, err := m.store.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jobs of other tenants are reported as missing
+	if job.Tenant != m.tenantID(ctx) {
+		return nil, ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// Cancel stops a queued or running job
+func (m *Manager) Cancel(ctx context.Context, id string) (*Job, error) {
+	if _, err := m.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	queued := false
+	job, err := m.store.update(id, func(j *Job) error {
+		if j.Status.Finished() {
+			return ErrJobFinished
+		}
+		if j.Status == StatusQueued {
+			now := time.Now().UTC()
+			j.Status = StatusCanceled
+			j.CompletedAt = &now
+			queued = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Running jobs remove their input when they stop
+	if queued {
+		m.removeInput(ctx, id)
+	}
+
+	m.mu.Lock()
+	if cancel, ok := m.running[id]; ok {
+		cancel()
+	}
+	m.mu.Unlock()
+
+	return job, nil
+}
+
+// OpenResults opens the results of a finished job. Jobs that finished before
+// writing any, such as jobs canceled while queued, return ErrNoResults.
+func (m *Manager) OpenResults(ctx context.Context, id string) (*os.File, error) {
+	job, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !job.Status.Finished() {
+		return nil, ErrJobNotFinished
+	}
+
+	file, err := os.Open(filepath.Join(m.jobDir(id), resultsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoResults
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results: %w", err)
+	}
+	return file, nil
+}
+
+// WriteResults writes the results of a finished job in the given format
+func (m *Manager) WriteResults(ctx context.Context, id string, format Format, w io.Writer) error {
+	file, err := m.OpenResults(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return WriteResults(w, file, format)
+}
+
+// WriteResults converts results read from r to the given format
+func WriteResults(w io.Writer, r io.Reader, format Format) error {
+	switch format {
+	case FormatNDJSON:
+		_, err := io.Copy(w, r)
+		return err
+	case FormatCSV:
+		return writeCSV(w, r)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedInput, format)
+	}
+}
+
+// worker runs queued jobs until ctx is done
+func (m *Manager) worker(ctx context.Context) {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-m.queue:
+			m.run(ctx, id)
+		}
+	}
+}
+
+// run processes a single job. The job logger is carried by the job context,
+// so the validator logs with the job ID and tenant.
+func (m *Manager) run(ctx context.Context, id string) {
+	logger := m.logger.With("job_id", id)
+
+	// The job can be canceled as soon as it is seen running, so the cancel
+	// function is registered before the status changes
+	jobCtx, cancel := context.WithCancel(ctx)
+	m.mu.Lock()
+	m.running[id] = cancel
+	m.mu.Unlock()
+
+	defer func() {
+		cancel()
+		m.mu.Lock()
+		delete(m.running, id)
+		m.mu.Unlock()
+	}()
+
+	job, err := m.store.update(id, func(j *Job) error {
+		if j.Status != StatusQueued {
+			return ErrJobFinished
+		}
+		j.Status = StatusRunning
+		j.Processed, j.Valid, j.Invalid, j.Errors = 0, 0, 0, 0
+		return nil
+	})
+	if err != nil {
+		// Canceled while queued
+		return
+	}
+
+	logger = logger.With(logging.FieldTenant, job.Tenant)
+	jobCtx = logging.NewContext(jobCtx, logger)
+
+	logger.Info("Job started")
+
+	err = m.process(jobCtx, job)
+
+	// Shutdown leaves the job running so it is resumed on the next start
+	if ctx.Err() != nil {
+		logger.Info("Job interrupted by shutdown")
+		return
+	}
+
+	// The input is removed before the final status is visible to clients
+	m.removeInput(ctx, id)
+
+	now := time.Now().UTC()
+	_, updateErr := m.store.update(id, func(j *Job) error {
+		j.Processed, j.Valid, j.Invalid, j.Errors, j.Total = job.Processed, job.Valid, job.Invalid, job.Errors, job.Total
+		j.CompletedAt = &now
+		switch {
+		case errors.Is(err, context.Canceled):
+			j.Status = StatusCanceled
+		case err != nil:
+			j.Status = StatusFailed
+			j.Error = err.Error()
+		default:
+			j.Status = StatusCompleted
+		}
+		return nil
+	})
+	if updateErr != nil {
+		logger.Error("Failed to update job", logging.FieldError, updateErr)
+	}
+
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.Error("Job failed", logging.FieldError, err)
+		return
+	}
+	logger.Info("Job finished")
+}
+
+// process validates every row of the job input and writes the results. The
+// results of the rows processed before a failure are kept.
+func (m *Manager) process(ctx context.Context, job *Job) (err error) {
+	t, ok := m.tenants.Get(job.Tenant)
+	if !ok {
+		return fmt.Errorf("tenant %q no longer exists", job.Tenant)
+	}
+
+	// Jobs act on behalf of their owner for auditing
+	ctx = tenant.NewContext(ctx, t)
+	if job.Owner != "" {
+		ctx = auth.NewContext(ctx, &auth.Identity{Subject: job.Owner, Method: job.AuthMethod, Tenant: job.Tenant})
+	}
+
+	inputPath := filepath.Join(m.jobDir(job.ID), inputFileName)
+
+	total, err := m.countRows(job.Format, inputPath)
+	if err != nil {
+		return err
+	}
+	job.Total = total
+
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open input: %w", err)
+	}
+	defer input.Close()
+
+	reader, err := newInputReader(job.Format, input)
+	if err != nil {
+		return err
+	}
+
+	output, err := os.Create(filepath.Join(m.jobDir(job.ID), resultsFileName))
+	if err != nil {
+		return fmt.Errorf("failed to create results: %w", err)
+	}
+	defer output.Close()
+
+	writer := bufio.NewWriter(output)
+	defer func() {
+		if flushErr := writer.Flush(); flushErr != nil && err == nil {
+			err = fmt.Errorf("failed to write results: %w", flushErr)
+		}
+		if err == nil {
+			err = output.Sync()
+		}
+	}()
+	encoder := json.NewEncoder(writer)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+
+		// Rows are charged to the tenant's rate limit like the cards of a
+		// batch; the job waits for its turn rather than failing them
+		if r.err == nil {
+			if err := t.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		record, err := m.validateRow(ctx, t.Validator, job, r)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+
+		job.Processed++
+		if job.Processed%progressInterval == 0 {
+			if err := m.saveProgress(job); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRow validates one input row and updates the job counters. Like the
+// API, it fails when the validation cannot be audited, so no result is
+// returned unaudited.
+func (m *Manager) validateRow(ctx context.Context, validator *service.Validator, job *Job, r row) (Record, error) {
+	record := Record{
+		Line:      r.line,
+		Reference: r.reference,
+		MaskedPAN: service.MaskCardNumber(digitsOnly(r.cardNumber)),
+	}
+
+	if r.err != nil {
+		record.Error = r.err.Error()
+		job.Errors++
+		return record, nil
+	}
+
+	var (
+		result *service.ValidationResult
+		err    error
+	)
+	if job.EnrichBIN {
+		result, err = validator.ValidateCard(ctx, r.cardNumber)
+	} else {
+		result, err = validator.ValidateCardSimple(r.cardNumber)
+	}
+	if err != nil {
+		record.Error = err.Error()
+		job.Errors++
+		return record, nil
+	}
+
+	if m.auditLog != nil {
+		if err := m.auditLog.RecordValidation(ctx, audit.ChannelJob, job.ID, result); err != nil {
+			logging.FromContext(ctx, m.logger.With("job_id", job.ID)).Error("Failed to write audit log", logging.FieldError, err)
+			return Record{}, apperror.Wrap(apperror.CodeAuditLogUnavailable, err, "audit log unavailable")
+		}
+	}
+
+	record.MaskedPAN = service.MaskCardNumber(result.CardNumber)
+	record.Valid = result.Valid
+	record.CardType = result.CardType.String()
+	for _, issue := range result.Issues {
+		record.Issues = append(record.Issues, issue.String())
+	}
+
+	if result.Valid {
+		job.Valid++
+	} else {
+		job.Invalid++
+	}
+
+	return record, nil
+}
+
+// removeInput deletes the uploaded input of a job once it has reached a final
+// status, so cleartext card numbers do not outlive the job
+func (m *Manager) removeInput(ctx context.Context, id string) {
+	err := os.Remove(filepath.Join(m.jobDir(id), inputFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logging.FromContext(ctx, m.logger).Error("Failed to remove job input", "job_id", id, logging.FieldError, err)
+	}
+}
+
+// saveProgress persists the counters of a running job
+func (m *Manager) saveProgress(job *Job) error {
+	_, err := m.store.update(job.ID, func(j *Job) error {
+		j.Processed, j.Valid, j.Invalid, j.Errors, j.Total = job.Processed, job.Valid, job.Invalid, job.Errors, job.Total
+		return nil
+	})
+	return err
+}
+
+// countRows counts the rows of an input file for progress reporting
+func (m *Manager) countRows(format Format, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open input: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := newInputReader(format, file)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		_, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read input: %w", err)
+		}
+		count++
+	}
+}
+
+// tenantID returns the tenant of the request in ctx
+func (m *Manager) tenantID(ctx context.Context) string {
+	if t, ok := tenant.FromContext(ctx); ok {
+		return t.ID
+	}
+	return tenant.DefaultID
+}
+
+// jobDir returns the directory holding the files of a job
+func (m *Manager) jobDir(id string) string {
+	return filepath.Join(m.config.Dir, id)
+}
+
+// newJobID returns a random job ID
+func newJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// digitsOnly strips everything but digits, so malformed input is never
+// written to results unmasked
+func digitsOnly(value string) string {
+	digits := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] >= '0' && value[i] <= '9' {
+			digits = append(digits, value[i])
+		}
+	}
+	return string(digits)
+}
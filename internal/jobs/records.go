@@ -0,0 +1,201 @@
+package jobs
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Record is the result of one input row. PANs are only written masked.
+type Record struct {
+	Line      int      `json:"line"`
+	Reference string   `json:"reference,omitempty"`
+	MaskedPAN string   `json:"masked_pan"`
+	Valid     bool     `json:"valid"`
+	CardType  string   `json:"card_type,omitempty"`
+	Issues    []string `json:"issues,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// csvHeader is the header row of CSV results
+var csvHeader = []string{"line", "reference", "masked_pan", "valid", "card_type", "issues", "error"}
+
+// row is one card number read from an input file
+type row struct {
+	line       int
+	reference  string
+	cardNumber string
+	err        error
+}
+
+// inputReader yields the rows of an input file
+type inputReader interface {
+	next() (row, error)
+}
+
+// newInputReader returns a reader for the given format
+func newInputReader(format Format, r io.Reader) (inputReader, error) {
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return &csvInput{reader: reader}, nil
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &ndjsonInput{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedInput, format)
+	}
+}
+
+// csvInput reads card numbers from a CSV column. A header row naming a
+// card_number or pan column selects it, otherwise the first column is used.
+type csvInput struct {
+	reader    *csv.Reader
+	started   bool
+	panColumn int
+	refColumn int
+}
+
+func (c *csvInput) next() (row, error) {
+	for {
+		record, err := c.reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return row{line: parseErr.Line, err: errors.New("malformed CSV row")}, nil
+			}
+			return row{}, err
+		}
+
+		if !c.started {
+			c.started = true
+			c.refColumn = -1
+			if c.parseHeader(record) {
+				continue
+			}
+		}
+
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		line, _ := c.reader.FieldPos(0)
+		r := row{line: line}
+		if c.panColumn < len(record) {
+			r.cardNumber = record[c.panColumn]
+		}
+		if c.refColumn >= 0 && c.refColumn < len(record) {
+			r.reference = record[c.refColumn]
+		}
+		return r, nil
+	}
+}
+
+// parseHeader detects a header row and its columns
+func (c *csvInput) parseHeader(record []string) bool {
+	isHeader := false
+	for i, field := range record {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "card_number", "pan", "card":
+			c.panColumn = i
+			isHeader = true
+		case "id", "reference", "ref":
+			c.refColumn = i
+			isHeader = true
+		}
+	}
+	if isHeader {
+		return true
+	}
+
+	// A first cell with letters is a header naming other columns
+	return len(record) > 0 && strings.IndexFunc(record[0], func(r rune) bool {
+		return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z'
+	}) >= 0
+}
+
+// ndjsonInput reads objects with a card_number (or pan) and optional id field
+type ndjsonInput struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonInput) next() (row, error) {
+	for n.scanner.Scan() {
+		n.line++
+		data := strings.TrimSpace(n.scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var item struct {
+			CardNumber string          `json:"card_number"`
+			PAN        string          `json:"pan"`
+			ID         json.RawMessage `json:"id"`
+			Reference  string          `json:"reference"`
+		}
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return row{line: n.line, err: errors.New("malformed JSON line")}, nil
+		}
+
+		r := row{line: n.line, cardNumber: item.CardNumber, reference: item.Reference}
+		if r.cardNumber == "" {
+			r.cardNumber = item.PAN
+		}
+		if r.reference == "" && len(item.ID) > 0 {
+			if s, err := strconv.Unquote(string(item.ID)); err == nil {
+				r.reference = s
+			} else {
+				r.reference = string(item.ID)
+			}
+		}
+		return r, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return row{}, err
+	}
+	return row{}, io.EOF
+}
+
+// writeCSV converts NDJSON records to CSV
+func writeCSV(w io.Writer, r io.Reader) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to decode result: %w", err)
+		}
+		err := writer.Write([]string{
+			strconv.Itoa(record.Line),
+			record.Reference,
+			record.MaskedPAN,
+			strconv.FormatBool(record.Valid),
+			record.CardType,
+			strings.Join(record.Issues, ";"),
+			record.Error,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var jobsBucket = []byte("jobs")
+
+// store persists jobs in a bbolt database
+type store struct {
+	db *bolt.DB
+}
+
+// openStore opens or creates the job database at path
+func openStore(path string) (*store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job store: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(jobsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize job store: %w", err)
+	}
+
+	return &store{db: db}, nil
+}
+
+// put saves the job
+func (s *store) put(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put([]byte(job.ID), data)
+	})
+}
+
+// get loads the job with the given ID
+func (s *store) get(id string) (*Job, error) {
+	var job *Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(jobsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrJobNotFound
+		}
+		job = &Job{}
+		return json.Unmarshal(data, job)
+	})
+	return job, err
+}
+
+// update loads the job, applies fn and saves it in one transaction
+func (s *store) update(id string, fn func(job *Job) error) (*Job, error) {
+	var job *Job
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(jobsBucket)
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return ErrJobNotFound
+		}
+
+		job = &Job{}
+		if err := json.Unmarshal(data, job); err != nil {
+			return fmt.Errorf("failed to decode job: %w", err)
+		}
+		if err := fn(job); err != nil {
+			return err
+		}
+		job.UpdatedAt = time.Now().UTC()
+
+		data, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("failed to encode job: %w", err)
+		}
+		return bucket.Put([]byte(id), data)
+	})
+	return job, err
+}
+
+// unfinished returns the jobs that were queued or running
+func (s *store) unfinished() ([]*Job, error) {
+	var jobs []*Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(_, data []byte) error {
+			var job Job
+			if err := json.Unmarshal(data, &job); err != nil {
+				return fmt.Errorf("failed to decode job: %w", err)
+			}
+			if !job.Status.Finished() {
+				jobs = append(jobs, &job)
+			}
+			return nil
+		})
+	})
+	return jobs, err
+}
+
+// close closes the database
+func (s *store) close() error {
+	return s.db.Close()
+}
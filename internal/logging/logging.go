@@ -0,0 +1,143 @@
+// Package logging carries a request-scoped logger through the context. The
+// REST middleware and gRPC interceptors store a logger annotated with the
+// request ID, method, client identity and tenant, so every log entry written
+// while serving a request, down to the validator, can be correlated.
+//
+// Logger follows the log/slog calling convention: a message followed by
+// alternating keys and values. Adapters are provided for logrus and slog.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Standard field names
+const (
+	FieldRequestID = "request_id"
+	FieldMethod    = "method"
+	FieldClient    = "client"
+	FieldTenant    = "tenant"
+	FieldError     = "error"
+)
+
+// Logger is a structured logger. args are alternating keys and values.
+type Logger interface {
+	With(args ...any) Logger
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// loggerKey is the context key of the request logger
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying logger
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or fallback when there is none
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// With returns a copy of ctx whose logger carries the additional fields. ctx
+// is returned unchanged when it carries no logger.
+func With(ctx context.Context, args ...any) context.Context {
+	logger, ok := ctx.Value(loggerKey{}).(Logger)
+	if !ok {
+		return ctx
+	}
+	return NewContext(ctx, logger.With(args...))
+}
+
+// FromLogrus adapts a logrus logger
+func FromLogrus(logger *logrus.Logger) Logger {
+	return logrusLogger{entry: logrus.NewEntry(logger)}
+}
+
+// FromSlog adapts a slog logger
+func FromSlog(logger *slog.Logger) Logger {
+	return slogLogger{logger: logger}
+}
+
+// Discard returns a logger that writes nothing
+func Discard() Logger {
+	return FromSlog(slog.New(slog.DiscardHandler))
+}
+
+// logrusLogger writes key-value pairs as logrus fields
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+func (l logrusLogger) With(args ...any) Logger {
+	return logrusLogger{entry: l.entry.WithFields(fields(args))}
+}
+
+func (l logrusLogger) Debug(msg string, args ...any) {
+	l.entry.WithFields(fields(args)).Debug(msg)
+}
+
+func (l logrusLogger) Info(msg string, args ...any) {
+	l.entry.WithFields(fields(args)).Info(msg)
+}
+
+func (l logrusLogger) Warn(msg string, args ...any) {
+	l.entry.WithFields(fields(args)).Warn(msg)
+}
+
+func (l logrusLogger) Error(msg string, args ...any) {
+	l.entry.WithFields(fields(args)).Error(msg)
+}
+
+// fields converts alternating keys and values to logrus fields. A key
+// without a value is logged under !BADKEY, as slog does.
+func fields(args []any) logrus.Fields {
+	f := make(logrus.Fields, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			f["!BADKEY"] = args[i]
+			break
+		}
+		key, ok := args[i].(string)
+		if !ok {
+			key = fmt.Sprint(args[i])
+		}
+		f[key] = args[i+1]
+	}
+	return f
+}
+
+// slogLogger adapts *slog.Logger, whose With returns the concrete type
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) With(args ...any) Logger {
+	return slogLogger{logger: l.logger.With(args...)}
+}
+
+func (l slogLogger) Debug(msg string, args ...any) {
+	l.logger.Debug(msg, args...)
+}
+
+func (l slogLogger) Info(msg string, args ...any) {
+	l.logger.Info(msg, args...)
+}
+
+func (l slogLogger) Warn(msg string, args ...any) {
+	l.logger.Warn(msg, args...)
+}
+
+func (l slogLogger) Error(msg string, args ...any) {
+	l.logger.Error(msg, args...)
+}
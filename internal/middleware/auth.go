@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+// identityContextKey is the echo context key holding the authenticated identity
+const identityContextKey = "identity"
+
+// Authenticate verifies the client credentials of every request and stores
+// the resulting identity in both the echo and the request context.
+func Authenticate(authenticator auth.Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			identity, err := authenticator.Authenticate(req.Context(), credentialsFromRequest(req))
+			if err != nil {
+				if !errors.Is(err, auth.ErrMissingCredentials) && !errors.Is(err, auth.ErrInvalidCredentials) {
+					c.Logger().Errorf("authentication failed: %v", err)
+				}
+				return apperror.Respond(c, apperror.New(apperror.CodeUnauthenticated, "Authentication required"))
+			}
+
+			if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+				identity.CertSubject = req.TLS.PeerCertificates[0].Subject.String()
+			}
+
+			c.Set(identityContextKey, identity)
+			ctx := logging.With(req.Context(), logging.FieldClient, identity.Subject)
+			c.SetRequest(req.WithContext(auth.NewContext(ctx, identity)))
+
+			return next(c)
+		}
+	}
+}
+
+// RequireScope rejects requests whose identity lacks the scope
+func RequireScope(scope auth.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			identity, _ := c.Get(identityContextKey).(*auth.Identity)
+			if identity == nil {
+				return apperror.Respond(c, apperror.New(apperror.CodeUnauthenticated, "Authentication required"))
+			}
+
+			if !identity.HasScope(scope) {
+				return apperror.Respond(c, apperror.New(apperror.CodePermissionDenied, "Missing required scope: "+scope.String()))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// credentialsFromRequest extracts the credentials presented in the request headers
+func credentialsFromRequest(req *http.Request) auth.Credentials {
+	creds := auth.Credentials{
+		APIKey: req.Header.Get("X-API-Key"),
+	}
+
+	authorization := req.Header.Get(echo.HeaderAuthorization)
+	if value, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		creds.BearerToken = strings.TrimSpace(value)
+	} else if value, ok := strings.CutPrefix(authorization, "ApiKey "); ok && creds.APIKey == "" {
+		creds.APIKey = strings.TrimSpace(value)
+	}
+
+	return creds
+}
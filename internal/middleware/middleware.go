@@ -4,6 +4,8 @@ import (
 	"strconv"
 	"time"
 
+	"credit-card-validator/internal/logging"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,7 +18,7 @@ var (
 			Name: "card_validation_requests_total",
 			Help: "Total number of card validation requests",
 		},
-		[]string{"method", "endpoint", "status"},
+		[]string{"method", "endpoint", "status", "tenant"},
 	)
 
 	requestDuration = promauto.NewHistogramVec(
@@ -30,7 +32,7 @@ var (
 	validationErrors = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "card_validation_errors_total",
-			Help: "Total number of card validation errors",
+			Help: "Total number of HTTP error responses per status code",
 		},
 		[]string{"error_type"},
 	)
@@ -40,7 +42,28 @@ func RequestID() echo.MiddlewareFunc {
 	return middleware.RequestID()
 }
 
-func Metrics() echo.MiddlewareFunc {
+// ContextLogger stores a request logger in the request context, annotated
+// with the request ID and the method and route. It must run after RequestID;
+// Authenticate and Tenant add the client and tenant.
+func ContextLogger(logger logging.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := logging.NewContext(req.Context(), logger.With(
+				logging.FieldRequestID, c.Response().Header().Get(echo.HeaderXRequestID),
+				logging.FieldMethod, req.Method+" "+c.Path(),
+			))
+			c.SetRequest(req.WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+// Metrics measures requests by route, status and tenant. Routes served by
+// the gateway or gRPC-Web resolve the tenant in the gRPC server, which
+// returns it in tenantHeader.
+func Metrics(tenantHeader string) echo.MiddlewareFunc {
 	return echo.MiddlewareFunc(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			start := time.Now()
@@ -49,11 +72,16 @@ func Metrics() echo.MiddlewareFunc {
 
 			duration := time.Since(start)
 			status := c.Response().Status
+			tenantID, ok := c.Get(tenantContextKey).(string)
+			if !ok {
+				tenantID = c.Response().Header().Get(tenantHeader)
+			}
 
 			requestsTotal.WithLabelValues(
 				c.Request().Method,
 				c.Path(),
 				strconv.Itoa(status),
+				tenantID,
 			).Inc()
 
 			requestDuration.WithLabelValues(
@@ -62,7 +90,7 @@ func Metrics() echo.MiddlewareFunc {
 			).Observe(duration.Seconds())
 
 			if status >= 400 {
-				validationErrors.WithLabelValues("http_error").Inc()
+				validationErrors.WithLabelValues("http_" + strconv.Itoa(status)).Inc()
 			}
 
 			return err
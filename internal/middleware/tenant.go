@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/tenant"
+
+	"github.com/labstack/echo/v4"
+)
+
+// tenantContextKey is the echo context key holding the resolved tenant ID
+const tenantContextKey = "tenant"
+
+// Tenant resolves the tenant of each request from the authenticated identity
+// or the tenant header and enforces the tenant rate limit. The tenant is
+// returned in the tenant header of the response. It must run after
+// Authenticate when authentication is enabled.
+func Tenant(registry *tenant.Registry) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			identity, _ := c.Get(identityContextKey).(*auth.Identity)
+			t, err := registry.Resolve(identity, req.Header.Get(registry.Header()))
+			if err != nil {
+				return apperror.Respond(c, err)
+			}
+
+			c.Set(tenantContextKey, t.ID)
+			c.Response().Header().Set(registry.Header(), t.ID)
+
+			if !t.Allow() {
+				return apperror.Respond(c, apperror.New(apperror.CodeRateLimited, "Rate limit exceeded"))
+			}
+
+			ctx := logging.With(req.Context(), logging.FieldTenant, t.ID)
+			c.SetRequest(req.WithContext(tenant.NewContext(ctx, t)))
+
+			return next(c)
+		}
+	}
+}
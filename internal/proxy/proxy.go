@@ -0,0 +1,390 @@
+// Package proxy implements a reverse proxy that redacts card numbers (PANs)
+// in request and response bodies before they reach the upstream or the
+// client.
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/logging"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// Directions of proxied bodies
+const (
+	directionRequest  = "request"
+	directionResponse = "response"
+)
+
+// defaultRouteName labels requests not matching a configured route
+const defaultRouteName = "default"
+
+var (
+	redactionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_proxy_redactions_total",
+			Help: "Total number of PANs redacted by the proxy",
+		},
+		[]string{"route", "direction", "action", "card_type"},
+	)
+
+	skippedBodies = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_proxy_skipped_bodies_total",
+			Help: "Total number of proxied bodies passed through without scanning",
+		},
+		[]string{"route", "direction", "reason"},
+	)
+
+	rejectedBodies = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_proxy_rejected_bodies_total",
+			Help: "Total number of proxied bodies rejected because they could not be scanned",
+		},
+		[]string{"route", "direction", "reason"},
+	)
+)
+
+// Reasons a body cannot be scanned
+const (
+	reasonContentType     = "content_type"
+	reasonContentEncoding = "content_encoding"
+	reasonTooLarge        = "too_large"
+)
+
+// errBodyTooLarge is returned when a decoded body exceeds the size limit
+var errBodyTooLarge = errors.New("body exceeds PROXY_MAX_BODY_SIZE")
+
+// rejectedError is returned for bodies that cannot be scanned in fail-closed
+// mode. Requests are answered with status; responses with 502.
+type rejectedError struct {
+	reason string
+	status int
+}
+
+func (e *rejectedError) Error() string {
+	return "body cannot be scanned: " + e.reason
+}
+
+// route is a configured route with parsed actions
+type route struct {
+	name     string
+	prefix   string
+	methods  map[string]bool
+	request  Action
+	response Action
+}
+
+// matches reports whether the route applies to a request
+func (r *route) matches(req *http.Request) bool {
+	if !strings.HasPrefix(req.URL.Path, r.prefix) {
+		return false
+	}
+	return len(r.methods) == 0 || r.methods[req.Method]
+}
+
+// Proxy forwards requests to an upstream and redacts PANs in bodies
+type Proxy struct {
+	config   *config.ProxyConfig
+	scanner  *dlp.Scanner
+	logger   logging.Logger
+	routes   []*route
+	fallback *route
+	tokenKey []byte
+	proxy    *httputil.ReverseProxy
+}
+
+// NewProxy creates a proxy for the configured upstream. Routes are matched by
+// the longest path prefix. nil logger logs with a default logrus logger.
+func NewProxy(cfg *config.ProxyConfig, routes []config.ProxyRoute, scanner *dlp.Scanner, logger logging.Logger) (*Proxy, error) {
+	if logger == nil {
+		logger = logging.FromLogrus(logrus.New())
+	}
+
+	upstream, err := url.Parse(cfg.Upstream)
+	if err != nil || upstream.Scheme == "" || upstream.Host == "" {
+		return nil, fmt.Errorf("invalid proxy upstream %q", cfg.Upstream)
+	}
+
+	defaultRequest, err := ParseAction(cfg.DefaultRequest)
+	if err != nil {
+		return nil, err
+	}
+	defaultResponse, err := ParseAction(cfg.DefaultResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Proxy{
+		config:   cfg,
+		scanner:  scanner,
+		logger:   logger,
+		fallback: &route{name: defaultRouteName, request: defaultRequest, response: defaultResponse},
+		tokenKey: []byte(cfg.TokenKey),
+	}
+
+	tokenize := defaultRequest == ActionTokenize || defaultResponse == ActionTokenize
+	for _, rc := range routes {
+		if !strings.HasPrefix(rc.Path, "/") {
+			return nil, fmt.Errorf("proxy route path %q must start with /", rc.Path)
+		}
+
+		r := &route{name: rc.Path, prefix: rc.Path, request: defaultRequest, response: defaultResponse}
+		if rc.Request != "" {
+			if r.request, err = ParseAction(rc.Request); err != nil {
+				return nil, fmt.Errorf("proxy route %s: %w", rc.Path, err)
+			}
+		}
+		if rc.Response != "" {
+			if r.response, err = ParseAction(rc.Response); err != nil {
+				return nil, fmt.Errorf("proxy route %s: %w", rc.Path, err)
+			}
+		}
+		if len(rc.Methods) > 0 {
+			r.methods = make(map[string]bool, len(rc.Methods))
+			for _, method := range rc.Methods {
+				r.methods[strings.ToUpper(method)] = true
+			}
+		}
+
+		tokenize = tokenize || r.request == ActionTokenize || r.response == ActionTokenize
+		p.routes = append(p.routes, r)
+	}
+
+	if tokenize && len(p.tokenKey) == 0 {
+		return nil, errors.New("PROXY_TOKEN_KEY is required to tokenize")
+	}
+
+	sort.SliceStable(p.routes, func(i, j int) bool {
+		return len(p.routes[i].prefix) > len(p.routes[j].prefix)
+	})
+
+	p.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(upstream)
+			pr.SetXForwarded()
+			// Let the transport negotiate compression so responses arrive
+			// decompressed and can be scanned
+			pr.Out.Header.Del("Accept-Encoding")
+		},
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			var rejected *rejectedError
+			if errors.As(err, &rejected) {
+				p.log(r.Context()).Warn("Response body cannot be scanned, rejected", "reason", rejected.reason)
+			} else {
+				p.log(r.Context()).Error("Proxy request failed", logging.FieldError, err)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+
+	return p, nil
+}
+
+// routeKey is the context key of the route of a proxied request
+type routeKey struct{}
+
+// ServeHTTP redacts the request body and forwards the request. The request
+// context carries the route and a logger annotated with the request.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt := p.match(r)
+	ctx := context.WithValue(r.Context(), routeKey{}, rt)
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx, p.logger).With(
+		"route", rt.name,
+		logging.FieldMethod, r.Method,
+		"path", r.URL.Path,
+	))
+	r = r.WithContext(ctx)
+
+	if rt.request != ActionNone && r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0 {
+		body, length, err := p.redact(ctx, rt, directionRequest, rt.request, r.Header, r.Body)
+		var rejected *rejectedError
+		if errors.As(err, &rejected) {
+			p.log(ctx).Warn("Request body cannot be scanned, rejected", "reason", rejected.reason)
+			w.WriteHeader(rejected.status)
+			return
+		}
+		if err != nil {
+			p.log(ctx).Error("Failed to read request body", logging.FieldError, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		r.Body = body
+		if length >= 0 {
+			r.ContentLength = length
+			r.Header.Set("Content-Length", strconv.FormatInt(length, 10))
+			r.TransferEncoding = nil
+		}
+	}
+
+	p.proxy.ServeHTTP(w, r)
+}
+
+// modifyResponse redacts the response body
+func (p *Proxy) modifyResponse(resp *http.Response) error {
+	rt, ok := resp.Request.Context().Value(routeKey{}).(*route)
+	if !ok || rt.response == ActionNone {
+		return nil
+	}
+
+	// These responses have no body and must keep their headers
+	if resp.Request.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.ContentLength == 0 || resp.Body == http.NoBody {
+		return nil
+	}
+
+	body, length, err := p.redact(resp.Request.Context(), rt, directionResponse, rt.response, resp.Header, resp.Body)
+	var rejected *rejectedError
+	if errors.As(err, &rejected) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	resp.Body = body
+	if length >= 0 {
+		resp.ContentLength = length
+		resp.Header.Set("Content-Length", strconv.FormatInt(length, 10))
+		resp.TransferEncoding = nil
+	}
+	return nil
+}
+
+// redact returns the body with PANs replaced and its length. Gzip encoded
+// bodies are decoded before scanning and returned decoded. Opaque bodies are
+// returned unchanged with a length of -1. Text bodies that cannot be scanned
+// are rejected in fail-closed mode and returned unchanged otherwise.
+func (p *Proxy) redact(ctx context.Context, rt *route, direction string, action Action, header http.Header, body io.ReadCloser) (io.ReadCloser, int64, error) {
+	kind := kindOf(header.Get("Content-Type"))
+	if kind == bodyOpaque {
+		skippedBodies.WithLabelValues(rt.name, direction, reasonContentType).Inc()
+		return body, -1, nil
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	gzipped := encoding == "gzip" || encoding == "x-gzip"
+	if encoding != "" && encoding != "identity" && !gzipped {
+		return p.skip(rt, direction, reasonContentEncoding, http.StatusUnsupportedMediaType, body)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(body, p.config.MaxBodySize+1))
+	if err != nil {
+		body.Close()
+		return nil, 0, err
+	}
+
+	data := raw
+	tooLarge := int64(len(raw)) > p.config.MaxBodySize
+	if gzipped && !tooLarge {
+		data, err = gunzip(raw, p.config.MaxBodySize)
+		switch {
+		case errors.Is(err, errBodyTooLarge):
+			tooLarge = true
+		case err != nil:
+			body.Close()
+			return nil, 0, err
+		}
+	}
+
+	if tooLarge {
+		// The part already read is replayed in front of the rest of the body
+		replay := &passthroughBody{Reader: io.MultiReader(bytes.NewReader(raw), body), Closer: body}
+		if _, _, err := p.skip(rt, direction, reasonTooLarge, http.StatusRequestEntityTooLarge, replay); err != nil {
+			return nil, 0, err
+		}
+		p.log(ctx).Warn("Body exceeds PROXY_MAX_BODY_SIZE and was not scanned", "direction", direction)
+		return replay, -1, nil
+	}
+	body.Close()
+
+	// The body is forwarded decoded
+	if gzipped {
+		header.Del("Content-Encoding")
+	}
+
+	redacted, findings := redactBody(p.scanner, kind, data, replacer(action, p.tokenKey))
+	for _, f := range findings {
+		redactionsTotal.WithLabelValues(rt.name, direction, string(action), f.CardType.String()).Inc()
+	}
+	if len(findings) > 0 {
+		p.log(ctx).Info("Redacted PANs in proxied body",
+			"direction", direction,
+			"action", action,
+			"redactions", len(findings),
+		)
+	}
+
+	return io.NopCloser(bytes.NewReader(redacted)), int64(len(redacted)), nil
+}
+
+// skip handles a body that cannot be scanned: it is rejected with status in
+// fail-closed mode and passed through unchanged otherwise
+func (p *Proxy) skip(rt *route, direction, reason string, status int, body io.ReadCloser) (io.ReadCloser, int64, error) {
+	if p.config.FailClosed {
+		body.Close()
+		rejectedBodies.WithLabelValues(rt.name, direction, reason).Inc()
+		return nil, 0, &rejectedError{reason: reason, status: status}
+	}
+	skippedBodies.WithLabelValues(rt.name, direction, reason).Inc()
+	return body, -1, nil
+}
+
+// gunzip decodes a gzip body of at most limit bytes
+func gunzip(data []byte, limit int64) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip body: %w", err)
+	}
+	defer zr.Close()
+
+	decoded, err := io.ReadAll(io.LimitReader(zr, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip body: %w", err)
+	}
+	if int64(len(decoded)) > limit {
+		return nil, errBodyTooLarge
+	}
+	return decoded, nil
+}
+
+// log returns the request logger of ctx
+func (p *Proxy) log(ctx context.Context) logging.Logger {
+	return logging.FromContext(ctx, p.logger)
+}
+
+// passthroughBody replays the part of a body that was already read
+type passthroughBody struct {
+	io.Reader
+	io.Closer
+}
+
+// match returns the route of a request
+func (p *Proxy) match(r *http.Request) *route {
+	for _, rt := range p.routes {
+		if rt.matches(r) {
+			return rt
+		}
+	}
+	return p.fallback
+}
@@ -0,0 +1,191 @@
+package proxy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/url"
+	"sort"
+	"strings"
+
+	"credit-card-validator/internal/dlp"
+)
+
+// Action is what the proxy does with PANs found in a body
+type Action string
+
+// Supported actions
+const (
+	ActionNone     Action = "none"
+	ActionMask     Action = "mask"
+	ActionTokenize Action = "tokenize"
+)
+
+// ParseAction parses an action name
+func ParseAction(value string) (Action, error) {
+	switch action := Action(strings.ToLower(strings.TrimSpace(value))); action {
+	case ActionNone, ActionMask, ActionTokenize:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown redaction action %q", value)
+	}
+}
+
+// tokenPrefix marks tokens replacing PANs
+const tokenPrefix = "tok_"
+
+// replacer returns the replacement function for an action. Tokens are a
+// keyed hash of the PAN, so the same PAN always gets the same token.
+func replacer(action Action, tokenKey []byte) func(original string, f dlp.Finding) string {
+	if action == ActionTokenize {
+		return func(_ string, f dlp.Finding) string {
+			mac := hmac.New(sha256.New, tokenKey)
+			mac.Write([]byte(f.PAN()))
+			return tokenPrefix + hex.EncodeToString(mac.Sum(nil))[:24]
+		}
+	}
+	return dlp.Mask
+}
+
+// bodyKind is how a body is parsed for redaction
+type bodyKind int
+
+const (
+	// bodyOpaque bodies are not text and are forwarded unscanned
+	bodyOpaque bodyKind = iota
+	bodyText
+	bodyJSON
+	bodyForm
+)
+
+// kindOf returns the body kind for a Content-Type header. Bodies without a
+// Content-Type are opaque.
+func kindOf(contentType string) bodyKind {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return bodyOpaque
+	}
+
+	switch {
+	case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		return bodyJSON
+	case mediaType == "application/x-www-form-urlencoded":
+		return bodyForm
+	case mediaType == "text/event-stream":
+		// Event streams never end, so they cannot be buffered
+		return bodyOpaque
+	case strings.HasPrefix(mediaType, "text/"):
+		return bodyText
+	default:
+		return bodyOpaque
+	}
+}
+
+// redactBody replaces the PANs in a body and returns the new body with the
+// findings
+func redactBody(scanner *dlp.Scanner, kind bodyKind, body []byte, replace func(string, dlp.Finding) string) ([]byte, []dlp.Finding) {
+	switch kind {
+	case bodyJSON:
+		return redactJSON(scanner, body, replace)
+	case bodyForm:
+		return redactForm(scanner, body, replace)
+	default:
+		text := string(body)
+		findings := scanner.Scan(text)
+		if len(findings) == 0 {
+			return body, nil
+		}
+		return []byte(dlp.Redact(text, findings, replace)), findings
+	}
+}
+
+// redactJSON redacts JSON text. PANs written as JSON numbers are replaced by
+// strings so the document stays valid.
+func redactJSON(scanner *dlp.Scanner, body []byte, replace func(string, dlp.Finding) string) ([]byte, []dlp.Finding) {
+	text := string(body)
+	findings := scanner.Scan(text)
+	if len(findings) == 0 {
+		return body, nil
+	}
+
+	inString := stringRanges(text)
+	redacted := dlp.Redact(text, findings, func(original string, f dlp.Finding) string {
+		if inString(f.Start) {
+			return replace(original, f)
+		}
+		return `"` + replace(original, f) + `"`
+	})
+
+	return []byte(redacted), findings
+}
+
+// stringRanges returns a function reporting whether a byte offset of a JSON
+// text lies inside a string
+func stringRanges(text string) func(offset int) bool {
+	var starts, ends []int
+
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			if inString {
+				ends = append(ends, i)
+			} else {
+				starts = append(starts, i)
+			}
+			inString = !inString
+		}
+	}
+	if inString {
+		ends = append(ends, len(text))
+	}
+
+	return func(offset int) bool {
+		// The last string opening before offset is the only candidate
+		i := sort.SearchInts(starts, offset) - 1
+		return i >= 0 && offset < ends[i]
+	}
+}
+
+// redactForm redacts the values of a URL encoded form, keeping the order of
+// its fields
+func redactForm(scanner *dlp.Scanner, body []byte, replace func(string, dlp.Finding) string) ([]byte, []dlp.Finding) {
+	var (
+		all     []dlp.Finding
+		changed bool
+	)
+
+	fields := strings.Split(string(body), "&")
+	for i, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+
+		decoded, err := url.QueryUnescape(value)
+		if err != nil {
+			continue
+		}
+
+		findings := scanner.Scan(decoded)
+		if len(findings) == 0 {
+			continue
+		}
+
+		fields[i] = key + "=" + url.QueryEscape(dlp.Redact(decoded, findings, replace))
+		all = append(all, findings...)
+		changed = true
+	}
+
+	if !changed {
+		return body, nil
+	}
+	return []byte(strings.Join(fields, "&")), all
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchResult is the outcome of validating one card of a batch. Exactly one
+// of Result and Err is set.
+type BatchResult struct {
+	Index  int
+	Result *ValidationResult
+	Err    error
+}
+
+// ValidateCards validates a batch of card numbers with at most concurrency
+// workers. Each distinct BIN is looked up only once per batch. Results are
+// returned in input order.
+func (v *Validator) ValidateCards(ctx context.Context, cardNumbers []string, concurrency int) []BatchResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BatchResult, len(cardNumbers))
+	lookup := newBatchLookup(v.getBINInfo)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(cardNumbers); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := v.validateCard(ctx, cardNumbers[i], lookup.get)
+				results[i] = BatchResult{Index: i, Result: result, Err: err}
+			}
+		}()
+	}
+
+	for i := range cardNumbers {
+		if ctx.Err() != nil {
+			results[i] = BatchResult{Index: i, Err: ctx.Err()}
+			continue
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// ValidateCardsSimple validates a batch of card numbers without BIN lookup
+func (v *Validator) ValidateCardsSimple(cardNumbers []string) []BatchResult {
+	results := make([]BatchResult, len(cardNumbers))
+	for i, cardNumber := range cardNumbers {
+		result, err := v.ValidateCardSimple(cardNumber)
+		results[i] = BatchResult{Index: i, Result: result, Err: err}
+	}
+	return results
+}
+
+// batchLookup de-duplicates concurrent and repeated BIN lookups of a batch
+type batchLookup struct {
+	lookup binLookupFunc
+
+	mu    sync.Mutex
+	calls map[string]*binCall
+}
+
+// binCall is a single, possibly in-flight, BIN lookup
+type binCall struct {
+	done   chan struct{}
+	result *ValidationResult
+	err    error
+}
+
+func newBatchLookup(lookup binLookupFunc) *batchLookup {
+	return &batchLookup{
+		lookup: lookup,
+		calls:  make(map[string]*binCall),
+	}
+}
+
+// get returns the BIN information, performing the lookup on first use
+func (b *batchLookup) get(ctx context.Context, bin string) (*ValidationResult, error) {
+	b.mu.Lock()
+	call, ok := b.calls[bin]
+	if !ok {
+		call = &binCall{done: make(chan struct{})}
+		b.calls[bin] = call
+	}
+	b.mu.Unlock()
+
+	if !ok {
+		call.result, call.err = b.lookup(ctx, bin)
+		close(call.done)
+	}
+
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
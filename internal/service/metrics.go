@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// BIN lookup outcomes. no_bin means the card number was too short to look up.
+const (
+	lookupSuccess         = "success"
+	lookupNotFound        = "not_found"
+	lookupRateLimited     = "rate_limited"
+	lookupHTTPError       = "http_error"
+	lookupTimeout         = "timeout"
+	lookupCanceled        = "canceled"
+	lookupInvalidResponse = "invalid_response"
+	lookupError           = "error"
+	lookupNoBIN           = "no_bin"
+)
+
+// issueNone labels validations without issues
+const issueNone = "none"
+
+var (
+	validationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_validations_total",
+			Help: "Card validations per tenant, card type and validity",
+		},
+		[]string{"tenant", "card_type", "valid"},
+	)
+
+	validationIssuesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_issues_total",
+			Help: "Validation issues per tenant, card type and issue code",
+		},
+		[]string{"tenant", "card_type", "issue"},
+	)
+
+	rejectedInputsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_rejected_inputs_total",
+			Help: "Card numbers rejected before validation per tenant and reason",
+		},
+		[]string{"tenant", "reason"},
+	)
+
+	binLookupsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_bin_lookups_total",
+			Help: "BIN service lookups per provider and outcome",
+		},
+		[]string{"provider", "outcome"},
+	)
+
+	binLookupDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "card_validation_bin_lookup_duration_seconds",
+			Help:    "Duration of BIN service lookups per provider and outcome",
+			Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"provider", "outcome"},
+	)
+
+	enrichmentFallbacksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_enrichment_fallbacks_total",
+			Help: "Validations returned without BIN data after a failed lookup, per tenant, provider and reason",
+		},
+		[]string{"tenant", "provider", "reason"},
+	)
+)
+
+// recordValidation counts a completed validation and its issues
+func (v *Validator) recordValidation(result *ValidationResult) {
+	if !v.metrics {
+		return
+	}
+
+	cardType := result.CardType.String()
+	valid := "false"
+	if result.Valid {
+		valid = "true"
+	}
+	validationsTotal.WithLabelValues(v.tenant, cardType, valid).Inc()
+
+	if len(result.Issues) == 0 {
+		validationIssuesTotal.WithLabelValues(v.tenant, cardType, issueNone).Inc()
+	}
+	for _, issue := range result.Issues {
+		validationIssuesTotal.WithLabelValues(v.tenant, cardType, issue.String()).Inc()
+	}
+}
+
+// recordRejectedInput counts a card number rejected as malformed, either
+// without digits or with too few or too many
+func (v *Validator) recordRejectedInput(cardNumber string) {
+	if !v.metrics {
+		return
+	}
+
+	reason := "invalid_length"
+	if v.sanitizeRegex.ReplaceAllString(cardNumber, "") == "" {
+		reason = "no_digits"
+	}
+	rejectedInputsTotal.WithLabelValues(v.tenant, reason).Inc()
+}
+
+// recordFallback counts a validation returned without BIN data because the
+// lookup failed with err
+func (v *Validator) recordFallback(err error) {
+	if !v.metrics {
+		return
+	}
+	enrichmentFallbacksTotal.WithLabelValues(v.tenant, v.binProvider, lookupOutcome(err)).Inc()
+}
+
+// observeBINLookup records the outcome and duration of a BIN service call
+func (v *Validator) observeBINLookup(start time.Time, err error) {
+	outcome := lookupOutcome(err)
+	binLookupsTotal.WithLabelValues(v.binProvider, outcome).Inc()
+	binLookupDuration.WithLabelValues(v.binProvider, outcome).Observe(time.Since(start).Seconds())
+}
+
+// lookupOutcome classifies the result of a BIN lookup
+func lookupOutcome(err error) string {
+	var statusErr *binStatusError
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return lookupSuccess
+	case errors.Is(err, ErrCardNumberTooShort):
+		return lookupNoBIN
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.StatusCode == http.StatusNotFound:
+			return lookupNotFound
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			return lookupRateLimited
+		default:
+			return lookupHTTPError
+		}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return lookupTimeout
+	case errors.Is(err, context.Canceled):
+		return lookupCanceled
+	case errors.Is(err, ErrInvalidBINResponse):
+		return lookupInvalidResponse
+	default:
+		return lookupError
+	}
+}
@@ -5,25 +5,29 @@ package service
 
 import (
 	"context"
+	"credit-card-validator/internal/apperror"
 	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/tracing"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Package-level errors for better error handling
 var (
-	ErrInvalidCardNumber  = errors.New("invalid card number format")
-	ErrCardNumberTooShort = errors.New("card number too short")
-	ErrBINLookupFailed    = errors.New("BIN lookup service unavailable")
-	ErrInvalidBINResponse = errors.New("invalid BIN service response")
+	ErrInvalidCardNumber  = apperror.New(apperror.CodeInvalidCardNumber, "invalid card number format")
+	ErrCardNumberTooShort = apperror.New(apperror.CodeCardNumberTooShort, "card number too short")
+	ErrBINLookupFailed    = apperror.New(apperror.CodeBINLookupFailed, "BIN lookup service unavailable")
+	ErrInvalidBINResponse = apperror.New(apperror.CodeInvalidBINResponse, "invalid BIN service response")
 )
 
 // CardType represents the different types of credit cards supported
@@ -55,6 +59,41 @@ func (c CardType) IsValid() bool {
 	}
 }
 
+// IssueCode identifies a reason why a card failed validation
+type IssueCode string
+
+// Validation issue codes
+const (
+	IssueLuhnCheckFailed     IssueCode = "luhn_check_failed"
+	IssueCardTypeNotAccepted IssueCode = "card_type_not_accepted"
+)
+
+// String returns the string representation of IssueCode
+func (i IssueCode) String() string {
+	return string(i)
+}
+
+// EnrichmentStatus reports whether a result was enriched with BIN data
+type EnrichmentStatus string
+
+// Enrichment statuses
+const (
+	EnrichmentNotRequested EnrichmentStatus = "not_requested"
+	EnrichmentDisabled     EnrichmentStatus = "disabled"
+	EnrichmentSkipped      EnrichmentStatus = "skipped"
+	EnrichmentEnriched     EnrichmentStatus = "enriched"
+	EnrichmentFailed       EnrichmentStatus = "failed"
+)
+
+// EnrichmentSourceBINService names the remote BIN lookup service as the
+// source of issuer data
+const EnrichmentSourceBINService = "bin_service"
+
+// String returns the string representation of EnrichmentStatus
+func (e EnrichmentStatus) String() string {
+	return string(e)
+}
+
 // CountryInfo contains geographical and currency information about the card issuer
 type CountryInfo struct {
 	Name      string  `json:"name"`
@@ -84,6 +123,10 @@ type ValidationResult struct {
 	Bank       BankInfo    `json:"bank"`
 	BIN        string      `json:"bin"`
 	LastFour   string      `json:"last_four"`
+	Issues     []IssueCode `json:"issues,omitempty"`
+
+	EnrichmentStatus EnrichmentStatus `json:"enrichment_status"`
+	EnrichmentSource string           `json:"enrichment_source,omitempty"`
 }
 
 // DefaultConfig returns a default configuration
@@ -99,21 +142,29 @@ func DefaultConfig() *config.ValidatorConfig {
 // Validator provides credit card validation services
 type Validator struct {
 	config     *config.ValidatorConfig
-	logger     *logrus.Logger
+	logger     logging.Logger
 	httpClient *http.Client
+	tenant     string
+
+	// metrics enables the validation metrics, recorded for tenant validators
+	metrics bool
+	// binProvider labels BIN lookup metrics, the host of the BIN service
+	binProvider string
 
 	// Pre-compiled regex for better performance
 	sanitizeRegex *regexp.Regexp
 }
 
-// NewValidator creates a new validator instance with the provided configuration
-func NewValidator(config *config.ValidatorConfig, logger *logrus.Logger) (*Validator, error) {
+// NewValidator creates a new validator instance with the provided
+// configuration. logger is used for calls whose context carries no request
+// logger; nil logs with a default logrus logger.
+func NewValidator(config *config.ValidatorConfig, logger logging.Logger) (*Validator, error) {
 	if config == nil {
 		config = DefaultConfig()
 	}
 
 	if logger == nil {
-		logger = logrus.New()
+		logger = logging.FromLogrus(logrus.New())
 	}
 
 	// Pre-compile regex for better performance
@@ -126,38 +177,95 @@ func NewValidator(config *config.ValidatorConfig, logger *logrus.Logger) (*Valid
 		config: config,
 		logger: logger,
 		httpClient: &http.Client{
-			Timeout: config.HTTPTimeout,
+			Timeout:   config.HTTPTimeout,
+			Transport: tracing.NewTransport(nil),
 		},
 		sanitizeRegex: sanitizeRegex,
+		binProvider:   binProvider(config.BINServiceURL),
 	}, nil
 }
 
+// binProvider returns the host of the BIN service, or the URL when it has none
+func binProvider(serviceURL string) string {
+	if u, err := url.Parse(serviceURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return serviceURL
+}
+
+// NewTenantValidator creates a validator whose log entries carry the tenant
+// ID and that records validation metrics labelled with it
+func NewTenantValidator(tenant string, config *config.ValidatorConfig, logger logging.Logger) (*Validator, error) {
+	v, err := NewValidator(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	v.tenant = tenant
+	v.metrics = true
+	return v, nil
+}
+
+// Tenant returns the tenant the validator serves, empty for a shared validator
+func (v *Validator) Tenant() string {
+	return v.tenant
+}
+
 // ValidateCard performs comprehensive validation of a credit card number
 func (v *Validator) ValidateCard(ctx context.Context, cardNumber string) (*ValidationResult, error) {
+	return v.validateCard(ctx, cardNumber, v.getBINInfo)
+}
+
+// binLookupFunc retrieves BIN information for a BIN
+type binLookupFunc func(ctx context.Context, bin string) (*ValidationResult, error)
+
+// validateCard validates a card number using lookup for BIN enrichment.
+// Within a traced request every stage of the pipeline is recorded as a span.
+func (v *Validator) validateCard(ctx context.Context, cardNumber string, lookup binLookupFunc) (_ *ValidationResult, err error) {
+	ctx, span := tracing.StartChild(ctx, "ValidateCard")
+	defer func() { tracing.End(span, err) }()
+
 	// Sanitize the card number
+	_, sanitizeSpan := tracing.StartChild(ctx, "sanitize")
 	sanitized := v.sanitizeCardNumber(cardNumber)
+	sanitizeSpan.End()
 	if sanitized == "" {
+		v.recordRejectedInput(cardNumber)
 		return nil, ErrInvalidCardNumber
 	}
 
 	// Initialize result
-	result := &ValidationResult{
-		CardNumber: sanitized,
-		CardType:   v.detectCardType(sanitized),
-		Valid:      v.luhnValidation(sanitized),
-		BIN:        v.extractBIN(sanitized),
-		LastFour:   v.extractLastFour(sanitized),
-	}
+	result := v.newResult(ctx, sanitized)
 
 	// Perform BIN lookup if enabled and card is valid
-	if v.config.EnableBINLookup && result.Valid {
-		if err := v.enrichWithBINInfo(ctx, result); err != nil {
-			v.logger.WithError(err).Warn("Failed to enrich with BIN information")
+	switch {
+	case !v.config.EnableBINLookup:
+		result.EnrichmentStatus = EnrichmentDisabled
+	case !result.Valid:
+		result.EnrichmentStatus = EnrichmentSkipped
+	default:
+		enrichCtx, enrichSpan := tracing.StartChild(ctx, "enrich")
+		err := v.enrichWithBINInfo(enrichCtx, result, lookup)
+		tracing.End(enrichSpan, err)
+		if err != nil {
+			v.log(ctx).Warn("Failed to enrich with BIN information", logging.FieldError, err)
+			v.recordFallback(err)
+			result.EnrichmentStatus = EnrichmentFailed
+		} else {
+			result.EnrichmentStatus = EnrichmentEnriched
+			result.EnrichmentSource = EnrichmentSourceBINService
 		}
 	}
 
+	span.SetAttributes(
+		attribute.String("card.type", result.CardType.String()),
+		attribute.Bool("card.valid", result.Valid),
+		attribute.String("card.enrichment_status", string(result.EnrichmentStatus)),
+	)
+
 	// Log validation result
-	v.logValidationResult(result)
+	v.logValidationResult(ctx, result)
+	v.recordValidation(result)
 
 	return result, nil
 }
@@ -166,16 +274,57 @@ func (v *Validator) ValidateCard(ctx context.Context, cardNumber string) (*Valid
 func (v *Validator) ValidateCardSimple(cardNumber string) (*ValidationResult, error) {
 	sanitized := v.sanitizeCardNumber(cardNumber)
 	if sanitized == "" {
+		v.recordRejectedInput(cardNumber)
 		return nil, ErrInvalidCardNumber
 	}
 
-	return &ValidationResult{
+	result := v.newResult(context.Background(), sanitized)
+	result.EnrichmentStatus = EnrichmentNotRequested
+	v.recordValidation(result)
+	return result, nil
+}
+
+// newResult builds the offline validation result for a sanitized card number
+func (v *Validator) newResult(ctx context.Context, sanitized string) *ValidationResult {
+	_, detectSpan := tracing.StartChild(ctx, "detect")
+	cardType := v.detectCardType(sanitized)
+	detectSpan.End()
+
+	_, luhnSpan := tracing.StartChild(ctx, "luhn")
+	valid := v.luhnValidation(sanitized)
+	luhnSpan.End()
+
+	result := &ValidationResult{
 		CardNumber: sanitized,
-		CardType:   v.detectCardType(sanitized),
-		Valid:      v.luhnValidation(sanitized),
+		CardType:   cardType,
+		Valid:      valid,
 		BIN:        v.extractBIN(sanitized),
 		LastFour:   v.extractLastFour(sanitized),
-	}, nil
+	}
+
+	if !result.Valid {
+		result.Issues = append(result.Issues, IssueLuhnCheckFailed)
+	}
+
+	if !v.isAccepted(result.CardType) {
+		result.Valid = false
+		result.Issues = append(result.Issues, IssueCardTypeNotAccepted)
+	}
+
+	return result
+}
+
+// isAccepted checks the card type against the accepted card types
+func (v *Validator) isAccepted(cardType CardType) bool {
+	if len(v.config.AcceptedCardTypes) == 0 {
+		return true
+	}
+	for _, accepted := range v.config.AcceptedCardTypes {
+		if strings.EqualFold(accepted, cardType.String()) {
+			return true
+		}
+	}
+	return false
 }
 
 // sanitizeCardNumber removes all non-digit characters from the card number
@@ -283,6 +432,12 @@ func (v *Validator) extractLastFour(cardNumber string) string {
 
 // maskCardNumber masks sensitive parts of the card number for logging
 func (v *Validator) maskCardNumber(cardNumber string) string {
+	return MaskCardNumber(cardNumber)
+}
+
+// MaskCardNumber keeps the first and last four digits of a card number and
+// masks the rest
+func MaskCardNumber(cardNumber string) string {
 	if len(cardNumber) < 8 {
 		return strings.Repeat("*", len(cardNumber))
 	}
@@ -290,12 +445,12 @@ func (v *Validator) maskCardNumber(cardNumber string) string {
 }
 
 // enrichWithBINInfo enriches the validation result with BIN lookup data
-func (v *Validator) enrichWithBINInfo(ctx context.Context, result *ValidationResult) error {
+func (v *Validator) enrichWithBINInfo(ctx context.Context, result *ValidationResult, lookup binLookupFunc) error {
 	if result.BIN == "" {
 		return ErrCardNumberTooShort
 	}
 
-	binInfo, err := v.getBINInfo(ctx, result.BIN)
+	binInfo, err := lookup(ctx, result.BIN)
 	if err != nil {
 		return fmt.Errorf("BIN lookup failed: %w", err)
 	}
@@ -311,20 +466,37 @@ func (v *Validator) enrichWithBINInfo(ctx context.Context, result *ValidationRes
 }
 
 // logValidationResult logs the validation result appropriately
-func (v *Validator) logValidationResult(result *ValidationResult) {
-	fields := logrus.Fields{
-		"card_type": result.CardType,
-		"valid":     result.Valid,
-		"bin":       result.BIN,
+func (v *Validator) logValidationResult(ctx context.Context, result *ValidationResult) {
+	cardNumber := result.CardNumber
+	if v.config.MaskSensitive {
+		cardNumber = v.maskCardNumber(result.CardNumber)
 	}
 
-	if v.config.MaskSensitive {
-		fields["card_number"] = v.maskCardNumber(result.CardNumber)
-	} else {
-		fields["card_number"] = result.CardNumber
+	v.log(ctx).Info("Card validation completed",
+		"card_type", result.CardType,
+		"valid", result.Valid,
+		"bin", result.BIN,
+		"card_number", cardNumber,
+	)
+}
+
+// log returns the request logger carried by ctx, which already identifies
+// the request and its tenant, or else the validator's logger with its tenant
+func (v *Validator) log(ctx context.Context) logging.Logger {
+	logger := v.logger
+	if v.tenant != "" {
+		logger = logger.With(logging.FieldTenant, v.tenant)
 	}
+	return logging.FromContext(ctx, logger)
+}
+
+// binStatusError reports an unexpected status from the BIN service
+type binStatusError struct {
+	StatusCode int
+}
 
-	v.logger.WithFields(fields).Info("Card validation completed")
+func (e *binStatusError) Error() string {
+	return fmt.Sprintf("BIN service returned status %d", e.StatusCode)
 }
 
 // BINInfo represents the response from BIN lookup service
@@ -348,10 +520,11 @@ type binInfo struct {
 }
 
 // getBINInfo retrieves BIN information from the lookup service
-func (v *Validator) getBINInfo(ctx context.Context, bin string) (*ValidationResult, error) {
-	url := fmt.Sprintf("%s/%s", v.config.BINServiceURL, bin)
+func (v *Validator) getBINInfo(ctx context.Context, bin string) (_ *ValidationResult, err error) {
+	start := time.Now()
+	defer func() { v.observeBINLookup(start, err) }()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s", v.config.BINServiceURL, bin), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -368,12 +541,12 @@ func (v *Validator) getBINInfo(ctx context.Context, bin string) (*ValidationResu
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("BIN service returned status %d", resp.StatusCode)
+		return nil, &binStatusError{StatusCode: resp.StatusCode}
 	}
 
 	var binData binInfo
 	if err := json.NewDecoder(resp.Body).Decode(&binData); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBINResponse, err)
 	}
 
 	// Convert binInfo to our result format
@@ -399,6 +572,32 @@ func (v *Validator) getBINInfo(ctx context.Context, bin string) (*ValidationResu
 	return result, nil
 }
 
+// CheckBINService reports whether the BIN lookup service is reachable. It
+// succeeds without a request when BIN lookup is disabled. Any response below
+// 500 counts as reachable, so the check does not spend lookup quota on a BIN.
+func (v *Validator) CheckBINService(ctx context.Context) error {
+	if !v.config.EnableBINLookup {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, v.config.BINServiceURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "ccvalidator/1.0")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBINLookupFailed, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: status %d", ErrBINLookupFailed, resp.StatusCode)
+	}
+	return nil
+}
+
 // IsValidCardNumber is a convenience function for quick validation
 func IsValidCardNumber(cardNumber string) bool {
 	validator, err := NewValidator(DefaultConfig(), nil)
@@ -0,0 +1,129 @@
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// forwarder delivers redacted messages downstream
+type forwarder interface {
+	forward(msg string) error
+	Close() error
+}
+
+// newForwarder creates a forwarder for a udp://host:port, tcp://host:port or
+// file:///path target
+func newForwarder(target string, timeout time.Duration) (forwarder, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog forward target %q: %w", target, err)
+	}
+
+	switch u.Scheme {
+	case "udp":
+		conn, err := net.DialTimeout("udp", u.Host, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", target, err)
+		}
+		return &udpForwarder{conn: conn}, nil
+	case "tcp":
+		return &tcpForwarder{addr: u.Host, timeout: timeout}, nil
+	case "file":
+		file, err := os.OpenFile(u.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", u.Path, err)
+		}
+		return &fileForwarder{file: file}, nil
+	default:
+		return nil, fmt.Errorf("unsupported syslog forward target %q", target)
+	}
+}
+
+// udpForwarder sends one datagram per message
+type udpForwarder struct {
+	conn net.Conn
+}
+
+func (u *udpForwarder) forward(msg string) error {
+	_, err := u.conn.Write([]byte(msg))
+	return err
+}
+
+func (u *udpForwarder) Close() error {
+	return u.conn.Close()
+}
+
+// tcpForwarder sends octet-counted messages (RFC 6587) over a connection
+// that is re-established after errors
+type tcpForwarder struct {
+	addr    string
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (t *tcpForwarder) forward(msg string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frame := []byte(strconv.Itoa(len(msg)) + " " + msg)
+
+	// Retry once on a fresh connection in case the old one was closed
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if t.conn == nil {
+			t.conn, err = net.DialTimeout("tcp", t.addr, t.timeout)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", t.addr, err)
+			}
+		}
+
+		t.conn.SetWriteDeadline(time.Now().Add(t.timeout))
+		if _, err = t.conn.Write(frame); err == nil {
+			return nil
+		}
+
+		t.conn.Close()
+		t.conn = nil
+	}
+	return err
+}
+
+func (t *tcpForwarder) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// fileForwarder appends one message per line to a file
+type fileForwarder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (f *fileForwarder) forward(msg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err := f.file.WriteString(msg + "\n")
+	return err
+}
+
+func (f *fileForwarder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
@@ -0,0 +1,105 @@
+package syslog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Message formats
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatRFC3164 = "rfc3164"
+	FormatUnknown = "unknown"
+)
+
+// header holds the parts of a syslog header used for routing and metrics
+type header struct {
+	format   string
+	hostname string
+}
+
+// parseHeader reads the format and hostname of an RFC 5424 or RFC 3164
+// message. Messages that match neither are still forwarded.
+func parseHeader(msg string) header {
+	rest, ok := skipPriority(msg)
+	if !ok {
+		return header{format: FormatUnknown}
+	}
+
+	// RFC 5424: VERSION SP TIMESTAMP SP HOSTNAME SP ...
+	if version, after, found := strings.Cut(rest, " "); found && isVersion(version) {
+		fields := strings.SplitN(after, " ", 3)
+		if len(fields) >= 2 {
+			return header{format: FormatRFC5424, hostname: nilValue(fields[1])}
+		}
+		return header{format: FormatRFC5424}
+	}
+
+	// RFC 3164: Mmm dd hh:mm:ss SP HOSTNAME SP TAG ...
+	const timestampLen = len("Jan _2 15:04:05")
+	if len(rest) > timestampLen && isBSDTimestamp(rest[:timestampLen]) {
+		fields := strings.SplitN(strings.TrimLeft(rest[timestampLen:], " "), " ", 2)
+		// A colon or bracket marks the tag of a message without hostname
+		if len(fields) == 2 && !strings.ContainsAny(fields[0], ":[") {
+			return header{format: FormatRFC3164, hostname: fields[0]}
+		}
+		return header{format: FormatRFC3164}
+	}
+
+	return header{format: FormatUnknown}
+}
+
+// skipPriority returns the message after a <PRI> prefix
+func skipPriority(msg string) (string, bool) {
+	if !strings.HasPrefix(msg, "<") {
+		return "", false
+	}
+
+	end := strings.IndexByte(msg, '>')
+	if end < 2 || end > 4 {
+		return "", false
+	}
+
+	priority, err := strconv.Atoi(msg[1:end])
+	if err != nil || priority < 0 || priority > 191 {
+		return "", false
+	}
+
+	return msg[end+1:], true
+}
+
+// isVersion reports whether s is an RFC 5424 version number
+func isVersion(s string) bool {
+	if len(s) == 0 || len(s) > 2 || s[0] == '0' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// months are the month abbreviations of RFC 3164 timestamps
+var months = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// isBSDTimestamp reports whether s looks like "Mmm dd hh:mm:ss"
+func isBSDTimestamp(s string) bool {
+	month := false
+	for _, m := range months {
+		if strings.HasPrefix(s, m+" ") {
+			month = true
+			break
+		}
+	}
+	return month && s[6] == ' ' && s[9] == ':' && s[12] == ':'
+}
+
+// nilValue maps the RFC 5424 nil value to an empty string
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
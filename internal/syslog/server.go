@@ -0,0 +1,328 @@
+// Package syslog receives syslog messages over UDP and TCP, masks card
+// numbers (PANs) in them and forwards them to a downstream syslog server or
+// file.
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/logging"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// otherSource is the metric label of senders missing from SYSLOG_SOURCES
+const otherSource = "other"
+
+// maxLengthDigits bounds the length prefix of octet-counted frames, so a
+// sender cannot make the server buffer an endless prefix
+const maxLengthDigits = 10
+
+var (
+	messagesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_syslog_messages_total",
+			Help: "Total number of syslog messages received",
+		},
+		[]string{"source", "format"},
+	)
+
+	redactionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "card_validation_syslog_redactions_total",
+			Help: "Total number of PANs masked in syslog messages",
+		},
+		[]string{"source"},
+	)
+
+	forwardErrors = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "card_validation_syslog_forward_errors_total",
+			Help: "Total number of syslog messages that could not be forwarded",
+		},
+	)
+)
+
+// Server receives, redacts and forwards syslog messages
+type Server struct {
+	config    *config.SyslogConfig
+	scanner   *dlp.Scanner
+	forwarder forwarder
+	logger    logging.Logger
+	// sources holds the hostnames used as metric labels
+	sources map[string]bool
+
+	udp net.PacketConn
+	tcp net.Listener
+
+	wg    sync.WaitGroup
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewServer binds the configured listeners and opens the forward target. nil
+// logger logs with a default logrus logger.
+func NewServer(cfg *config.SyslogConfig, scanner *dlp.Scanner, logger logging.Logger) (*Server, error) {
+	if logger == nil {
+		logger = logging.FromLogrus(logrus.New())
+	}
+	if cfg.UDPAddr == "" && cfg.TCPAddr == "" {
+		return nil, errors.New("syslog needs a UDP or TCP listen address")
+	}
+
+	fwd, err := newForwarder(cfg.Forward, cfg.ForwardTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		config:    cfg,
+		scanner:   scanner,
+		forwarder: fwd,
+		logger:    logger,
+		sources:   make(map[string]bool, len(cfg.Sources)),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	for _, source := range cfg.Sources {
+		if source = strings.TrimSpace(source); source != "" {
+			s.sources[source] = true
+		}
+	}
+
+	if cfg.UDPAddr != "" {
+		if s.udp, err = net.ListenPacket("udp", cfg.UDPAddr); err != nil {
+			fwd.Close()
+			return nil, fmt.Errorf("failed to listen on UDP %s: %w", cfg.UDPAddr, err)
+		}
+	}
+	if cfg.TCPAddr != "" {
+		if s.tcp, err = net.Listen("tcp", cfg.TCPAddr); err != nil {
+			fwd.Close()
+			if s.udp != nil {
+				s.udp.Close()
+			}
+			return nil, fmt.Errorf("failed to listen on TCP %s: %w", cfg.TCPAddr, err)
+		}
+	}
+
+	return s, nil
+}
+
+// UDPAddr returns the address of the UDP listener, nil when disabled
+func (s *Server) UDPAddr() net.Addr {
+	if s.udp == nil {
+		return nil
+	}
+	return s.udp.LocalAddr()
+}
+
+// TCPAddr returns the address of the TCP listener, nil when disabled
+func (s *Server) TCPAddr() net.Addr {
+	if s.tcp == nil {
+		return nil
+	}
+	return s.tcp.Addr()
+}
+
+// Serve handles messages until ctx is done, then closes the listeners and
+// the forward target
+func (s *Server) Serve(ctx context.Context) {
+	if s.udp != nil {
+		s.wg.Add(1)
+		go s.serveUDP()
+	}
+	if s.tcp != nil {
+		s.wg.Add(1)
+		go s.serveTCP()
+	}
+
+	<-ctx.Done()
+
+	if s.udp != nil {
+		s.udp.Close()
+	}
+	if s.tcp != nil {
+		s.tcp.Close()
+	}
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	s.forwarder.Close()
+}
+
+// serveUDP handles one message per datagram
+func (s *Server) serveUDP() {
+	defer s.wg.Done()
+
+	buf := make([]byte, s.config.MaxMessageSize)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Error("Syslog UDP read failed", logging.FieldError, err)
+			}
+			return
+		}
+		s.handle(string(buf[:n]), addr)
+	}
+}
+
+// serveTCP accepts connections until the listener is closed
+func (s *Server) serveTCP() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Error("Syslog TCP accept failed", logging.FieldError, err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn reads octet-counted or newline delimited messages (RFC 6587)
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReaderSize(conn, s.config.MaxMessageSize)
+	for {
+		msg, err := s.readFrame(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				s.logger.Warn("Syslog TCP connection closed", "remote", conn.RemoteAddr().String(), logging.FieldError, err)
+			}
+			return
+		}
+		if msg != "" {
+			s.handle(msg, conn.RemoteAddr())
+		}
+	}
+}
+
+// readFrame reads one message. Frames starting with a digit carry their
+// length; other frames end at a newline.
+func (s *Server) readFrame(reader *bufio.Reader) (string, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return "", err
+	}
+
+	if first[0] >= '0' && first[0] <= '9' {
+		length, err := s.readLength(reader)
+		if err != nil {
+			return "", err
+		}
+
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	line, err := reader.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", fmt.Errorf("message exceeds %d bytes", s.config.MaxMessageSize)
+	}
+	if err != nil && (len(line) == 0 || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// readLength reads the length that starts an octet-counted frame: at most
+// maxLengthDigits digits followed by a space
+func (s *Server) readLength(reader *bufio.Reader) (int, error) {
+	var prefix []byte
+	for len(prefix) <= maxLengthDigits {
+		c, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c == ' ' {
+			break
+		}
+		prefix = append(prefix, c)
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid frame length %q", prefix)
+		}
+	}
+	if len(prefix) > maxLengthDigits {
+		return 0, fmt.Errorf("frame length %q exceeds %d digits", prefix, maxLengthDigits)
+	}
+
+	length, err := strconv.Atoi(string(prefix))
+	if err != nil || length <= 0 || length > s.config.MaxMessageSize {
+		return 0, fmt.Errorf("invalid frame length %q", prefix)
+	}
+	return length, nil
+}
+
+// handle masks the PANs of a message and forwards it
+func (s *Server) handle(msg string, remote net.Addr) {
+	msg = strings.TrimRight(msg, "\r\n\x00")
+	hdr := parseHeader(msg)
+
+	source := hdr.hostname
+	if source == "" {
+		source = remoteHost(remote)
+	}
+	// Hostnames are chosen by the sender, so only configured ones become
+	// label values
+	label := otherSource
+	if s.sources[source] {
+		label = source
+	}
+	messagesTotal.WithLabelValues(label, hdr.format).Inc()
+
+	findings := s.scanner.Scan(msg)
+	if len(findings) > 0 {
+		msg = dlp.Redact(msg, findings, dlp.Mask)
+		redactionsTotal.WithLabelValues(label).Add(float64(len(findings)))
+	}
+
+	if err := s.forwarder.forward(msg); err != nil {
+		forwardErrors.Inc()
+		s.logger.Error("Failed to forward syslog message", "source", source, logging.FieldError, err)
+	}
+}
+
+// remoteHost returns the IP of a remote address
+func remoteHost(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,198 @@
+// Package tenant resolves the business unit a request belongs to and holds
+// the isolated validator and rate limiter of every tenant.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultID identifies the tenant used when a request names no tenant
+const DefaultID = "default"
+
+// Package-level errors for better error handling
+var (
+	ErrUnknownTenant  = apperror.New(apperror.CodeUnknownTenant, "unknown tenant")
+	ErrTenantMismatch = apperror.New(apperror.CodeTenantMismatch, "requested tenant does not match credentials")
+)
+
+// Tenant holds the isolated resources of a single tenant
+type Tenant struct {
+	ID        string
+	Config    config.ValidatorConfig
+	Validator *service.Validator
+
+	limiter *rate.Limiter
+}
+
+// Allow reports whether the tenant is within its rate limit
+func (t *Tenant) Allow() bool {
+	return t.AllowN(1)
+}
+
+// AllowN reports whether the tenant is within its rate limit for n more
+// validations, charging all of them or none
+func (t *Tenant) AllowN(n int) bool {
+	return t.limiter == nil || n <= 0 || t.limiter.AllowN(time.Now(), n)
+}
+
+// Wait blocks until the tenant is within its rate limit for one more
+// validation and charges it, or returns the error of ctx
+func (t *Tenant) Wait(ctx context.Context) error {
+	if t.limiter == nil {
+		return nil
+	}
+	return t.limiter.Wait(ctx)
+}
+
+// Registry holds all configured tenants
+type Registry struct {
+	tenants map[string]*Tenant
+	header  string
+}
+
+// NewRegistry creates a tenant for each override plus the default tenant,
+// each with its own validator built from the merged configuration
+func NewRegistry(cfg *config.Config, overrides map[string]config.TenantOverride, logger logging.Logger) (*Registry, error) {
+	r := &Registry{
+		tenants: make(map[string]*Tenant, len(overrides)+1),
+		header:  cfg.Tenants.TenantHeader,
+	}
+
+	if _, ok := overrides[DefaultID]; !ok {
+		if err := r.add(DefaultID, config.TenantOverride{}, cfg, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	for id, override := range overrides {
+		if err := r.add(id, override, cfg, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// add creates the tenant with the given overrides
+func (r *Registry) add(id string, override config.TenantOverride, cfg *config.Config, logger logging.Logger) error {
+	validatorConfig := override.ApplyValidator(cfg.Validator)
+
+	validator, err := service.NewTenantValidator(id, &validatorConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create validator for tenant %q: %w", id, err)
+	}
+
+	t := &Tenant{
+		ID:        id,
+		Config:    validatorConfig,
+		Validator: validator,
+	}
+
+	if limit, burst := override.ApplyRateLimit(cfg.Tenants.RateLimit, cfg.Tenants.RateBurst); limit > 0 {
+		t.limiter = rate.NewLimiter(rate.Limit(limit), burst)
+	}
+
+	r.tenants[id] = t
+	return nil
+}
+
+// Get returns the tenant with the given ID
+func (r *Registry) Get(id string) (*Tenant, bool) {
+	t, ok := r.tenants[id]
+	return t, ok
+}
+
+// Header returns the name of the header clients use to select a tenant
+func (r *Registry) Header() string {
+	return r.header
+}
+
+// Default returns the default tenant
+func (r *Registry) Default() *Tenant {
+	return r.tenants[DefaultID]
+}
+
+// IDs returns the sorted IDs of all tenants
+func (r *Registry) IDs() []string {
+	ids := make([]string, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CheckBINServices checks the BIN lookup service of every tenant, once per
+// distinct service URL
+func (r *Registry) CheckBINServices(ctx context.Context) error {
+	checked := map[string]bool{}
+	var errs []error
+	for _, id := range r.IDs() {
+		t := r.tenants[id]
+		if !t.Config.EnableBINLookup || checked[t.Config.BINServiceURL] {
+			continue
+		}
+		checked[t.Config.BINServiceURL] = true
+
+		if err := t.Validator.CheckBINService(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.Config.BINServiceURL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Resolve determines the tenant of a request. The tenant bound to the
+// authenticated identity takes precedence; a requested tenant, e.g. from a
+// header, is only honored when it matches or the identity is not bound.
+func (r *Registry) Resolve(identity *auth.Identity, requested string) (*Tenant, error) {
+	id := requested
+	if identity != nil && identity.Tenant != "" {
+		if requested != "" && requested != identity.Tenant {
+			return nil, ErrTenantMismatch
+		}
+		id = identity.Tenant
+	}
+
+	if id == "" {
+		return r.Default(), nil
+	}
+
+	t, ok := r.tenants[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, id)
+	}
+	return t, nil
+}
+
+// Validator returns the validator of the tenant in ctx, or of the default tenant
+func (r *Registry) Validator(ctx context.Context) *service.Validator {
+	if t, ok := FromContext(ctx); ok {
+		return t.Validator
+	}
+	return r.Default().Validator
+}
+
+type tenantKey struct{}
+
+// NewContext returns a copy of ctx carrying the tenant
+func NewContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// FromContext returns the tenant stored in ctx, if any
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(*Tenant)
+	return t, ok && t != nil
+}
@@ -7,6 +7,7 @@
 package proto
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -165,6 +166,500 @@ func (x *ValidateCardResponse) GetBank() *Bank {
 	return nil
 }
 
+type ValidateCardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CardNumbers   []string               `protobuf:"bytes,1,rep,name=card_numbers,json=cardNumbers,proto3" json:"card_numbers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardsRequest) Reset() {
+	*x = ValidateCardsRequest{}
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardsRequest) ProtoMessage() {}
+
+func (x *ValidateCardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardsRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCardsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ValidateCardsRequest) GetCardNumbers() []string {
+	if x != nil {
+		return x.CardNumbers
+	}
+	return nil
+}
+
+type ValidateCardsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ValidateCardsItem   `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardsResponse) Reset() {
+	*x = ValidateCardsResponse{}
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardsResponse) ProtoMessage() {}
+
+func (x *ValidateCardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardsResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCardsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ValidateCardsResponse) GetResults() []*ValidateCardsItem {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ValidateCardsItem struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*ValidateCardsItem_Result
+	//	*ValidateCardsItem_Error
+	Outcome       isValidateCardsItem_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardsItem) Reset() {
+	*x = ValidateCardsItem{}
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardsItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardsItem) ProtoMessage() {}
+
+func (x *ValidateCardsItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardsItem.ProtoReflect.Descriptor instead.
+func (*ValidateCardsItem) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ValidateCardsItem) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ValidateCardsItem) GetOutcome() isValidateCardsItem_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *ValidateCardsItem) GetResult() *ValidateCardResponse {
+	if x != nil {
+		if x, ok := x.Outcome.(*ValidateCardsItem_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *ValidateCardsItem) GetError() string {
+	if x != nil {
+		if x, ok := x.Outcome.(*ValidateCardsItem_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+type isValidateCardsItem_Outcome interface {
+	isValidateCardsItem_Outcome()
+}
+
+type ValidateCardsItem_Result struct {
+	Result *ValidateCardResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+type ValidateCardsItem_Error struct {
+	Error string `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*ValidateCardsItem_Result) isValidateCardsItem_Outcome() {}
+
+func (*ValidateCardsItem_Error) isValidateCardsItem_Outcome() {}
+
+type ValidateCardStreamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	CardNumber    string                 `protobuf:"bytes,2,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardStreamRequest) Reset() {
+	*x = ValidateCardStreamRequest{}
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardStreamRequest) ProtoMessage() {}
+
+func (x *ValidateCardStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardStreamRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCardStreamRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ValidateCardStreamRequest) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *ValidateCardStreamRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+type ValidateCardStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*ValidateCardStreamResponse_Result
+	//	*ValidateCardStreamResponse_Error
+	Outcome       isValidateCardStreamResponse_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardStreamResponse) Reset() {
+	*x = ValidateCardStreamResponse{}
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardStreamResponse) ProtoMessage() {}
+
+func (x *ValidateCardStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardStreamResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCardStreamResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ValidateCardStreamResponse) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *ValidateCardStreamResponse) GetOutcome() isValidateCardStreamResponse_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *ValidateCardStreamResponse) GetResult() *ValidateCardResponse {
+	if x != nil {
+		if x, ok := x.Outcome.(*ValidateCardStreamResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *ValidateCardStreamResponse) GetError() string {
+	if x != nil {
+		if x, ok := x.Outcome.(*ValidateCardStreamResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+type isValidateCardStreamResponse_Outcome interface {
+	isValidateCardStreamResponse_Outcome()
+}
+
+type ValidateCardStreamResponse_Result struct {
+	Result *ValidateCardResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+type ValidateCardStreamResponse_Error struct {
+	Error string `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*ValidateCardStreamResponse_Result) isValidateCardStreamResponse_Outcome() {}
+
+func (*ValidateCardStreamResponse_Error) isValidateCardStreamResponse_Outcome() {}
+
+type ScanTextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanTextRequest) Reset() {
+	*x = ScanTextRequest{}
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanTextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanTextRequest) ProtoMessage() {}
+
+func (x *ScanTextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanTextRequest.ProtoReflect.Descriptor instead.
+func (*ScanTextRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ScanTextRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type ScanTextResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Findings      []*PanFinding          `protobuf:"bytes,1,rep,name=findings,proto3" json:"findings,omitempty"`
+	RedactedText  string                 `protobuf:"bytes,2,opt,name=redacted_text,json=redactedText,proto3" json:"redacted_text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanTextResponse) Reset() {
+	*x = ScanTextResponse{}
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanTextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanTextResponse) ProtoMessage() {}
+
+func (x *ScanTextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanTextResponse.ProtoReflect.Descriptor instead.
+func (*ScanTextResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ScanTextResponse) GetFindings() []*PanFinding {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+func (x *ScanTextResponse) GetRedactedText() string {
+	if x != nil {
+		return x.RedactedText
+	}
+	return ""
+}
+
+// PanFinding is a card number found in text. start and end are byte offsets
+// into the UTF-8 encoded text.
+type PanFinding struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	MaskedPan     string                 `protobuf:"bytes,3,opt,name=masked_pan,json=maskedPan,proto3" json:"masked_pan,omitempty"`
+	CardType      string                 `protobuf:"bytes,4,opt,name=card_type,json=cardType,proto3" json:"card_type,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,5,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PanFinding) Reset() {
+	*x = PanFinding{}
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PanFinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PanFinding) ProtoMessage() {}
+
+func (x *PanFinding) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PanFinding.ProtoReflect.Descriptor instead.
+func (*PanFinding) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PanFinding) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *PanFinding) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *PanFinding) GetMaskedPan() string {
+	if x != nil {
+		return x.MaskedPan
+	}
+	return ""
+}
+
+func (x *PanFinding) GetCardType() string {
+	if x != nil {
+		return x.CardType
+	}
+	return ""
+}
+
+func (x *PanFinding) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
 type Country struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -179,7 +674,7 @@ type Country struct {
 
 func (x *Country) Reset() {
 	*x = Country{}
-	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[2]
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -191,7 +686,7 @@ func (x *Country) String() string {
 func (*Country) ProtoMessage() {}
 
 func (x *Country) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[2]
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -204,7 +699,7 @@ func (x *Country) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Country.ProtoReflect.Descriptor instead.
 func (*Country) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{2}
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Country) GetName() string {
@@ -260,7 +755,7 @@ type Bank struct {
 
 func (x *Bank) Reset() {
 	*x = Bank{}
-	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[3]
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -272,7 +767,7 @@ func (x *Bank) String() string {
 func (*Bank) ProtoMessage() {}
 
 func (x *Bank) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[3]
+	mi := &file_pkg_proto_cardvalidator_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -285,7 +780,7 @@ func (x *Bank) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Bank.ProtoReflect.Descriptor instead.
 func (*Bank) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{3}
+	return file_pkg_proto_cardvalidator_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Bank) GetName() string {
@@ -313,9 +808,9 @@ var File_pkg_proto_cardvalidator_proto protoreflect.FileDescriptor
 
 const file_pkg_proto_cardvalidator_proto_rawDesc = "" +
 	"\n" +
-	"\x1dpkg/proto/cardvalidator.proto\x12\rcardvalidator\"6\n" +
-	"\x13ValidateCardRequest\x12\x1f\n" +
-	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"\x1dpkg/proto/cardvalidator.proto\x12\rcardvalidator\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/api/field_behavior.proto\";\n" +
+	"\x13ValidateCardRequest\x12$\n" +
+	"\vcard_number\x18\x01 \x01(\tB\x03\xe0A\x02R\n" +
 	"cardNumber\"\x99\x02\n" +
 	"\x14ValidateCardResponse\x12\x14\n" +
 	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x1b\n" +
@@ -327,7 +822,40 @@ const file_pkg_proto_cardvalidator_proto_rawDesc = "" +
 	"card_brand\x18\x05 \x01(\tR\tcardBrand\x12\x1b\n" +
 	"\tcard_kind\x18\x06 \x01(\tR\bcardKind\x120\n" +
 	"\acountry\x18\a \x01(\v2\x16.cardvalidator.CountryR\acountry\x12'\n" +
-	"\x04bank\x18\b \x01(\v2\x13.cardvalidator.BankR\x04bank\"\xa1\x01\n" +
+	"\x04bank\x18\b \x01(\v2\x13.cardvalidator.BankR\x04bank\">\n" +
+	"\x14ValidateCardsRequest\x12&\n" +
+	"\fcard_numbers\x18\x01 \x03(\tB\x03\xe0A\x02R\vcardNumbers\"S\n" +
+	"\x15ValidateCardsResponse\x12:\n" +
+	"\aresults\x18\x01 \x03(\v2 .cardvalidator.ValidateCardsItemR\aresults\"\x8b\x01\n" +
+	"\x11ValidateCardsItem\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12=\n" +
+	"\x06result\x18\x02 \x01(\v2#.cardvalidator.ValidateCardResponseH\x00R\x06result\x12\x16\n" +
+	"\x05error\x18\x03 \x01(\tH\x00R\x05errorB\t\n" +
+	"\aoutcome\"h\n" +
+	"\x19ValidateCardStreamRequest\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12$\n" +
+	"\vcard_number\x18\x02 \x01(\tB\x03\xe0A\x02R\n" +
+	"cardNumber\"\xa5\x01\n" +
+	"\x1aValidateCardStreamResponse\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12=\n" +
+	"\x06result\x18\x02 \x01(\v2#.cardvalidator.ValidateCardResponseH\x00R\x06result\x12\x16\n" +
+	"\x05error\x18\x03 \x01(\tH\x00R\x05errorB\t\n" +
+	"\aoutcome\"%\n" +
+	"\x0fScanTextRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"n\n" +
+	"\x10ScanTextResponse\x125\n" +
+	"\bfindings\x18\x01 \x03(\v2\x19.cardvalidator.PanFindingR\bfindings\x12#\n" +
+	"\rredacted_text\x18\x02 \x01(\tR\fredactedText\"\x90\x01\n" +
+	"\n" +
+	"PanFinding\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\x12\x1d\n" +
+	"\n" +
+	"masked_pan\x18\x03 \x01(\tR\tmaskedPan\x12\x1b\n" +
+	"\tcard_type\x18\x04 \x01(\tR\bcardType\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x05 \x01(\x01R\n" +
+	"confidence\"\xa1\x01\n" +
 	"\aCountry\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
 	"\x06alpha2\x18\x02 \x01(\tR\x06alpha2\x12\x1a\n" +
@@ -338,9 +866,12 @@ const file_pkg_proto_cardvalidator_proto_rawDesc = "" +
 	"\x04Bank\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
 	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
-	"\x05phone\x18\x03 \x01(\tR\x05phone2h\n" +
-	"\rCardValidator\x12W\n" +
-	"\fValidateCard\x12\".cardvalidator.ValidateCardRequest\x1a#.cardvalidator.ValidateCardResponseB!Z\x1fcredit-card-validator/pkg/protob\x06proto3"
+	"\x05phone\x18\x03 \x01(\tR\x05phone2\x82\x04\n" +
+	"\rCardValidator\x12t\n" +
+	"\fValidateCard\x12\".cardvalidator.ValidateCardRequest\x1a#.cardvalidator.ValidateCardResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/api/v1/validate\x12}\n" +
+	"\rValidateCards\x12#.cardvalidator.ValidateCardsRequest\x1a$.cardvalidator.ValidateCardsResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/api/v1/validate/batch\x12\x91\x01\n" +
+	"\x12ValidateCardStream\x12(.cardvalidator.ValidateCardStreamRequest\x1a).cardvalidator.ValidateCardStreamResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/api/v1/validate/stream(\x010\x01\x12h\n" +
+	"\bScanText\x12\x1e.cardvalidator.ScanTextRequest\x1a\x1f.cardvalidator.ScanTextResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/api/v1/dlp/scanB!Z\x1fcredit-card-validator/pkg/protob\x06proto3"
 
 var (
 	file_pkg_proto_cardvalidator_proto_rawDescOnce sync.Once
@@ -354,23 +885,41 @@ func file_pkg_proto_cardvalidator_proto_rawDescGZIP() []byte {
 	return file_pkg_proto_cardvalidator_proto_rawDescData
 }
 
-var file_pkg_proto_cardvalidator_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_pkg_proto_cardvalidator_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_pkg_proto_cardvalidator_proto_goTypes = []any{
-	(*ValidateCardRequest)(nil),  // 0: cardvalidator.ValidateCardRequest
-	(*ValidateCardResponse)(nil), // 1: cardvalidator.ValidateCardResponse
-	(*Country)(nil),              // 2: cardvalidator.Country
-	(*Bank)(nil),                 // 3: cardvalidator.Bank
+	(*ValidateCardRequest)(nil),        // 0: cardvalidator.ValidateCardRequest
+	(*ValidateCardResponse)(nil),       // 1: cardvalidator.ValidateCardResponse
+	(*ValidateCardsRequest)(nil),       // 2: cardvalidator.ValidateCardsRequest
+	(*ValidateCardsResponse)(nil),      // 3: cardvalidator.ValidateCardsResponse
+	(*ValidateCardsItem)(nil),          // 4: cardvalidator.ValidateCardsItem
+	(*ValidateCardStreamRequest)(nil),  // 5: cardvalidator.ValidateCardStreamRequest
+	(*ValidateCardStreamResponse)(nil), // 6: cardvalidator.ValidateCardStreamResponse
+	(*ScanTextRequest)(nil),            // 7: cardvalidator.ScanTextRequest
+	(*ScanTextResponse)(nil),           // 8: cardvalidator.ScanTextResponse
+	(*PanFinding)(nil),                 // 9: cardvalidator.PanFinding
+	(*Country)(nil),                    // 10: cardvalidator.Country
+	(*Bank)(nil),                       // 11: cardvalidator.Bank
 }
 var file_pkg_proto_cardvalidator_proto_depIdxs = []int32{
-	2, // 0: cardvalidator.ValidateCardResponse.country:type_name -> cardvalidator.Country
-	3, // 1: cardvalidator.ValidateCardResponse.bank:type_name -> cardvalidator.Bank
-	0, // 2: cardvalidator.CardValidator.ValidateCard:input_type -> cardvalidator.ValidateCardRequest
-	1, // 3: cardvalidator.CardValidator.ValidateCard:output_type -> cardvalidator.ValidateCardResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	10, // 0: cardvalidator.ValidateCardResponse.country:type_name -> cardvalidator.Country
+	11, // 1: cardvalidator.ValidateCardResponse.bank:type_name -> cardvalidator.Bank
+	4,  // 2: cardvalidator.ValidateCardsResponse.results:type_name -> cardvalidator.ValidateCardsItem
+	1,  // 3: cardvalidator.ValidateCardsItem.result:type_name -> cardvalidator.ValidateCardResponse
+	1,  // 4: cardvalidator.ValidateCardStreamResponse.result:type_name -> cardvalidator.ValidateCardResponse
+	9,  // 5: cardvalidator.ScanTextResponse.findings:type_name -> cardvalidator.PanFinding
+	0,  // 6: cardvalidator.CardValidator.ValidateCard:input_type -> cardvalidator.ValidateCardRequest
+	2,  // 7: cardvalidator.CardValidator.ValidateCards:input_type -> cardvalidator.ValidateCardsRequest
+	5,  // 8: cardvalidator.CardValidator.ValidateCardStream:input_type -> cardvalidator.ValidateCardStreamRequest
+	7,  // 9: cardvalidator.CardValidator.ScanText:input_type -> cardvalidator.ScanTextRequest
+	1,  // 10: cardvalidator.CardValidator.ValidateCard:output_type -> cardvalidator.ValidateCardResponse
+	3,  // 11: cardvalidator.CardValidator.ValidateCards:output_type -> cardvalidator.ValidateCardsResponse
+	6,  // 12: cardvalidator.CardValidator.ValidateCardStream:output_type -> cardvalidator.ValidateCardStreamResponse
+	8,  // 13: cardvalidator.CardValidator.ScanText:output_type -> cardvalidator.ScanTextResponse
+	10, // [10:14] is the sub-list for method output_type
+	6,  // [6:10] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_cardvalidator_proto_init() }
@@ -378,13 +927,21 @@ func file_pkg_proto_cardvalidator_proto_init() {
 	if File_pkg_proto_cardvalidator_proto != nil {
 		return
 	}
+	file_pkg_proto_cardvalidator_proto_msgTypes[4].OneofWrappers = []any{
+		(*ValidateCardsItem_Result)(nil),
+		(*ValidateCardsItem_Error)(nil),
+	}
+	file_pkg_proto_cardvalidator_proto_msgTypes[6].OneofWrappers = []any{
+		(*ValidateCardStreamResponse_Result)(nil),
+		(*ValidateCardStreamResponse_Error)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_proto_cardvalidator_proto_rawDesc), len(file_pkg_proto_cardvalidator_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
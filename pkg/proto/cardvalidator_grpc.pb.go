@@ -19,7 +19,10 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CardValidator_ValidateCard_FullMethodName = "/cardvalidator.CardValidator/ValidateCard"
+	CardValidator_ValidateCard_FullMethodName       = "/cardvalidator.CardValidator/ValidateCard"
+	CardValidator_ValidateCards_FullMethodName      = "/cardvalidator.CardValidator/ValidateCards"
+	CardValidator_ValidateCardStream_FullMethodName = "/cardvalidator.CardValidator/ValidateCardStream"
+	CardValidator_ScanText_FullMethodName           = "/cardvalidator.CardValidator/ScanText"
 )
 
 // CardValidatorClient is the client API for CardValidator service.
@@ -27,6 +30,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CardValidatorClient interface {
 	ValidateCard(ctx context.Context, in *ValidateCardRequest, opts ...grpc.CallOption) (*ValidateCardResponse, error)
+	ValidateCards(ctx context.Context, in *ValidateCardsRequest, opts ...grpc.CallOption) (*ValidateCardsResponse, error)
+	ValidateCardStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ValidateCardStreamRequest, ValidateCardStreamResponse], error)
+	ScanText(ctx context.Context, in *ScanTextRequest, opts ...grpc.CallOption) (*ScanTextResponse, error)
 }
 
 type cardValidatorClient struct {
@@ -47,11 +53,47 @@ func (c *cardValidatorClient) ValidateCard(ctx context.Context, in *ValidateCard
 	return out, nil
 }
 
+func (c *cardValidatorClient) ValidateCards(ctx context.Context, in *ValidateCardsRequest, opts ...grpc.CallOption) (*ValidateCardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateCardsResponse)
+	err := c.cc.Invoke(ctx, CardValidator_ValidateCards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cardValidatorClient) ValidateCardStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ValidateCardStreamRequest, ValidateCardStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CardValidator_ServiceDesc.Streams[0], CardValidator_ValidateCardStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ValidateCardStreamRequest, ValidateCardStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CardValidator_ValidateCardStreamClient = grpc.BidiStreamingClient[ValidateCardStreamRequest, ValidateCardStreamResponse]
+
+func (c *cardValidatorClient) ScanText(ctx context.Context, in *ScanTextRequest, opts ...grpc.CallOption) (*ScanTextResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScanTextResponse)
+	err := c.cc.Invoke(ctx, CardValidator_ScanText_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CardValidatorServer is the server API for CardValidator service.
 // All implementations must embed UnimplementedCardValidatorServer
 // for forward compatibility.
 type CardValidatorServer interface {
 	ValidateCard(context.Context, *ValidateCardRequest) (*ValidateCardResponse, error)
+	ValidateCards(context.Context, *ValidateCardsRequest) (*ValidateCardsResponse, error)
+	ValidateCardStream(grpc.BidiStreamingServer[ValidateCardStreamRequest, ValidateCardStreamResponse]) error
+	ScanText(context.Context, *ScanTextRequest) (*ScanTextResponse, error)
 	mustEmbedUnimplementedCardValidatorServer()
 }
 
@@ -65,6 +107,15 @@ type UnimplementedCardValidatorServer struct{}
 func (UnimplementedCardValidatorServer) ValidateCard(context.Context, *ValidateCardRequest) (*ValidateCardResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidateCard not implemented")
 }
+func (UnimplementedCardValidatorServer) ValidateCards(context.Context, *ValidateCardsRequest) (*ValidateCardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateCards not implemented")
+}
+func (UnimplementedCardValidatorServer) ValidateCardStream(grpc.BidiStreamingServer[ValidateCardStreamRequest, ValidateCardStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ValidateCardStream not implemented")
+}
+func (UnimplementedCardValidatorServer) ScanText(context.Context, *ScanTextRequest) (*ScanTextResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScanText not implemented")
+}
 func (UnimplementedCardValidatorServer) mustEmbedUnimplementedCardValidatorServer() {}
 func (UnimplementedCardValidatorServer) testEmbeddedByValue()                       {}
 
@@ -104,6 +155,49 @@ func _CardValidator_ValidateCard_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CardValidator_ValidateCards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateCardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CardValidatorServer).ValidateCards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CardValidator_ValidateCards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CardValidatorServer).ValidateCards(ctx, req.(*ValidateCardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CardValidator_ValidateCardStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CardValidatorServer).ValidateCardStream(&grpc.GenericServerStream[ValidateCardStreamRequest, ValidateCardStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CardValidator_ValidateCardStreamServer = grpc.BidiStreamingServer[ValidateCardStreamRequest, ValidateCardStreamResponse]
+
+func _CardValidator_ScanText_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanTextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CardValidatorServer).ScanText(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CardValidator_ScanText_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CardValidatorServer).ScanText(ctx, req.(*ScanTextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CardValidator_ServiceDesc is the grpc.ServiceDesc for CardValidator service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +209,22 @@ var CardValidator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ValidateCard",
 			Handler:    _CardValidator_ValidateCard_Handler,
 		},
+		{
+			MethodName: "ValidateCards",
+			Handler:    _CardValidator_ValidateCards_Handler,
+		},
+		{
+			MethodName: "ScanText",
+			Handler:    _CardValidator_ScanText_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ValidateCardStream",
+			Handler:       _CardValidator_ValidateCardStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/proto/cardvalidator.proto",
 }
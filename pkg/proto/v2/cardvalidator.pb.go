@@ -0,0 +1,1131 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.31.1
+// source: pkg/proto/v2/cardvalidator.proto
+
+package cardvalidatorv2
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// IssueCode identifies a reason why a card failed validation
+type IssueCode int32
+
+const (
+	IssueCode_ISSUE_CODE_UNSPECIFIED            IssueCode = 0
+	IssueCode_ISSUE_CODE_LUHN_CHECK_FAILED      IssueCode = 1
+	IssueCode_ISSUE_CODE_CARD_TYPE_NOT_ACCEPTED IssueCode = 2
+)
+
+// Enum value maps for IssueCode.
+var (
+	IssueCode_name = map[int32]string{
+		0: "ISSUE_CODE_UNSPECIFIED",
+		1: "ISSUE_CODE_LUHN_CHECK_FAILED",
+		2: "ISSUE_CODE_CARD_TYPE_NOT_ACCEPTED",
+	}
+	IssueCode_value = map[string]int32{
+		"ISSUE_CODE_UNSPECIFIED":            0,
+		"ISSUE_CODE_LUHN_CHECK_FAILED":      1,
+		"ISSUE_CODE_CARD_TYPE_NOT_ACCEPTED": 2,
+	}
+)
+
+func (x IssueCode) Enum() *IssueCode {
+	p := new(IssueCode)
+	*p = x
+	return p
+}
+
+func (x IssueCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IssueCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_v2_cardvalidator_proto_enumTypes[0].Descriptor()
+}
+
+func (IssueCode) Type() protoreflect.EnumType {
+	return &file_pkg_proto_v2_cardvalidator_proto_enumTypes[0]
+}
+
+func (x IssueCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IssueCode.Descriptor instead.
+func (IssueCode) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{0}
+}
+
+// EnrichmentStatus reports whether the result carries BIN data
+type EnrichmentStatus int32
+
+const (
+	EnrichmentStatus_ENRICHMENT_STATUS_UNSPECIFIED EnrichmentStatus = 0
+	// The caller may not read BIN data
+	EnrichmentStatus_ENRICHMENT_STATUS_NOT_REQUESTED EnrichmentStatus = 1
+	// BIN lookup is disabled for the tenant
+	EnrichmentStatus_ENRICHMENT_STATUS_DISABLED EnrichmentStatus = 2
+	// The card is invalid, so no lookup was made
+	EnrichmentStatus_ENRICHMENT_STATUS_SKIPPED  EnrichmentStatus = 3
+	EnrichmentStatus_ENRICHMENT_STATUS_ENRICHED EnrichmentStatus = 4
+	EnrichmentStatus_ENRICHMENT_STATUS_FAILED   EnrichmentStatus = 5
+)
+
+// Enum value maps for EnrichmentStatus.
+var (
+	EnrichmentStatus_name = map[int32]string{
+		0: "ENRICHMENT_STATUS_UNSPECIFIED",
+		1: "ENRICHMENT_STATUS_NOT_REQUESTED",
+		2: "ENRICHMENT_STATUS_DISABLED",
+		3: "ENRICHMENT_STATUS_SKIPPED",
+		4: "ENRICHMENT_STATUS_ENRICHED",
+		5: "ENRICHMENT_STATUS_FAILED",
+	}
+	EnrichmentStatus_value = map[string]int32{
+		"ENRICHMENT_STATUS_UNSPECIFIED":   0,
+		"ENRICHMENT_STATUS_NOT_REQUESTED": 1,
+		"ENRICHMENT_STATUS_DISABLED":      2,
+		"ENRICHMENT_STATUS_SKIPPED":       3,
+		"ENRICHMENT_STATUS_ENRICHED":      4,
+		"ENRICHMENT_STATUS_FAILED":        5,
+	}
+)
+
+func (x EnrichmentStatus) Enum() *EnrichmentStatus {
+	p := new(EnrichmentStatus)
+	*p = x
+	return p
+}
+
+func (x EnrichmentStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EnrichmentStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_v2_cardvalidator_proto_enumTypes[1].Descriptor()
+}
+
+func (EnrichmentStatus) Type() protoreflect.EnumType {
+	return &file_pkg_proto_v2_cardvalidator_proto_enumTypes[1]
+}
+
+func (x EnrichmentStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EnrichmentStatus.Descriptor instead.
+func (EnrichmentStatus) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{1}
+}
+
+type ValidateCardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CardNumber    string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardRequest) Reset() {
+	*x = ValidateCardRequest{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardRequest) ProtoMessage() {}
+
+func (x *ValidateCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCardRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ValidateCardRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+type ValidateCardResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Valid            bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	CardType         string                 `protobuf:"bytes,2,opt,name=card_type,json=cardType,proto3" json:"card_type,omitempty"`
+	CardNumber       string                 `protobuf:"bytes,3,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	Scheme           string                 `protobuf:"bytes,4,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	CardBrand        string                 `protobuf:"bytes,5,opt,name=card_brand,json=cardBrand,proto3" json:"card_brand,omitempty"`
+	CardKind         string                 `protobuf:"bytes,6,opt,name=card_kind,json=cardKind,proto3" json:"card_kind,omitempty"`
+	Country          *Country               `protobuf:"bytes,7,opt,name=country,proto3" json:"country,omitempty"`
+	Bank             *Bank                  `protobuf:"bytes,8,opt,name=bank,proto3" json:"bank,omitempty"`
+	Bin              string                 `protobuf:"bytes,9,opt,name=bin,proto3" json:"bin,omitempty"`
+	LastFour         string                 `protobuf:"bytes,10,opt,name=last_four,json=lastFour,proto3" json:"last_four,omitempty"`
+	Issues           []IssueCode            `protobuf:"varint,11,rep,packed,name=issues,proto3,enum=cardvalidator.v2.IssueCode" json:"issues,omitempty"`
+	EnrichmentStatus EnrichmentStatus       `protobuf:"varint,12,opt,name=enrichment_status,json=enrichmentStatus,proto3,enum=cardvalidator.v2.EnrichmentStatus" json:"enrichment_status,omitempty"`
+	// enrichment_source names the provider of the BIN data, if any
+	EnrichmentSource string `protobuf:"bytes,13,opt,name=enrichment_source,json=enrichmentSource,proto3" json:"enrichment_source,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ValidateCardResponse) Reset() {
+	*x = ValidateCardResponse{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardResponse) ProtoMessage() {}
+
+func (x *ValidateCardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCardResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ValidateCardResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateCardResponse) GetCardType() string {
+	if x != nil {
+		return x.CardType
+	}
+	return ""
+}
+
+func (x *ValidateCardResponse) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *ValidateCardResponse) GetScheme() string {
+	if x != nil {
+		return x.Scheme
+	}
+	return ""
+}
+
+func (x *ValidateCardResponse) GetCardBrand() string {
+	if x != nil {
+		return x.CardBrand
+	}
+	return ""
+}
+
+func (x *ValidateCardResponse) GetCardKind() string {
+	if x != nil {
+		return x.CardKind
+	}
+	return ""
+}
+
+func (x *ValidateCardResponse) GetCountry() *Country {
+	if x != nil {
+		return x.Country
+	}
+	return nil
+}
+
+func (x *ValidateCardResponse) GetBank() *Bank {
+	if x != nil {
+		return x.Bank
+	}
+	return nil
+}
+
+func (x *ValidateCardResponse) GetBin() string {
+	if x != nil {
+		return x.Bin
+	}
+	return ""
+}
+
+func (x *ValidateCardResponse) GetLastFour() string {
+	if x != nil {
+		return x.LastFour
+	}
+	return ""
+}
+
+func (x *ValidateCardResponse) GetIssues() []IssueCode {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+func (x *ValidateCardResponse) GetEnrichmentStatus() EnrichmentStatus {
+	if x != nil {
+		return x.EnrichmentStatus
+	}
+	return EnrichmentStatus_ENRICHMENT_STATUS_UNSPECIFIED
+}
+
+func (x *ValidateCardResponse) GetEnrichmentSource() string {
+	if x != nil {
+		return x.EnrichmentSource
+	}
+	return ""
+}
+
+type ValidateCardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CardNumbers   []string               `protobuf:"bytes,1,rep,name=card_numbers,json=cardNumbers,proto3" json:"card_numbers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardsRequest) Reset() {
+	*x = ValidateCardsRequest{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardsRequest) ProtoMessage() {}
+
+func (x *ValidateCardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardsRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCardsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ValidateCardsRequest) GetCardNumbers() []string {
+	if x != nil {
+		return x.CardNumbers
+	}
+	return nil
+}
+
+type ValidateCardsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ValidateCardsItem   `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardsResponse) Reset() {
+	*x = ValidateCardsResponse{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardsResponse) ProtoMessage() {}
+
+func (x *ValidateCardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardsResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCardsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ValidateCardsResponse) GetResults() []*ValidateCardsItem {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ValidateCardsItem struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*ValidateCardsItem_Result
+	//	*ValidateCardsItem_Error
+	Outcome       isValidateCardsItem_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardsItem) Reset() {
+	*x = ValidateCardsItem{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardsItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardsItem) ProtoMessage() {}
+
+func (x *ValidateCardsItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardsItem.ProtoReflect.Descriptor instead.
+func (*ValidateCardsItem) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ValidateCardsItem) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ValidateCardsItem) GetOutcome() isValidateCardsItem_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *ValidateCardsItem) GetResult() *ValidateCardResponse {
+	if x != nil {
+		if x, ok := x.Outcome.(*ValidateCardsItem_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *ValidateCardsItem) GetError() string {
+	if x != nil {
+		if x, ok := x.Outcome.(*ValidateCardsItem_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+type isValidateCardsItem_Outcome interface {
+	isValidateCardsItem_Outcome()
+}
+
+type ValidateCardsItem_Result struct {
+	Result *ValidateCardResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+type ValidateCardsItem_Error struct {
+	Error string `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*ValidateCardsItem_Result) isValidateCardsItem_Outcome() {}
+
+func (*ValidateCardsItem_Error) isValidateCardsItem_Outcome() {}
+
+type ValidateCardStreamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	CardNumber    string                 `protobuf:"bytes,2,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardStreamRequest) Reset() {
+	*x = ValidateCardStreamRequest{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardStreamRequest) ProtoMessage() {}
+
+func (x *ValidateCardStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardStreamRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCardStreamRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ValidateCardStreamRequest) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *ValidateCardStreamRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+type ValidateCardStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*ValidateCardStreamResponse_Result
+	//	*ValidateCardStreamResponse_Error
+	Outcome       isValidateCardStreamResponse_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCardStreamResponse) Reset() {
+	*x = ValidateCardStreamResponse{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCardStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCardStreamResponse) ProtoMessage() {}
+
+func (x *ValidateCardStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCardStreamResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCardStreamResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ValidateCardStreamResponse) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *ValidateCardStreamResponse) GetOutcome() isValidateCardStreamResponse_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *ValidateCardStreamResponse) GetResult() *ValidateCardResponse {
+	if x != nil {
+		if x, ok := x.Outcome.(*ValidateCardStreamResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *ValidateCardStreamResponse) GetError() string {
+	if x != nil {
+		if x, ok := x.Outcome.(*ValidateCardStreamResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+type isValidateCardStreamResponse_Outcome interface {
+	isValidateCardStreamResponse_Outcome()
+}
+
+type ValidateCardStreamResponse_Result struct {
+	Result *ValidateCardResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+type ValidateCardStreamResponse_Error struct {
+	Error string `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*ValidateCardStreamResponse_Result) isValidateCardStreamResponse_Outcome() {}
+
+func (*ValidateCardStreamResponse_Error) isValidateCardStreamResponse_Outcome() {}
+
+type ScanTextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanTextRequest) Reset() {
+	*x = ScanTextRequest{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanTextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanTextRequest) ProtoMessage() {}
+
+func (x *ScanTextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanTextRequest.ProtoReflect.Descriptor instead.
+func (*ScanTextRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ScanTextRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type ScanTextResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Findings      []*PanFinding          `protobuf:"bytes,1,rep,name=findings,proto3" json:"findings,omitempty"`
+	RedactedText  string                 `protobuf:"bytes,2,opt,name=redacted_text,json=redactedText,proto3" json:"redacted_text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanTextResponse) Reset() {
+	*x = ScanTextResponse{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanTextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanTextResponse) ProtoMessage() {}
+
+func (x *ScanTextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanTextResponse.ProtoReflect.Descriptor instead.
+func (*ScanTextResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ScanTextResponse) GetFindings() []*PanFinding {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+func (x *ScanTextResponse) GetRedactedText() string {
+	if x != nil {
+		return x.RedactedText
+	}
+	return ""
+}
+
+// PanFinding is a card number found in text. start and end are byte offsets
+// into the UTF-8 encoded text.
+type PanFinding struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	MaskedPan     string                 `protobuf:"bytes,3,opt,name=masked_pan,json=maskedPan,proto3" json:"masked_pan,omitempty"`
+	CardType      string                 `protobuf:"bytes,4,opt,name=card_type,json=cardType,proto3" json:"card_type,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,5,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PanFinding) Reset() {
+	*x = PanFinding{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PanFinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PanFinding) ProtoMessage() {}
+
+func (x *PanFinding) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PanFinding.ProtoReflect.Descriptor instead.
+func (*PanFinding) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PanFinding) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *PanFinding) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *PanFinding) GetMaskedPan() string {
+	if x != nil {
+		return x.MaskedPan
+	}
+	return ""
+}
+
+func (x *PanFinding) GetCardType() string {
+	if x != nil {
+		return x.CardType
+	}
+	return ""
+}
+
+func (x *PanFinding) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+type Country struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Alpha2        string                 `protobuf:"bytes,2,opt,name=alpha2,proto3" json:"alpha2,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	Emoji         string                 `protobuf:"bytes,4,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Latitude      float64                `protobuf:"fixed64,5,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,6,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Country) Reset() {
+	*x = Country{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Country) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Country) ProtoMessage() {}
+
+func (x *Country) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Country.ProtoReflect.Descriptor instead.
+func (*Country) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Country) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Country) GetAlpha2() string {
+	if x != nil {
+		return x.Alpha2
+	}
+	return ""
+}
+
+func (x *Country) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Country) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *Country) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Country) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+type Bank struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Phone         string                 `protobuf:"bytes,3,opt,name=phone,proto3" json:"phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bank) Reset() {
+	*x = Bank{}
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bank) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bank) ProtoMessage() {}
+
+func (x *Bank) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_v2_cardvalidator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bank.ProtoReflect.Descriptor instead.
+func (*Bank) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Bank) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Bank) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Bank) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+var File_pkg_proto_v2_cardvalidator_proto protoreflect.FileDescriptor
+
+const file_pkg_proto_v2_cardvalidator_proto_rawDesc = "" +
+	"\n" +
+	" pkg/proto/v2/cardvalidator.proto\x12\x10cardvalidator.v2\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/api/field_behavior.proto\";\n" +
+	"\x13ValidateCardRequest\x12$\n" +
+	"\vcard_number\x18\x01 \x01(\tB\x03\xe0A\x02R\n" +
+	"cardNumber\"\x81\x04\n" +
+	"\x14ValidateCardResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x1b\n" +
+	"\tcard_type\x18\x02 \x01(\tR\bcardType\x12\x1f\n" +
+	"\vcard_number\x18\x03 \x01(\tR\n" +
+	"cardNumber\x12\x16\n" +
+	"\x06scheme\x18\x04 \x01(\tR\x06scheme\x12\x1d\n" +
+	"\n" +
+	"card_brand\x18\x05 \x01(\tR\tcardBrand\x12\x1b\n" +
+	"\tcard_kind\x18\x06 \x01(\tR\bcardKind\x123\n" +
+	"\acountry\x18\a \x01(\v2\x19.cardvalidator.v2.CountryR\acountry\x12*\n" +
+	"\x04bank\x18\b \x01(\v2\x16.cardvalidator.v2.BankR\x04bank\x12\x10\n" +
+	"\x03bin\x18\t \x01(\tR\x03bin\x12\x1b\n" +
+	"\tlast_four\x18\n" +
+	" \x01(\tR\blastFour\x123\n" +
+	"\x06issues\x18\v \x03(\x0e2\x1b.cardvalidator.v2.IssueCodeR\x06issues\x12O\n" +
+	"\x11enrichment_status\x18\f \x01(\x0e2\".cardvalidator.v2.EnrichmentStatusR\x10enrichmentStatus\x12+\n" +
+	"\x11enrichment_source\x18\r \x01(\tR\x10enrichmentSource\">\n" +
+	"\x14ValidateCardsRequest\x12&\n" +
+	"\fcard_numbers\x18\x01 \x03(\tB\x03\xe0A\x02R\vcardNumbers\"V\n" +
+	"\x15ValidateCardsResponse\x12=\n" +
+	"\aresults\x18\x01 \x03(\v2#.cardvalidator.v2.ValidateCardsItemR\aresults\"\x8e\x01\n" +
+	"\x11ValidateCardsItem\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12@\n" +
+	"\x06result\x18\x02 \x01(\v2&.cardvalidator.v2.ValidateCardResponseH\x00R\x06result\x12\x16\n" +
+	"\x05error\x18\x03 \x01(\tH\x00R\x05errorB\t\n" +
+	"\aoutcome\"h\n" +
+	"\x19ValidateCardStreamRequest\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12$\n" +
+	"\vcard_number\x18\x02 \x01(\tB\x03\xe0A\x02R\n" +
+	"cardNumber\"\xa8\x01\n" +
+	"\x1aValidateCardStreamResponse\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12@\n" +
+	"\x06result\x18\x02 \x01(\v2&.cardvalidator.v2.ValidateCardResponseH\x00R\x06result\x12\x16\n" +
+	"\x05error\x18\x03 \x01(\tH\x00R\x05errorB\t\n" +
+	"\aoutcome\"%\n" +
+	"\x0fScanTextRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"q\n" +
+	"\x10ScanTextResponse\x128\n" +
+	"\bfindings\x18\x01 \x03(\v2\x1c.cardvalidator.v2.PanFindingR\bfindings\x12#\n" +
+	"\rredacted_text\x18\x02 \x01(\tR\fredactedText\"\x90\x01\n" +
+	"\n" +
+	"PanFinding\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\x12\x1d\n" +
+	"\n" +
+	"masked_pan\x18\x03 \x01(\tR\tmaskedPan\x12\x1b\n" +
+	"\tcard_type\x18\x04 \x01(\tR\bcardType\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x05 \x01(\x01R\n" +
+	"confidence\"\xa1\x01\n" +
+	"\aCountry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06alpha2\x18\x02 \x01(\tR\x06alpha2\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x14\n" +
+	"\x05emoji\x18\x04 \x01(\tR\x05emoji\x12\x1a\n" +
+	"\blatitude\x18\x05 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x06 \x01(\x01R\tlongitude\"B\n" +
+	"\x04Bank\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05phone\x18\x03 \x01(\tR\x05phone*p\n" +
+	"\tIssueCode\x12\x1a\n" +
+	"\x16ISSUE_CODE_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cISSUE_CODE_LUHN_CHECK_FAILED\x10\x01\x12%\n" +
+	"!ISSUE_CODE_CARD_TYPE_NOT_ACCEPTED\x10\x02*\xd7\x01\n" +
+	"\x10EnrichmentStatus\x12!\n" +
+	"\x1dENRICHMENT_STATUS_UNSPECIFIED\x10\x00\x12#\n" +
+	"\x1fENRICHMENT_STATUS_NOT_REQUESTED\x10\x01\x12\x1e\n" +
+	"\x1aENRICHMENT_STATUS_DISABLED\x10\x02\x12\x1d\n" +
+	"\x19ENRICHMENT_STATUS_SKIPPED\x10\x03\x12\x1e\n" +
+	"\x1aENRICHMENT_STATUS_ENRICHED\x10\x04\x12\x1c\n" +
+	"\x18ENRICHMENT_STATUS_FAILED\x10\x052\x9b\x04\n" +
+	"\rCardValidator\x12z\n" +
+	"\fValidateCard\x12%.cardvalidator.v2.ValidateCardRequest\x1a&.cardvalidator.v2.ValidateCardResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/api/v2/validate\x12\x83\x01\n" +
+	"\rValidateCards\x12&.cardvalidator.v2.ValidateCardsRequest\x1a'.cardvalidator.v2.ValidateCardsResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/api/v2/validate/batch\x12\x97\x01\n" +
+	"\x12ValidateCardStream\x12+.cardvalidator.v2.ValidateCardStreamRequest\x1a,.cardvalidator.v2.ValidateCardStreamResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/api/v2/validate/stream(\x010\x01\x12n\n" +
+	"\bScanText\x12!.cardvalidator.v2.ScanTextRequest\x1a\".cardvalidator.v2.ScanTextResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/api/v2/dlp/scanB4Z2credit-card-validator/pkg/proto/v2;cardvalidatorv2b\x06proto3"
+
+var (
+	file_pkg_proto_v2_cardvalidator_proto_rawDescOnce sync.Once
+	file_pkg_proto_v2_cardvalidator_proto_rawDescData []byte
+)
+
+func file_pkg_proto_v2_cardvalidator_proto_rawDescGZIP() []byte {
+	file_pkg_proto_v2_cardvalidator_proto_rawDescOnce.Do(func() {
+		file_pkg_proto_v2_cardvalidator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_proto_v2_cardvalidator_proto_rawDesc), len(file_pkg_proto_v2_cardvalidator_proto_rawDesc)))
+	})
+	return file_pkg_proto_v2_cardvalidator_proto_rawDescData
+}
+
+var file_pkg_proto_v2_cardvalidator_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_pkg_proto_v2_cardvalidator_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_pkg_proto_v2_cardvalidator_proto_goTypes = []any{
+	(IssueCode)(0),                     // 0: cardvalidator.v2.IssueCode
+	(EnrichmentStatus)(0),              // 1: cardvalidator.v2.EnrichmentStatus
+	(*ValidateCardRequest)(nil),        // 2: cardvalidator.v2.ValidateCardRequest
+	(*ValidateCardResponse)(nil),       // 3: cardvalidator.v2.ValidateCardResponse
+	(*ValidateCardsRequest)(nil),       // 4: cardvalidator.v2.ValidateCardsRequest
+	(*ValidateCardsResponse)(nil),      // 5: cardvalidator.v2.ValidateCardsResponse
+	(*ValidateCardsItem)(nil),          // 6: cardvalidator.v2.ValidateCardsItem
+	(*ValidateCardStreamRequest)(nil),  // 7: cardvalidator.v2.ValidateCardStreamRequest
+	(*ValidateCardStreamResponse)(nil), // 8: cardvalidator.v2.ValidateCardStreamResponse
+	(*ScanTextRequest)(nil),            // 9: cardvalidator.v2.ScanTextRequest
+	(*ScanTextResponse)(nil),           // 10: cardvalidator.v2.ScanTextResponse
+	(*PanFinding)(nil),                 // 11: cardvalidator.v2.PanFinding
+	(*Country)(nil),                    // 12: cardvalidator.v2.Country
+	(*Bank)(nil),                       // 13: cardvalidator.v2.Bank
+}
+var file_pkg_proto_v2_cardvalidator_proto_depIdxs = []int32{
+	12, // 0: cardvalidator.v2.ValidateCardResponse.country:type_name -> cardvalidator.v2.Country
+	13, // 1: cardvalidator.v2.ValidateCardResponse.bank:type_name -> cardvalidator.v2.Bank
+	0,  // 2: cardvalidator.v2.ValidateCardResponse.issues:type_name -> cardvalidator.v2.IssueCode
+	1,  // 3: cardvalidator.v2.ValidateCardResponse.enrichment_status:type_name -> cardvalidator.v2.EnrichmentStatus
+	6,  // 4: cardvalidator.v2.ValidateCardsResponse.results:type_name -> cardvalidator.v2.ValidateCardsItem
+	3,  // 5: cardvalidator.v2.ValidateCardsItem.result:type_name -> cardvalidator.v2.ValidateCardResponse
+	3,  // 6: cardvalidator.v2.ValidateCardStreamResponse.result:type_name -> cardvalidator.v2.ValidateCardResponse
+	11, // 7: cardvalidator.v2.ScanTextResponse.findings:type_name -> cardvalidator.v2.PanFinding
+	2,  // 8: cardvalidator.v2.CardValidator.ValidateCard:input_type -> cardvalidator.v2.ValidateCardRequest
+	4,  // 9: cardvalidator.v2.CardValidator.ValidateCards:input_type -> cardvalidator.v2.ValidateCardsRequest
+	7,  // 10: cardvalidator.v2.CardValidator.ValidateCardStream:input_type -> cardvalidator.v2.ValidateCardStreamRequest
+	9,  // 11: cardvalidator.v2.CardValidator.ScanText:input_type -> cardvalidator.v2.ScanTextRequest
+	3,  // 12: cardvalidator.v2.CardValidator.ValidateCard:output_type -> cardvalidator.v2.ValidateCardResponse
+	5,  // 13: cardvalidator.v2.CardValidator.ValidateCards:output_type -> cardvalidator.v2.ValidateCardsResponse
+	8,  // 14: cardvalidator.v2.CardValidator.ValidateCardStream:output_type -> cardvalidator.v2.ValidateCardStreamResponse
+	10, // 15: cardvalidator.v2.CardValidator.ScanText:output_type -> cardvalidator.v2.ScanTextResponse
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_pkg_proto_v2_cardvalidator_proto_init() }
+func file_pkg_proto_v2_cardvalidator_proto_init() {
+	if File_pkg_proto_v2_cardvalidator_proto != nil {
+		return
+	}
+	file_pkg_proto_v2_cardvalidator_proto_msgTypes[4].OneofWrappers = []any{
+		(*ValidateCardsItem_Result)(nil),
+		(*ValidateCardsItem_Error)(nil),
+	}
+	file_pkg_proto_v2_cardvalidator_proto_msgTypes[6].OneofWrappers = []any{
+		(*ValidateCardStreamResponse_Result)(nil),
+		(*ValidateCardStreamResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_proto_v2_cardvalidator_proto_rawDesc), len(file_pkg_proto_v2_cardvalidator_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_proto_v2_cardvalidator_proto_goTypes,
+		DependencyIndexes: file_pkg_proto_v2_cardvalidator_proto_depIdxs,
+		EnumInfos:         file_pkg_proto_v2_cardvalidator_proto_enumTypes,
+		MessageInfos:      file_pkg_proto_v2_cardvalidator_proto_msgTypes,
+	}.Build()
+	File_pkg_proto_v2_cardvalidator_proto = out.File
+	file_pkg_proto_v2_cardvalidator_proto_goTypes = nil
+	file_pkg_proto_v2_cardvalidator_proto_depIdxs = nil
+}
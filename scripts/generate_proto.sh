@@ -3,9 +3,17 @@
 set -e
 
 PROTO_PATH=./pkg/proto
-PROTO_FILE=cardvalidator.proto
+PROTO_FILES="cardvalidator.proto v2/cardvalidator.proto"
 OUT_DIR=.
 
-protoc --go_out=$OUT_DIR --go_opt=paths=source_relative \
-    --go-grpc_out=$OUT_DIR --go-grpc_opt=paths=source_relative \
-    $PROTO_PATH/$PROTO_FILE
\ No newline at end of file
+# google/api/annotations.proto and google/api/http.proto are looked up in
+# GOOGLEAPIS_DIR (a checkout of github.com/googleapis/googleapis)
+GOOGLEAPIS_DIR=${GOOGLEAPIS_DIR:-third_party/googleapis}
+
+for PROTO_FILE in $PROTO_FILES; do
+    protoc -I. -I$GOOGLEAPIS_DIR \
+        --go_out=$OUT_DIR --go_opt=paths=source_relative \
+        --go-grpc_out=$OUT_DIR --go-grpc_opt=paths=source_relative \
+        --grpc-gateway_out=$OUT_DIR --grpc-gateway_opt=paths=source_relative \
+        $PROTO_PATH/$PROTO_FILE
+done
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/service"
+)
+
+func TestAuditLogChain(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AuditConfig{
+		Dir:            dir,
+		FingerprintKey: "test-key",
+		ChainKey:       "chain-key",
+		MaxFileSize:    600,
+	}
+	key := audit.ChainKey(cfg)
+
+	validator, err := service.NewValidator(service.DefaultConfig(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := validator.ValidateCardSimple("4111 1111 1111 1111")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := func(n int) {
+		logger, err := audit.NewLogger(cfg)
+		if err != nil {
+			t.Fatalf("NewLogger() error = %v", err)
+		}
+		defer logger.Close()
+
+		for i := 0; i < n; i++ {
+			if err := logger.RecordValidation(context.Background(), audit.ChannelREST, "req", result); err != nil {
+				t.Fatalf("RecordValidation() error = %v", err)
+			}
+		}
+	}
+
+	// Reopening the log must continue the existing chain
+	record(3)
+	checkpointPath := filepath.Join(dir, "checkpoint.json")
+	olderCheckpoint, err := os.ReadFile(checkpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record(3)
+
+	report, err := audit.Verify(dir, key, nil)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if report.Entries != 6 || report.Files < 2 || report.Checkpoint == nil || report.Checkpoint.Sequence != 6 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	exported := report.Checkpoint
+
+	// A chain verified with another key fails
+	if _, err := audit.Verify(dir, []byte("other-key"), nil); !errors.Is(err, audit.ErrTampered) {
+		t.Errorf("Verify() with another key error = %v; want %v", err, audit.ErrTampered)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "audit-*.log"))
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("4111111111111111")) {
+		t.Fatal("audit log contains a cleartext PAN")
+	}
+
+	t.Run("Modified entry", func(t *testing.T) {
+		tampered := strings.Replace(string(data), `"valid":true`, `"valid":false`, 1)
+		if err := os.WriteFile(files[0], []byte(tampered), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		defer os.WriteFile(files[0], data, 0o600)
+
+		if _, err := audit.Verify(dir, key, nil); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("Verify() error = %v; want %v", err, audit.ErrTampered)
+		}
+	})
+
+	t.Run("Removed entry", func(t *testing.T) {
+		lines := strings.SplitAfter(string(data), "\n")
+		if err := os.WriteFile(files[0], []byte(strings.Join(lines[1:], "")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		defer os.WriteFile(files[0], data, 0o600)
+
+		if _, err := audit.Verify(dir, key, nil); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("Verify() error = %v; want %v", err, audit.ErrTampered)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		if len(files) < 3 {
+			t.Skip("not enough rotated files")
+		}
+		middle, err := os.ReadFile(files[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(files[1])
+		defer os.WriteFile(files[1], middle, 0o600)
+
+		if _, err := audit.Verify(dir, key, nil); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("Verify() error = %v; want %v", err, audit.ErrTampered)
+		}
+	})
+
+	t.Run("Recomputed chain", func(t *testing.T) {
+		// Hashes recomputed without the chain key do not verify
+		var lines []string
+		prev := ""
+		for _, line := range strings.SplitAfter(strings.TrimSpace(string(data)), "\n") {
+			var entry audit.Entry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatal(err)
+			}
+			entry.Valid = false
+			entry.PrevHash = prev
+			entry.Hash = ""
+			encoded, _ := json.Marshal(entry)
+			sum := sha256.Sum256(encoded)
+			entry.Hash = hex.EncodeToString(sum[:])
+			prev = entry.Hash
+			encoded, _ = json.Marshal(entry)
+			lines = append(lines, string(encoded)+"\n")
+		}
+		if err := os.WriteFile(files[0], []byte(strings.Join(lines, "")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		defer os.WriteFile(files[0], data, 0o600)
+
+		if _, err := audit.Verify(dir, key, nil); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("Verify() error = %v; want %v", err, audit.ErrTampered)
+		}
+	})
+
+	newest := files[len(files)-1]
+	newestData, err := os.ReadFile(newest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Truncated tail", func(t *testing.T) {
+		lines := strings.SplitAfter(string(newestData), "\n")
+		if err := os.WriteFile(newest, []byte(strings.Join(lines[:len(lines)-2], "")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		defer os.WriteFile(newest, newestData, 0o600)
+
+		if _, err := audit.Verify(dir, key, nil); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("Verify() error = %v; want %v", err, audit.ErrTampered)
+		}
+		// The truncated log is not resumed, which would hide the truncation
+		if _, err := audit.NewLogger(cfg); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("NewLogger() error = %v; want %v", err, audit.ErrTampered)
+		}
+	})
+
+	t.Run("Deleted newest file", func(t *testing.T) {
+		os.Remove(newest)
+		defer os.WriteFile(newest, newestData, 0o600)
+
+		if _, err := audit.Verify(dir, key, nil); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("Verify() error = %v; want %v", err, audit.ErrTampered)
+		}
+	})
+
+	t.Run("Rolled back checkpoint", func(t *testing.T) {
+		current, err := os.ReadFile(checkpointPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.SplitAfter(string(newestData), "\n")
+		os.WriteFile(newest, []byte(strings.Join(lines[:len(lines)-2], "")), 0o600)
+		os.WriteFile(checkpointPath, olderCheckpoint, 0o600)
+		defer func() {
+			os.WriteFile(newest, newestData, 0o600)
+			os.WriteFile(checkpointPath, current, 0o600)
+		}()
+
+		// The older checkpoint is still in the log; the exported one is not
+		if _, err := audit.Verify(dir, key, nil); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if _, err := audit.Verify(dir, key, exported); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("Verify() with exported checkpoint error = %v; want %v", err, audit.ErrTampered)
+		}
+	})
+
+	t.Run("Forged checkpoint", func(t *testing.T) {
+		forged := *exported
+		forged.Sequence = 3
+		if _, err := audit.Verify(dir, key, &forged); !errors.Is(err, audit.ErrTampered) {
+			t.Errorf("Verify() error = %v; want %v", err, audit.ErrTampered)
+		}
+	})
+
+	if _, err := audit.Verify(dir, key, exported); err != nil {
+		t.Errorf("Verify() of the restored log error = %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"credit-card-validator/internal/auth"
+)
+
+func TestKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	store, err := auth.NewKeyStore(path)
+	if err != nil {
+		t.Fatalf("NewKeyStore() error = %v", err)
+	}
+
+	token, key, err := store.Create("settlement", "retail", []auth.Scope{auth.ScopeValidate})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	identity, err := store.Authenticate(context.Background(), auth.Credentials{APIKey: token})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if identity.Subject != key.ID || identity.Tenant != "retail" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	if !identity.HasScope(auth.ScopeValidate) || identity.HasScope(auth.ScopeBINRead) {
+		t.Errorf("unexpected scopes %v", identity.Scopes)
+	}
+
+	// A second store sees keys written by the first, as the keys command does
+	reopened, err := auth.NewKeyStore(path)
+	if err != nil {
+		t.Fatalf("NewKeyStore() error = %v", err)
+	}
+	if err := reopened.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{name: "Revoked key", token: token, want: auth.ErrInvalidCredentials},
+		{name: "Malformed key", token: "not-a-key", want: auth.ErrInvalidCredentials},
+		{name: "Missing key", token: "", want: auth.ErrMissingCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Authenticate(context.Background(), auth.Credentials{APIKey: tt.token})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Authenticate() error = %v; want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"credit-card-validator/internal/service"
+)
+
+func TestValidateCardsDeduplicatesBINLookups(t *testing.T) {
+	var lookups int32
+	bins := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lookups, 1)
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"scheme":"visa","bank":{"name":"Test Bank"}}`))
+	}))
+	defer bins.Close()
+
+	cfg := service.DefaultConfig()
+	cfg.BINServiceURL = bins.URL
+
+	validator, err := service.NewValidator(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cards := []string{
+		"4111 1111 1111 1111",
+		"not a card",
+		"4111111111111111",
+		"4111-1111-1111-1111",
+		"5555 5555 5555 4444",
+	}
+
+	results := validator.ValidateCards(context.Background(), cards, 4)
+	if len(results) != len(cards) {
+		t.Fatalf("got %d results; want %d", len(results), len(cards))
+	}
+
+	for i, item := range results {
+		if item.Index != i {
+			t.Errorf("results[%d].Index = %d", i, item.Index)
+		}
+	}
+
+	if !errors.Is(results[1].Err, service.ErrInvalidCardNumber) {
+		t.Errorf("results[1].Err = %v; want %v", results[1].Err, service.ErrInvalidCardNumber)
+	}
+	if results[0].Result.Bank.Name != "Test Bank" || results[3].Result.Bank.Name != "Test Bank" {
+		t.Errorf("results were not enriched: %+v", results[0].Result)
+	}
+	if results[4].Result.CardType != service.CardTypeMastercard {
+		t.Errorf("results[4].CardType = %v", results[4].Result.CardType)
+	}
+
+	// Three cards share BIN 411111, one has BIN 555555
+	if got := atomic.LoadInt32(&lookups); got != 2 {
+		t.Errorf("BIN service called %d times; want 2", got)
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// buildCCValidate compiles the ccvalidate command into a temporary directory
+func buildCCValidate(t *testing.T) string {
+	t.Helper()
+
+	bin := filepath.Join(t.TempDir(), "ccvalidate")
+	cmd := exec.Command("go", "build", "-o", bin, "../cmd/ccvalidate")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// runCCValidate runs the command and returns its stdout and exit status
+func runCCValidate(t *testing.T, bin, stdin string, args ...string) (string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(bin, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("ccvalidate failed: %v\n%s", err, stderr.String())
+	}
+	return stdout.String(), 0
+}
+
+func TestCCValidateExitCodesAndFormats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the command")
+	}
+	bin := buildCCValidate(t)
+
+	out, code := runCCValidate(t, bin, "", "-o", "csv", "4111 1111 1111 1111", "5555555555554444")
+	if code != 0 {
+		t.Errorf("exit status = %d; want 0", code)
+	}
+	if !strings.Contains(out, "4111********1111") || strings.Contains(out, "4111111111111111") {
+		t.Errorf("CSV output does not mask card numbers:\n%s", out)
+	}
+	if lines := strings.Count(out, "\n"); lines != 3 {
+		t.Errorf("got %d CSV lines; want header and two rows", lines)
+	}
+
+	out, code = runCCValidate(t, bin, "4111111111111111\n# comment\n\n4111111111111112\n", "-o", "json")
+	if code != 1 {
+		t.Errorf("exit status = %d; want 1 for an invalid number", code)
+	}
+
+	var results []map[string]any
+	decoder := json.NewDecoder(strings.NewReader(out))
+	for decoder.More() {
+		var result map[string]any
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, out)
+		}
+		results = append(results, result)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results; want 2", len(results))
+	}
+	if results[1]["valid"] != false || results[1]["line"] != float64(4) {
+		t.Errorf("results[1] = %v", results[1])
+	}
+
+	if _, code := runCCValidate(t, bin, "", "-accept", "mastercard", "4111111111111111"); code != 1 {
+		t.Errorf("exit status = %d; want 1 for a card type that is not accepted", code)
+	}
+	if _, code := runCCValidate(t, bin, "", "-o", "xml", "4111111111111111"); code != 2 {
+		t.Errorf("exit status = %d; want 2 for an unknown format", code)
+	}
+}
+
+func TestCCValidatePrintsResultsAsInputIsRead(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the command")
+	}
+	bin := buildCCValidate(t)
+
+	for _, format := range []string{"json", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			cmd := exec.Command(bin, "-o", format, "-no-header")
+			stdin, err := cmd.StdinPipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stdout, err := cmd.StdoutPipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cmd.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer cmd.Wait()
+			defer stdin.Close()
+
+			// stdin stays open, so the result must be printed before the
+			// input ends
+			if _, err := stdin.Write([]byte("4111111111111111\n")); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := make(chan string, 1)
+			go func() {
+				line, _ := bufio.NewReader(stdout).ReadString('\n')
+				lines <- line
+			}()
+			select {
+			case line := <-lines:
+				if !strings.Contains(line, "4111********1111") {
+					t.Errorf("first line = %q; want the result of the first card", line)
+				}
+			case <-time.After(5 * time.Second):
+				cmd.Process.Kill()
+				t.Fatal("no result printed before stdin was closed")
+			}
+		})
+	}
+}
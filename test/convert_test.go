@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"credit-card-validator/internal/api/convert"
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	pb "credit-card-validator/pkg/proto"
+	pbv2 "credit-card-validator/pkg/proto/v2"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func enrichedResult() *service.ValidationResult {
+	return &service.ValidationResult{
+		Valid:      false,
+		CardType:   service.CardTypeVisa,
+		CardNumber: "4111111111111112",
+		Scheme:     "visa",
+		CardBrand:  "Visa Classic",
+		CardKind:   "debit",
+		Country: service.CountryInfo{
+			Name:      "Poland",
+			Alpha2:    "PL",
+			Currency:  "PLN",
+			Emoji:     "🇵🇱",
+			Latitude:  52.5,
+			Longitude: -19.75,
+		},
+		Bank:             service.BankInfo{Name: "Example Bank", URL: "www.example.com", Phone: "+48 123"},
+		BIN:              "411111",
+		LastFour:         "1112",
+		Issues:           []service.IssueCode{service.IssueLuhnCheckFailed, service.IssueCardTypeNotAccepted},
+		EnrichmentStatus: service.EnrichmentEnriched,
+		EnrichmentSource: service.EnrichmentSourceBINService,
+	}
+}
+
+func TestConvertV2RoundTrip(t *testing.T) {
+	result := enrichedResult()
+
+	res := convert.ToV2(result)
+	if res.Country.Latitude != 52.5 || res.Country.Longitude != -19.75 {
+		t.Errorf("coordinates = %v, %v; want 52.5, -19.75", res.Country.Latitude, res.Country.Longitude)
+	}
+	if res.Bin != "411111" || res.LastFour != "1112" {
+		t.Errorf("bin/last four = %q/%q", res.Bin, res.LastFour)
+	}
+	wantIssues := []pbv2.IssueCode{pbv2.IssueCode_ISSUE_CODE_LUHN_CHECK_FAILED, pbv2.IssueCode_ISSUE_CODE_CARD_TYPE_NOT_ACCEPTED}
+	if !reflect.DeepEqual(res.Issues, wantIssues) {
+		t.Errorf("issues = %v; want %v", res.Issues, wantIssues)
+	}
+	if res.EnrichmentStatus != pbv2.EnrichmentStatus_ENRICHMENT_STATUS_ENRICHED || res.EnrichmentSource != "bin_service" {
+		t.Errorf("enrichment = %v from %q", res.EnrichmentStatus, res.EnrichmentSource)
+	}
+
+	if got := convert.FromV2(res); !reflect.DeepEqual(got, result) {
+		t.Errorf("FromV2(ToV2()) = %+v; want %+v", got, result)
+	}
+}
+
+func TestConvertV2EnrichmentStatuses(t *testing.T) {
+	statuses := map[service.EnrichmentStatus]pbv2.EnrichmentStatus{
+		service.EnrichmentNotRequested: pbv2.EnrichmentStatus_ENRICHMENT_STATUS_NOT_REQUESTED,
+		service.EnrichmentDisabled:     pbv2.EnrichmentStatus_ENRICHMENT_STATUS_DISABLED,
+		service.EnrichmentSkipped:      pbv2.EnrichmentStatus_ENRICHMENT_STATUS_SKIPPED,
+		service.EnrichmentEnriched:     pbv2.EnrichmentStatus_ENRICHMENT_STATUS_ENRICHED,
+		service.EnrichmentFailed:       pbv2.EnrichmentStatus_ENRICHMENT_STATUS_FAILED,
+		"":                             pbv2.EnrichmentStatus_ENRICHMENT_STATUS_UNSPECIFIED,
+	}
+	for status, want := range statuses {
+		res := convert.ToV2(&service.ValidationResult{EnrichmentStatus: status})
+		if res.EnrichmentStatus != want {
+			t.Errorf("ToV2(%q) status = %v; want %v", status, res.EnrichmentStatus, want)
+		}
+		if got := convert.FromV2(res).EnrichmentStatus; got != status {
+			t.Errorf("FromV2(%v) status = %q; want %q", want, got, status)
+		}
+	}
+}
+
+func TestConvertV1(t *testing.T) {
+	res := convert.ToV1(enrichedResult())
+	if res.Country.Latitude != 52 || res.Country.Longitude != -19 {
+		t.Errorf("v1 coordinates = %d, %d; want 52, -19", res.Country.Latitude, res.Country.Longitude)
+	}
+	if res.Bank.Url != "www.example.com" || res.CardKind != "debit" {
+		t.Errorf("unexpected v1 response %+v", res)
+	}
+
+	got := convert.FromV1(res)
+	if got.Country.Latitude != 52 || got.BIN != "" || got.Issues != nil {
+		t.Errorf("FromV1() = %+v", got)
+	}
+
+	// Results without issuer data carry no country or bank
+	empty := convert.ToV1(&service.ValidationResult{CardType: service.CardTypeVisa})
+	if empty.Country != nil || empty.Bank != nil {
+		t.Errorf("empty result has country %v, bank %v", empty.Country, empty.Bank)
+	}
+}
+
+func TestConvertFindings(t *testing.T) {
+	scanner, err := dlp.NewScanner(&config.DLPConfig{MaxTextSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := scanner.ScanText("card 4111 1111 1111 1111 on file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v1 := convert.FindingsToV1(result.Findings)
+	v2 := convert.FindingsToV2(result.Findings)
+	if len(v1) != 1 || len(v2) != 1 {
+		t.Fatalf("findings = %d/%d; want 1", len(v1), len(v2))
+	}
+	if v1[0].Start != 5 || v2[0].End != 24 || v2[0].MaskedPan != v1[0].MaskedPan || v2[0].CardType != "visa" {
+		t.Errorf("findings v1 %+v, v2 %+v", v1[0], v2[0])
+	}
+}
+
+func TestV1AndV2Served(t *testing.T) {
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Batch:     config.BatchConfig{MaxItems: 10, Concurrency: 2, StreamConcurrency: 2},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	registry, err := tenant.NewRegistry(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpcapi.NewServer(registry, nil, &cfg.Batch, nil, logging.Discard()).RegisterServer(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+
+	v1, err := pb.NewCardValidatorClient(conn).ValidateCard(ctx, &pb.ValidateCardRequest{CardNumber: "4111111111111111"})
+	if err != nil {
+		t.Fatalf("v1 ValidateCard() error = %v", err)
+	}
+	if !v1.Valid || v1.CardType != "visa" {
+		t.Errorf("v1 response = %+v", v1)
+	}
+
+	v2, err := pbv2.NewCardValidatorClient(conn).ValidateCards(ctx, &pbv2.ValidateCardsRequest{
+		CardNumbers: []string{"4111111111111111", "4111111111111112"},
+	})
+	if err != nil {
+		t.Fatalf("v2 ValidateCards() error = %v", err)
+	}
+	valid, invalid := v2.Results[0].GetResult(), v2.Results[1].GetResult()
+	if !valid.Valid || valid.Bin != "411111" || valid.LastFour != "1111" {
+		t.Errorf("v2 valid result = %+v", valid)
+	}
+	if valid.EnrichmentStatus != pbv2.EnrichmentStatus_ENRICHMENT_STATUS_DISABLED {
+		t.Errorf("v2 enrichment status = %v; want DISABLED", valid.EnrichmentStatus)
+	}
+	if invalid.Valid || len(invalid.Issues) != 1 || invalid.Issues[0] != pbv2.IssueCode_ISSUE_CODE_LUHN_CHECK_FAILED {
+		t.Errorf("v2 invalid result = %+v", invalid)
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/service"
+)
+
+func TestScanTextFindsAndRedactsPANs(t *testing.T) {
+	scanner, err := dlp.NewScanner(&config.DLPConfig{MaxTextSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "Customer card 4111 1111 1111 1111, backup 3782-822463-10005.\n" +
+		"Order 4111111111111112 (bad Luhn), ref ID5555555555554444 and 5555.5555.5555.4444 exp 2027."
+	result, err := scanner.ScanText(text)
+	if err != nil {
+		t.Fatalf("ScanText() error = %v", err)
+	}
+
+	want := []struct {
+		pan      string
+		cardType service.CardType
+	}{
+		{"4111 1111 1111 1111", service.CardTypeVisa},
+		{"3782-822463-10005", service.CardTypeAmex},
+		{"5555555555554444", service.CardTypeMastercard},
+		{"5555.5555.5555.4444", service.CardTypeMastercard},
+	}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("got %d findings (%+v); want %d", len(result.Findings), result.Findings, len(want))
+	}
+	for i, w := range want {
+		f := result.Findings[i]
+		if got := text[f.Start:f.End]; got != w.pan {
+			t.Errorf("findings[%d] covers %q; want %q", i, got, w.pan)
+		}
+		if f.CardType != w.cardType {
+			t.Errorf("findings[%d].CardType = %s; want %s", i, f.CardType, w.cardType)
+		}
+		if f.Confidence < 0.8 || f.Confidence > 1 {
+			t.Errorf("findings[%d].Confidence = %v", i, f.Confidence)
+		}
+	}
+	if result.Findings[0].MaskedPAN != "4111********1111" {
+		t.Errorf("MaskedPAN = %q", result.Findings[0].MaskedPAN)
+	}
+
+	// Masking keeps the length so offsets stay valid in the redacted text
+	if len(result.RedactedText) != len(text) {
+		t.Errorf("redacted text length = %d; want %d", len(result.RedactedText), len(text))
+	}
+	if !strings.Contains(result.RedactedText, "**** **** **** 1111") || !strings.Contains(result.RedactedText, "****.****.****.4444") {
+		t.Errorf("unexpected redacted text: %s", result.RedactedText)
+	}
+	if strings.Contains(result.RedactedText, "4111 1111") {
+		t.Errorf("redacted text still contains a PAN: %s", result.RedactedText)
+	}
+
+	if _, err := scanner.ScanText(strings.Repeat("x", 2048)); !errors.Is(err, dlp.ErrTextTooLarge) {
+		t.Errorf("ScanText() error = %v; want %v", err, dlp.ErrTextTooLarge)
+	}
+}
+
+func TestScanSplitsRunsAtGroupBoundaries(t *testing.T) {
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A PAN followed by an expiry in the same run of groups
+	text := "4111 1111 1111 1111 1227"
+	findings := scanner.Scan(text)
+	if len(findings) != 1 || text[findings[0].Start:findings[0].End] != "4111 1111 1111 1111" {
+		t.Fatalf("Scan(%q) = %+v", text, findings)
+	}
+
+	// Letters and underscores next to a PAN do not hide it
+	for _, text := range []string{"PAN4111111111111111", "4111111111111111x", "card_4111111111111111_exp"} {
+		findings := scanner.Scan(text)
+		if len(findings) != 1 || text[findings[0].Start:findings[0].End] != "4111111111111111" {
+			t.Errorf("Scan(%q) = %+v; want 4111111111111111", text, findings)
+		}
+	}
+
+	// Adjacent digits make the run longer than a PAN
+	for _, text := range []string{"41111111111111110000", "04111111111111111", "tel 1234 5678 9012 3456", "2024-01-15 10:00:00.123"} {
+		if findings := scanner.Scan(text); len(findings) != 0 {
+			t.Errorf("Scan(%q) = %+v; want no findings", text, findings)
+		}
+	}
+}
+
+func TestScanFilesWalksArchives(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gzipped := func(data []byte) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(data)
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	member := []byte("header\namex 378282246310005\n")
+	tw.WriteHeader(&tar.Header{Name: "export/cards.csv", Mode: 0o644, Size: int64(len(member)), Typeflag: tar.TypeReg})
+	tw.Write(member)
+	tw.Close()
+
+	write("logs/app.log", []byte("start\npaid with 4111 1111 1111 1111\n"))
+	write("logs/app.1.log.gz", gzipped([]byte("card=5555555555554444\n")))
+	write("dumps/export.tar.gz", gzipped(archive.Bytes()))
+	write("vendor/lib.log", []byte("4111111111111111\n"))
+	write("notes.md", []byte("4111111111111111\n"))
+
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var findings []dlp.FileFinding
+	opts := dlp.FileScanOptions{
+		Include: []string{"*.log", "*.csv"},
+		Exclude: []string{"vendor"},
+		Workers: 3,
+	}
+	stats, err := scanner.ScanFiles(context.Background(), []string{dir}, opts, func(file string, found []dlp.FileFinding, err error) {
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+		findings = append(findings, found...)
+	})
+	if err != nil {
+		t.Fatalf("ScanFiles() error = %v", err)
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].File < findings[j].File })
+
+	want := []dlp.FileFinding{
+		{File: filepath.Join(dir, "dumps/export.tar.gz") + "!export/cards.csv", Line: 2, Offset: 12, MaskedPAN: "3782*******0005", CardType: "amex"},
+		{File: filepath.Join(dir, "logs/app.1.log.gz"), Line: 1, Offset: 5, MaskedPAN: "5555********4444", CardType: "mastercard"},
+		{File: filepath.Join(dir, "logs/app.log"), Line: 2, Offset: 16, MaskedPAN: "4111********1111", CardType: "visa"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("got findings %+v; want %d", findings, len(want))
+	}
+	for i, w := range want {
+		got := findings[i]
+		got.Confidence = 0
+		if got != w {
+			t.Errorf("findings[%d] = %+v; want %+v", i, got, w)
+		}
+	}
+	if stats.Files != 3 || stats.Findings != 3 || stats.Errors != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestScanStreamFindsPANsAcrossChunks(t *testing.T) {
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines longer than MaxLineSize are scanned in chunks; every position of
+	// the PAN relative to the chunk boundaries must be found once
+	opts := &dlp.FileScanOptions{MaxLineSize: 16}
+	for _, pan := range []string{"4111111111111111", "4111 1111 1111 1111", "4-1-1-1-1-1-1-1-1-1-1-1-1-1-1-1"} {
+		for shift := 0; shift < 40; shift++ {
+			text := "header\n" + strings.Repeat("x", shift) + pan + strings.Repeat("y", 20) + "\n"
+			findings, err := scanner.ScanStream("log", strings.NewReader(text), opts)
+			if err != nil {
+				t.Fatalf("ScanStream() error = %v", err)
+			}
+			want := int64(len("header\n") + shift)
+			if len(findings) != 1 || findings[0].Offset != want || findings[0].Line != 2 {
+				t.Errorf("ScanStream(%q) = %+v; want one finding at offset %d on line 2", text, findings, want)
+			}
+		}
+	}
+
+	// Longer numbers are never cut into PANs, even when they are longer
+	// than a chunk
+	for _, run := range []string{
+		"4111111111111111" + strings.Repeat("0", 8) + "4111111111111111",
+		strings.Repeat("4111111111111111 ", 2) + "4111111111111111" + strings.Repeat("0", 24) + "4111111111111111",
+	} {
+		for shift := 0; shift < 40; shift++ {
+			text := strings.Repeat("x", shift) + run + "\n"
+			findings, err := scanner.ScanStream("log", strings.NewReader(text), opts)
+			if err != nil {
+				t.Fatalf("ScanStream() error = %v", err)
+			}
+			want := strings.Count(run, " ")
+			if len(findings) != want {
+				t.Errorf("ScanStream(%q) = %+v; want %d findings", text, findings, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+)
+
+// zipDocument builds a zip based document from part names and contents
+func zipDocument(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pdfDocument builds a single page PDF showing text
+func pdfDocument(text string) []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func TestScanFileFindsPANsInDocuments(t *testing.T) {
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xlsx := zipDocument(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Refunds" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+			<si><t>Card</t></si><si><r><t>4111 1111 </t></r><r><t>1111 1111</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c></row>
+			<row r="2"><c r="A2" t="s"><v>1</v></c><c r="B2"><v>5555555555554444</v></c></row>
+			</sheetData></worksheet>`,
+	})
+
+	docx := zipDocument(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+			<w:p><w:r><w:t>Dear customer,</w:t></w:r></w:p>
+			<w:p><w:r><w:t>your card 3782</w:t></w:r><w:r><w:t>-822463-10005 was charged</w:t></w:r></w:p>
+			</w:body></w:document>`,
+		"word/footer1.xml": `<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>4111111111111111</w:t></w:r></w:p></w:ftr>`,
+	})
+
+	ods := zipDocument(t, map[string]string{
+		"content.xml": `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+			<office:body><office:spreadsheet><table:table table:name="Export">
+			<table:table-row table:number-rows-repeated="2"><table:table-cell/></table:table-row>
+			<table:table-row><table:table-cell table:number-columns-repeated="2"/><table:table-cell><text:p>pan 4111-1111-1111-1111</text:p></table:table-cell></table:table-row>
+			</table:table></office:spreadsheet></office:body></office:document-content>`,
+	})
+
+	tests := []struct {
+		name  string
+		data  []byte
+		wants []dlp.FileFinding
+	}{
+		{"refunds.xlsx", xlsx, []dlp.FileFinding{
+			{Location: "Refunds!A2", MaskedPAN: "4111********1111", CardType: "visa"},
+			{Location: "Refunds!B2", MaskedPAN: "5555********4444", CardType: "mastercard"},
+		}},
+		{"letter.docx", docx, []dlp.FileFinding{
+			{Location: "paragraph 2", Offset: 10, MaskedPAN: "3782*******0005", CardType: "amex"},
+			{Location: "footer1 paragraph 1", MaskedPAN: "4111********1111", CardType: "visa"},
+		}},
+		{"export.ods", ods, []dlp.FileFinding{
+			{Location: "Export!C3", Offset: 4, MaskedPAN: "4111********1111", CardType: "visa"},
+		}},
+		{"statement.pdf", pdfDocument("Card number: 4111 1111 1111 1111"), []dlp.FileFinding{
+			{Location: "page 1", Offset: 13, MaskedPAN: "4111********1111", CardType: "visa"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := scanner.ScanFile(context.Background(), tt.name, bytes.NewReader(tt.data), nil)
+			if err != nil {
+				t.Fatalf("ScanFile() error = %v", err)
+			}
+			if len(findings) != len(tt.wants) {
+				t.Fatalf("got findings %+v; want %d", findings, len(tt.wants))
+			}
+			for i, want := range tt.wants {
+				got := findings[i]
+				want.File = tt.name
+				got.Confidence = 0
+				if got != want {
+					t.Errorf("findings[%d] = %+v; want %+v", i, got, want)
+				}
+			}
+		})
+	}
+
+	if _, err := scanner.ScanFile(context.Background(), "broken.pdf", bytes.NewReader([]byte("%PDF-1.4 garbage")), nil); err == nil {
+		t.Error("ScanFile() of a malformed PDF should fail")
+	}
+}
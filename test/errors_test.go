@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/jobs"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/middleware"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	pb "credit-card-validator/pkg/proto"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorMatching(t *testing.T) {
+	err := fmt.Errorf("%w: %q", tenant.ErrUnknownTenant, "acme")
+
+	if !errors.Is(err, tenant.ErrUnknownTenant) || errors.Is(err, tenant.ErrTenantMismatch) {
+		t.Error("wrapped tenant error does not match its sentinel only")
+	}
+
+	// Errors match sentinels by code, so copies still match
+	if !errors.Is(apperror.From(err), tenant.ErrUnknownTenant) {
+		t.Error("converted error does not match its sentinel")
+	}
+	if !errors.Is(service.ErrInvalidCardNumber.WithField("card_number"), service.ErrInvalidCardNumber) {
+		t.Error("error with field does not match its sentinel")
+	}
+
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeUnknownTenant {
+		t.Fatalf("errors.As() = %v", appErr)
+	}
+
+	tests := []struct {
+		err     error
+		code    apperror.Code
+		status  int
+		grpc    codes.Code
+		message string
+	}{
+		{err, apperror.CodeUnknownTenant, http.StatusBadRequest, codes.InvalidArgument, `unknown tenant: "acme"`},
+		{jobs.ErrJobNotFound, apperror.CodeJobNotFound, http.StatusNotFound, codes.NotFound, "job not found"},
+		{jobs.ErrJobFinished, apperror.CodeJobFinished, http.StatusConflict, codes.FailedPrecondition, "job has already finished"},
+		{jobs.ErrQueueFull, apperror.CodeQueueFull, http.StatusServiceUnavailable, codes.Unavailable, "job queue is full"},
+		{context.DeadlineExceeded, apperror.CodeDeadlineExceeded, http.StatusGatewayTimeout, codes.DeadlineExceeded, "deadline exceeded"},
+		// Unexpected errors do not reveal their cause
+		{errors.New("disk full"), apperror.CodeInternal, http.StatusInternalServerError, codes.Internal, "internal error"},
+		{fmt.Errorf("saving: %w", apperror.Wrap(apperror.CodeInternal, errors.New("disk full"), "save failed")), apperror.CodeInternal, http.StatusInternalServerError, codes.Internal, "save failed"},
+	}
+	for _, tt := range tests {
+		got := apperror.From(tt.err)
+		if got.Code != tt.code || got.Code.HTTPStatus() != tt.status || got.Code.GRPCCode() != tt.grpc || got.Message != tt.message {
+			t.Errorf("From(%v) = %s %d %s %q; want %s %d %s %q", tt.err,
+				got.Code, got.Code.HTTPStatus(), got.Code.GRPCCode(), got.Message,
+				tt.code, tt.status, tt.grpc, tt.message)
+		}
+	}
+}
+
+func TestGRPCErrorDetails(t *testing.T) {
+	conn, _ := newObservedClient(t)
+	client := pb.NewCardValidatorClient(conn)
+
+	_, err := client.ValidateCard(context.Background(), &pb.ValidateCardRequest{CardNumber: "abc"})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || st.Message() != "invalid card number format" {
+		t.Fatalf("status = %v", st)
+	}
+
+	var (
+		info       *errdetails.ErrorInfo
+		badRequest *errdetails.BadRequest
+	)
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			badRequest = d
+		}
+	}
+	if info == nil || info.Reason != string(apperror.CodeInvalidCardNumber) || info.Domain != apperror.Domain {
+		t.Errorf("ErrorInfo = %v", info)
+	}
+	if badRequest == nil || len(badRequest.FieldViolations) != 1 || badRequest.FieldViolations[0].Field != "card_number" {
+		t.Errorf("BadRequest = %v", badRequest)
+	}
+
+	// Clients recover the typed error from the status
+	if got := apperror.From(err); !errors.Is(got, service.ErrInvalidCardNumber) || got.Fields[0].Field != "card_number" {
+		t.Errorf("From() = %+v", got)
+	}
+}
+
+func TestRESTProblemDetails(t *testing.T) {
+	registry := newLoggingRegistry(t, logging.Discard())
+
+	e := echo.New()
+	e.HTTPErrorHandler = apperror.HTTPErrorHandler
+	e.Use(middleware.RequestID())
+	e.GET("/api/v1/tenant", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, middleware.Tenant(registry))
+
+	tests := []struct {
+		path, tenant string
+		status       int
+		code         apperror.Code
+	}{
+		{"/api/v1/tenant", "acme", http.StatusBadRequest, apperror.CodeUnknownTenant},
+		// Echo's own errors are problem details as well
+		{"/api/v1/missing", "", http.StatusNotFound, apperror.CodeNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("X-Tenant-ID", tt.tenant)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status || rec.Header().Get(echo.HeaderContentType) != apperror.ContentType {
+			t.Errorf("%s: status = %d, content type %q", tt.path, rec.Code, rec.Header().Get(echo.HeaderContentType))
+		}
+		var problem apperror.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err)
+		}
+		if problem.Code != tt.code || problem.Status != tt.status || problem.Type != tt.code.TypeURI() ||
+			problem.Title != http.StatusText(tt.status) || problem.Instance != tt.path {
+			t.Errorf("%s: problem = %+v", tt.path, problem)
+		}
+		if problem.RequestID == "" || problem.RequestID != rec.Header().Get(echo.HeaderXRequestID) {
+			t.Errorf("%s: request_id = %q", tt.path, problem.RequestID)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"credit-card-validator/internal/api/gateway"
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/middleware"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGatewayServer serves the gRPC API through the REST gateway
+func newGatewayServer(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Batch:     config.BatchConfig{MaxItems: 2, Concurrency: 2, StreamConcurrency: 2},
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	overrides := map[string]config.TenantOverride{
+		"retail": {AcceptedCardTypes: []string{"mastercard"}},
+	}
+	registry, err := tenant.NewRegistry(cfg, overrides, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveGateway(t, registry, &cfg.Batch)
+}
+
+// serveGateway serves the gRPC API of registry through the REST gateway
+func serveGateway(t *testing.T, registry *tenant.Registry, batch *config.BatchConfig) *echo.Echo {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcapi.ChannelUnaryInterceptor(audit.ChannelREST), grpcapi.TenantUnaryInterceptor(registry)),
+		grpc.ChainStreamInterceptor(grpcapi.ChannelStreamInterceptor(audit.ChannelREST), grpcapi.TenantStreamInterceptor(registry)),
+	)
+	grpcapi.NewServer(registry, nil, batch, nil, logging.Discard()).RegisterServer(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	handler, err := gateway.NewHandler(context.Background(), conn, registry.Header())
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+
+	e := echo.New()
+	e.Use(middleware.RequestID())
+	e.Use(middleware.Metrics(registry.Header()))
+	gateway.Register(e, handler)
+	return e
+}
+
+func postJSON(e *echo.Echo, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGatewayRoutes(t *testing.T) {
+	want := map[string]string{
+		"/api/v1/validate":        "/cardvalidator.CardValidator/ValidateCard",
+		"/api/v1/validate/batch":  "/cardvalidator.CardValidator/ValidateCards",
+		"/api/v1/validate/stream": "/cardvalidator.CardValidator/ValidateCardStream",
+		"/api/v1/dlp/scan":        "/cardvalidator.CardValidator/ScanText",
+		"/api/v2/validate":        "/cardvalidator.v2.CardValidator/ValidateCard",
+		"/api/v2/validate/batch":  "/cardvalidator.v2.CardValidator/ValidateCards",
+		"/api/v2/validate/stream": "/cardvalidator.v2.CardValidator/ValidateCardStream",
+		"/api/v2/dlp/scan":        "/cardvalidator.v2.CardValidator/ScanText",
+	}
+
+	routes := gateway.Routes()
+	if len(routes) != len(want) {
+		t.Errorf("Routes() returned %d routes; want %d", len(routes), len(want))
+	}
+	for _, route := range routes {
+		if route.Method != http.MethodPost || want[route.Path] != route.FullMethod {
+			t.Errorf("unexpected route %+v", route)
+		}
+	}
+}
+
+func TestGatewayValidate(t *testing.T) {
+	e := newGatewayServer(t)
+
+	rec := postJSON(e, "/api/v1/validate", `{"card_number": "4111 1111 1111 1111"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("v1 status = %d; body %s", rec.Code, rec.Body)
+	}
+	var v1 map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &v1)
+	if v1["valid"] != true || v1["card_type"] != "visa" || v1["card_number"] != "4111111111111111" {
+		t.Errorf("v1 body = %s", rec.Body)
+	}
+
+	rec = postJSON(e, "/api/v2/validate", `{"card_number": "4111111111111111"}`, nil)
+	var v2 map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &v2)
+	if v2["bin"] != "411111" || v2["last_four"] != "1111" || v2["enrichment_status"] != "ENRICHMENT_STATUS_DISABLED" {
+		t.Errorf("v2 body = %s", rec.Body)
+	}
+
+	// The tenant header is forwarded, so the tenant's accepted card types apply
+	rec = postJSON(e, "/api/v2/validate", `{"card_number": "4111111111111111"}`, http.Header{"X-Tenant-Id": {"retail"}})
+	json.Unmarshal(rec.Body.Bytes(), &v2)
+	if v2["valid"] != false || len(v2["issues"].([]interface{})) != 1 {
+		t.Errorf("retail body = %s", rec.Body)
+	}
+}
+
+// The v1 validation routes keep the JSON they returned before the gateway
+const (
+	goldenV1Validate = `{"valid":true,"card_type":"visa","card_number":"4111111111111111","scheme":"visa","card_brand":"Traditional","card_kind":"",` +
+		`"country":{"name":"Netherlands","alpha2":"NL","currency":"EUR","emoji":"🇳🇱","latitude":52.5,"longitude":5.75},` +
+		`"bank":{"name":"Test Bank","url":"www.testbank.example","phone":"+31 20 000 0000"},` +
+		`"bin":"411111","last_four":"1111","enrichment_status":"enriched","enrichment_source":"bin_service"}` + "\n"
+	goldenV1Batch = `{"results":[{"index":0,"result":{"valid":true,"card_type":"visa","card_number":"4111111111111111","scheme":"","card_brand":"","card_kind":"",` +
+		`"country":{"name":"","alpha2":"","currency":"","emoji":"","latitude":0,"longitude":0},"bank":{"name":"","url":"","phone":""},` +
+		`"bin":"411111","last_four":"1111","enrichment_status":"disabled"}},{"index":1,"error":"invalid card number format"}]}` + "\n"
+)
+
+func TestV1ValidateGolden(t *testing.T) {
+	bins := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"scheme":"visa","brand":"Traditional",` +
+			`"country":{"name":"Netherlands","alpha2":"NL","currency":"EUR","emoji":"🇳🇱","latitude":52.5,"longitude":5.75},` +
+			`"bank":{"name":"Test Bank","url":"www.testbank.example","phone":"+31 20 000 0000"}}`))
+	}))
+	t.Cleanup(bins.Close)
+
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Batch:     config.BatchConfig{MaxItems: 2, Concurrency: 2},
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.BINServiceURL = bins.URL
+	registry, err := tenant.NewRegistry(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := serveGateway(t, registry, &cfg.Batch)
+
+	rec := postJSON(e, "/api/v1/validate", `{"card_number": "4111 1111 1111 1111"}`, nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != goldenV1Validate {
+		t.Errorf("v1 validate = %d\n%s\nwant\n%s", rec.Code, rec.Body, goldenV1Validate)
+	}
+
+	// newGatewayServer has BIN lookups disabled
+	rec = postJSON(newGatewayServer(t), "/api/v1/validate/batch", `{"card_numbers": ["4111111111111111", "abc"]}`, nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != goldenV1Batch {
+		t.Errorf("v1 batch = %d\n%s\nwant\n%s", rec.Code, rec.Body, goldenV1Batch)
+	}
+}
+
+func TestGatewayErrors(t *testing.T) {
+	e := newGatewayServer(t)
+
+	tests := []struct {
+		path, body string
+		status     int
+		code       apperror.Code
+		message    string
+	}{
+		{"/api/v1/validate", `{}`, http.StatusBadRequest, apperror.CodeMissingField, "card_number is required"},
+		{"/api/v1/validate", `{"card_number": "abc"}`, http.StatusBadRequest, apperror.CodeInvalidCardNumber, "invalid card number format"},
+		{"/api/v1/validate/batch", `{"card_numbers": ["1", "2", "3"]}`, http.StatusBadRequest, apperror.CodeBatchTooLarge, "batch exceeds 2 items"},
+		{"/api/v2/validate", `{"card_number": "4111111111111111"}`, http.StatusBadRequest, apperror.CodeUnknownTenant, ""},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.message == "" {
+			header.Set("X-Tenant-ID", "unknown")
+		}
+		rec := postJSON(e, tt.path, tt.body, header)
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: status = %d; want %d", tt.path, tt.body, rec.Code, tt.status)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != apperror.ContentType {
+			t.Errorf("%s: content type = %q", tt.path, ct)
+		}
+		var body apperror.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error == "" {
+			t.Errorf("%s: error body = %s", tt.path, rec.Body)
+		}
+		if body.Code != tt.code || body.Status != tt.status || body.Instance != tt.path {
+			t.Errorf("%s: problem = %+v", tt.path, body)
+		}
+		if tt.message != "" && (body.Error != tt.message || body.Detail != tt.message) {
+			t.Errorf("%s: error = %q; want %q", tt.path, body.Error, tt.message)
+		}
+	}
+}
+
+func TestGatewayStream(t *testing.T) {
+	e := newGatewayServer(t)
+
+	body := `{"correlation_id": "a", "card_number": "4111111111111111"}
+{"correlation_id": "b", "card_number": "invalid"}
+`
+	rec := postJSON(e, "/api/v2/validate/stream", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body %s", rec.Code, rec.Body)
+	}
+
+	seen := map[string]bool{}
+	lines := bufio.NewScanner(rec.Body)
+	for lines.Scan() {
+		var msg struct {
+			Result struct {
+				CorrelationID string          `json:"correlation_id"`
+				Result        json.RawMessage `json:"result"`
+				Error         string          `json:"error"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(lines.Bytes(), &msg); err != nil {
+			t.Fatalf("invalid line %s: %v", lines.Text(), err)
+		}
+		id := msg.Result.CorrelationID
+		seen[id] = true
+		if (id == "b") != (msg.Result.Error != "") {
+			t.Errorf("unexpected response %s", lines.Text())
+		}
+	}
+	if !seen["a"] || !seen["b"] {
+		t.Errorf("responses for %v; want a and b", seen)
+	}
+}
+
+func TestGatewayForwardsClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.EnableBINLookup = false
+	registry, err := tenant.NewRegistry(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := auth.NewKeyStore(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, key, err := keys.Create("settlement", "", []auth.Scope{auth.ScopeValidate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLog, err := audit.NewLogger(&config.AuditConfig{Dir: filepath.Join(dir, "audit"), FingerprintKey: "test-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcapi.ChannelUnaryInterceptor(audit.ChannelREST),
+		grpcapi.ForwardedCertUnaryInterceptor(),
+		grpcapi.AuthUnaryInterceptor(keys),
+		grpcapi.TenantUnaryInterceptor(registry),
+	))
+	grpcapi.NewServer(registry, auditLog, &cfg.Batch, nil, logging.Discard()).RegisterServer(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	handler, err := gateway.NewHandler(context.Background(), conn, registry.Header())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	gateway.Register(e, handler)
+
+	validate := func(state *tls.ConnectionState, header http.Header) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/validate", strings.NewReader(`{"card_number":"4111111111111111"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", token)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		req.TLS = state
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+	}
+
+	// The verified certificate of the REST client reaches the gRPC service
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "settlement-client"}}
+	validate(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, nil)
+	// A subject sent by the client is not trusted
+	validate(nil, http.Header{"X-Client-Cert-Subject": {"CN=spoofed"}})
+	auditLog.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "audit", "audit-*.log"))
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var clients []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry audit.Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, entry.Client)
+	}
+	want := []string{key.ID + " (CN=settlement-client)", key.ID}
+	if len(clients) != 2 || clients[0] != want[0] || clients[1] != want[1] {
+		t.Errorf("audited clients = %q; want %q", clients, want)
+	}
+}
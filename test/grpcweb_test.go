@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/api/grpcweb"
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/middleware"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+)
+
+// newGRPCWebServer serves the gRPC API to browsers over HTTP/1.1
+func newGRPCWebServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Batch:     config.BatchConfig{MaxItems: 2, Concurrency: 2, StreamConcurrency: 2},
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	registry, err := tenant.NewRegistry(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcapi.ChannelUnaryInterceptor(audit.ChannelWeb), grpcapi.TenantUnaryInterceptor(registry)),
+		grpc.ChainStreamInterceptor(grpcapi.ChannelStreamInterceptor(audit.ChannelWeb), grpcapi.TenantStreamInterceptor(registry)),
+	)
+	grpcapi.NewServer(registry, nil, &cfg.Batch, nil, logging.Discard()).RegisterServer(server)
+
+	handler, err := grpcweb.NewHandler(server)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+
+	e := echo.New()
+	e.Use(middleware.RequestID())
+	e.Use(middleware.Metrics(registry.Header()))
+	grpcweb.Register(e, handler)
+
+	ts := httptest.NewServer(e)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// grpcWebFrame frames msg as a gRPC-Web data message
+func grpcWebFrame(msg string) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// readGRPCWebFrames splits a gRPC-Web response body into its messages and trailers
+func readGRPCWebFrames(t *testing.T, body []byte) ([]string, string) {
+	t.Helper()
+
+	var messages []string
+	var trailers string
+	for len(body) > 0 {
+		if len(body) < 5 {
+			t.Fatalf("truncated frame %q", body)
+		}
+		size := binary.BigEndian.Uint32(body[1:5])
+		payload := string(body[5 : 5+size])
+		if body[0]&0x80 != 0 {
+			trailers = payload
+		} else {
+			messages = append(messages, payload)
+		}
+		body = body[5+size:]
+	}
+	return messages, trailers
+}
+
+// postConnect makes a Connect unary call with a JSON body
+func postConnect(url, body string) (*http.Response, error) {
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connect-Protocol-Version", "1")
+	return http.DefaultClient.Do(req)
+}
+
+func TestConnectValidate(t *testing.T) {
+	ts := newGRPCWebServer(t)
+
+	res, err := postConnect(ts.URL+"/cardvalidator.v2.CardValidator/ValidateCard", `{"card_number": "4111 1111 1111 1111"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("status = %d; body %s", res.StatusCode, body)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body["valid"] != true || body["card_type"] != "visa" || body["last_four"] != "1111" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestConnectError(t *testing.T) {
+	ts := newGRPCWebServer(t)
+
+	res, err := postConnect(ts.URL+"/cardvalidator.CardValidator/ValidateCard", `{"card_number": "abc"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	json.NewDecoder(res.Body).Decode(&body)
+	if res.StatusCode != http.StatusBadRequest || body.Code != "invalid_argument" || body.Message != "invalid card number format" {
+		t.Errorf("status = %d, body = %+v", res.StatusCode, body)
+	}
+}
+
+func TestGRPCWebValidate(t *testing.T) {
+	ts := newGRPCWebServer(t)
+
+	tests := []struct {
+		contentType string
+		request     []byte
+		status      string
+		wantMessage bool
+	}{
+		{"application/grpc-web+json", grpcWebFrame(`{"card_number": "5555555555554444"}`), "grpc-status: 0", true},
+		// proto encoding of card_number "4111111111111111"
+		{"application/grpc-web+proto", grpcWebFrame("\x0a\x104111111111111111"), "grpc-status: 0", true},
+		{"application/grpc-web+json", grpcWebFrame(`{}`), "grpc-status: 3", false},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/cardvalidator.CardValidator/ValidateCard", bytes.NewReader(tt.request))
+		req.Header.Set("Content-Type", tt.contentType)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != tt.contentType {
+			t.Fatalf("%s: status = %d, content type %q", tt.contentType, res.StatusCode, res.Header.Get("Content-Type"))
+		}
+		// Calls failing before a response is sent carry the status as headers
+		messages, trailers := readGRPCWebFrames(t, body)
+		if trailers == "" {
+			trailers = "grpc-status: " + res.Header.Get("Grpc-Status")
+		}
+		if !strings.Contains(strings.ToLower(trailers), tt.status) {
+			t.Errorf("%s: trailers = %q; want %s", tt.contentType, trailers, tt.status)
+		}
+		if (len(messages) == 1) != tt.wantMessage {
+			t.Errorf("%s: messages = %q", tt.contentType, messages)
+		}
+		if tt.contentType == "application/grpc-web+json" && tt.wantMessage {
+			var result map[string]interface{}
+			json.Unmarshal([]byte(messages[0]), &result)
+			if result["valid"] != true || result["card_type"] != "mastercard" {
+				t.Errorf("result = %s", messages[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/health"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	pb "credit-card-validator/pkg/proto"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newHealthRegistry returns tenants whose BIN service is binURL, plus a
+// tenant without BIN lookup
+func newHealthRegistry(t *testing.T, binURL string) *tenant.Registry {
+	t.Helper()
+
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.BINServiceURL = binURL
+
+	disabled := false
+	registry, err := tenant.NewRegistry(cfg, map[string]config.TenantOverride{
+		"offline": {EnableBINLookup: &disabled},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func newChecker() *health.Checker {
+	return health.NewChecker(&config.HealthConfig{CheckInterval: time.Hour, CheckTimeout: time.Second}, logging.Discard())
+}
+
+// readyz returns the status code and report of GET /readyz
+func readyz(t *testing.T, checker *health.Checker) (int, health.Report) {
+	t.Helper()
+
+	e := echo.New()
+	e.GET("/readyz", checker.ReadyzHandler())
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report health.Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, report
+}
+
+func TestReadinessChecks(t *testing.T) {
+	var failing atomic.Bool
+	checker := newChecker()
+	checker.Add("store", func(ctx context.Context) error {
+		if failing.Load() {
+			return errors.New("store unavailable")
+		}
+		return nil
+	})
+
+	// Checks are pending until they first run
+	if code, report := readyz(t, checker); code != http.StatusServiceUnavailable || report.Checks["store"] != health.CheckPending {
+		t.Errorf("before first run: %d %+v", code, report)
+	}
+
+	checker.Refresh(context.Background())
+	if code, report := readyz(t, checker); code != http.StatusOK || report.Status != health.StatusReady {
+		t.Errorf("healthy: %d %+v", code, report)
+	}
+
+	failing.Store(true)
+	checker.Refresh(context.Background())
+	code, report := readyz(t, checker)
+	if code != http.StatusServiceUnavailable || report.Checks["store"] != "store unavailable" {
+		t.Errorf("unhealthy: %d %+v", code, report)
+	}
+	if report.Checks[health.ShutdownCheck] != health.CheckOK {
+		t.Errorf("shutdown = %q", report.Checks[health.ShutdownCheck])
+	}
+}
+
+func TestReadinessBINServiceDegraded(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	bin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("check used %s", r.Method)
+		}
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer bin.Close()
+
+	checker := newChecker()
+	checker.AddOptional("bin_service", newHealthRegistry(t, bin.URL).CheckBINServices)
+
+	// Optional checks do not hold readiness back
+	if code, report := readyz(t, checker); code != http.StatusOK || report.Checks["bin_service"] != health.CheckPending {
+		t.Errorf("before first run: %d %+v", code, report)
+	}
+
+	checker.Refresh(context.Background())
+	if code, report := readyz(t, checker); code != http.StatusOK || report.Checks["bin_service"] != health.CheckOK {
+		t.Errorf("healthy: %d %+v", code, report)
+	}
+
+	// Validation keeps working without issuer details
+	healthy.Store(false)
+	checker.Refresh(context.Background())
+	code, report := readyz(t, checker)
+	if code != http.StatusOK || report.Status != health.StatusReady {
+		t.Errorf("unreachable: %d %+v", code, report)
+	}
+	if got := report.Checks["bin_service"]; !strings.HasPrefix(got, health.CheckDegraded+": ") || !strings.Contains(got, "status 503") {
+		t.Errorf("bin_service = %q; want degraded", got)
+	}
+}
+
+func TestReadinessDraining(t *testing.T) {
+	checker := newChecker()
+	checker.Refresh(context.Background())
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcapi.TenantUnaryInterceptor(newHealthRegistry(t, "http://127.0.0.1:0"))),
+	)
+	checker.RegisterGRPC(server, pb.CardValidator_ServiceDesc.ServiceName)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	// Health checks are answered outside of any tenant
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-tenant-id", "unknown")
+	status := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q) error = %v", service, err)
+		}
+		return res.Status
+	}
+
+	if s := status(pb.CardValidator_ServiceDesc.ServiceName); s != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("before draining: %v", s)
+	}
+
+	checker.Drain()
+	if code, report := readyz(t, checker); code != http.StatusServiceUnavailable || report.Checks[health.ShutdownCheck] != health.CheckDraining {
+		t.Errorf("draining: %d %+v", code, report)
+	}
+	for _, service := range []string{"", pb.CardValidator_ServiceDesc.ServiceName} {
+		if s := status(service); s != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("draining %q: %v", service, s)
+		}
+	}
+
+	// Later check results do not make a draining server ready again
+	checker.Refresh(context.Background())
+	if checker.Ready() || status("") != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Error("ready again after refresh")
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	pb "credit-card-validator/pkg/proto"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newObservedClient serves the gRPC API behind the request ID, logger,
+// observe and recovery interceptors. Calls to unknown services panic.
+func newObservedClient(t *testing.T) (*grpc.ClientConn, *logtest.Hook) {
+	t.Helper()
+
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	registry, err := tenant.NewRegistry(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger, hook := logtest.NewNullLogger()
+	base := logging.FromLogrus(logger)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcapi.RequestIDUnaryInterceptor(),
+			grpcapi.LoggerUnaryInterceptor(base),
+			grpcapi.ObserveUnaryInterceptor(base),
+			grpcapi.RecoveryUnaryInterceptor(base),
+			grpcapi.TenantUnaryInterceptor(registry),
+		),
+		grpc.ChainStreamInterceptor(
+			grpcapi.RequestIDStreamInterceptor(),
+			grpcapi.LoggerStreamInterceptor(base),
+			grpcapi.ObserveStreamInterceptor(base),
+			grpcapi.RecoveryStreamInterceptor(base),
+		),
+		grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			panic("boom")
+		}),
+	)
+	grpcapi.NewServer(registry, nil, &cfg.Batch, nil, base).RegisterServer(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, hook
+}
+
+// accessLog returns the access log entry of the last call
+func accessLog(t *testing.T, hook *logtest.Hook) *logrus.Entry {
+	t.Helper()
+
+	for i := len(hook.AllEntries()) - 1; i >= 0; i-- {
+		if entry := hook.AllEntries()[i]; entry.Message == "gRPC call" {
+			return entry
+		}
+	}
+	t.Fatal("no access log entry")
+	return nil
+}
+
+func TestGRPCRequestID(t *testing.T) {
+	conn, hook := newObservedClient(t)
+	client := pb.NewCardValidatorClient(conn)
+	req := &pb.ValidateCardRequest{CardNumber: "4111111111111111"}
+
+	// The client's request ID is kept and returned
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-123")
+	if _, err := client.ValidateCard(ctx, req, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-123" {
+		t.Errorf("x-request-id header = %v", got)
+	}
+	if entry := accessLog(t, hook); entry.Data["request_id"] != "req-123" || entry.Data["code"] != "OK" ||
+		entry.Data["method"] != pb.CardValidator_ValidateCard_FullMethodName || entry.Data["channel"] != "grpc" {
+		t.Errorf("access log = %v", entry.Data)
+	}
+
+	// The service logs carry the request ID as well
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "gRPC ValidateCard called" && entry.Data["request_id"] != "req-123" {
+			t.Errorf("service log request_id = %v", entry.Data["request_id"])
+		}
+	}
+
+	// Otherwise one is generated
+	if _, err := client.ValidateCard(context.Background(), req, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || len(got[0]) != 32 {
+		t.Errorf("generated x-request-id = %v", got)
+	}
+}
+
+func TestGRPCAccessLogErrors(t *testing.T) {
+	conn, hook := newObservedClient(t)
+
+	before := counterValue(t, "card_validation_grpc_requests_total", map[string]string{
+		"method": pb.CardValidator_ValidateCard_FullMethodName, "code": "InvalidArgument", "channel": "grpc",
+	})
+
+	_, err := pb.NewCardValidatorClient(conn).ValidateCard(context.Background(), &pb.ValidateCardRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("error = %v", err)
+	}
+
+	entry := accessLog(t, hook)
+	if entry.Level != logrus.WarnLevel || entry.Data["code"] != "InvalidArgument" || entry.Data["error"] != "card_number is required" {
+		t.Errorf("access log = %v %v", entry.Level, entry.Data)
+	}
+	after := counterValue(t, "card_validation_grpc_requests_total", map[string]string{
+		"method": pb.CardValidator_ValidateCard_FullMethodName, "code": "InvalidArgument", "channel": "grpc",
+	})
+	if after != before+1 {
+		t.Errorf("requests counter = %v; want %v", after, before+1)
+	}
+}
+
+func TestGRPCRecovery(t *testing.T) {
+	conn, hook := newObservedClient(t)
+	method := "/test.Panics/Boom"
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-panic")
+	err := conn.Invoke(ctx, method, &emptypb.Empty{}, &emptypb.Empty{})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "internal error" {
+		t.Fatalf("error = %v; want Internal without the panic value", err)
+	}
+
+	if counterValue(t, "card_validation_grpc_panics_total", map[string]string{"method": method}) != 1 {
+		t.Error("panic not counted")
+	}
+	if entry := accessLog(t, hook); entry.Level != logrus.ErrorLevel || entry.Data["code"] != "Internal" {
+		t.Errorf("access log = %v %v", entry.Level, entry.Data)
+	}
+
+	// The panic is logged with the request logger of the call
+	var logged bool
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "Recovered from panic in gRPC handler" {
+			logged = true
+			if entry.Data["request_id"] != "req-panic" || entry.Data["channel"] != "grpc" || entry.Data["panic"] != "boom" {
+				t.Errorf("panic log = %v", entry.Data)
+			}
+		}
+	}
+	if !logged {
+		t.Error("panic not logged")
+	}
+
+	// The server keeps serving
+	if _, err := pb.NewCardValidatorClient(conn).ValidateCard(context.Background(), &pb.ValidateCardRequest{CardNumber: "4111111111111111"}); err != nil {
+		t.Errorf("ValidateCard() after panic error = %v", err)
+	}
+
+	// Unary handlers are recovered the same way
+	_, err = grpcapi.RecoveryUnaryInterceptor(logging.Discard())(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("unary boom")
+		})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("unary error = %v", err)
+	}
+}
@@ -0,0 +1,446 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"credit-card-validator/internal/api/rest"
+	"credit-card-validator/internal/apperror"
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/jobs"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newJobManager(t *testing.T, dir string, auditLog *audit.Logger) (*jobs.Manager, *tenant.Registry) {
+	t.Helper()
+
+	validator := *service.DefaultConfig()
+	validator.EnableBINLookup = false
+	base := &config.Config{
+		Validator: validator,
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	overrides := map[string]config.TenantOverride{"retail": {}}
+	registry, err := tenant.NewRegistry(base, overrides, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := jobs.NewManager(&config.JobsConfig{Dir: dir, Workers: 2, QueueSize: 10}, registry, auditLog, nil)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	return manager, registry
+}
+
+func waitForJob(t *testing.T, manager *jobs.Manager, ctx context.Context, id string) *jobs.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := manager.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if job.Status.Finished() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
+
+func TestJobValidatesCSVAndMasksResults(t *testing.T) {
+	manager, registry := newJobManager(t, t.TempDir(), nil)
+	runCtx, stop := context.WithCancel(context.Background())
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stop()
+		manager.Close()
+	}()
+
+	retail, _ := registry.Get("retail")
+	ctx := tenant.NewContext(context.Background(), retail)
+
+	input := "id,card_number\nA1,4111 1111 1111 1111\nA2,4111111111111112\nA3,abc\n"
+	job, err := manager.Submit(ctx, "cards.csv", jobs.FormatCSV, strings.NewReader(input), false)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	job = waitForJob(t, manager, ctx, job.ID)
+	if job.Status != jobs.StatusCompleted {
+		t.Fatalf("Status = %s (%s); want completed", job.Status, job.Error)
+	}
+	if job.Total != 3 || job.Processed != 3 || job.Valid != 1 || job.Invalid != 1 || job.Errors != 1 {
+		t.Errorf("counters = %+v", job)
+	}
+
+	// Jobs of other tenants are hidden
+	if _, err := manager.Get(context.Background(), job.ID); !errors.Is(err, jobs.ErrJobNotFound) {
+		t.Errorf("Get() from default tenant error = %v; want %v", err, jobs.ErrJobNotFound)
+	}
+
+	var out bytes.Buffer
+	if err := manager.WriteResults(ctx, job.ID, jobs.FormatNDJSON, &out); err != nil {
+		t.Fatalf("WriteResults() error = %v", err)
+	}
+	if strings.Contains(out.String(), "4111111111111111") {
+		t.Fatalf("results contain a cleartext PAN: %s", out.String())
+	}
+
+	var records []jobs.Record
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var record jobs.Record
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records; want 3", len(records))
+	}
+	if records[0].Reference != "A1" || !records[0].Valid || records[0].MaskedPAN != "4111********1111" {
+		t.Errorf("records[0] = %+v", records[0])
+	}
+	if records[2].Error == "" {
+		t.Errorf("records[2] = %+v; want an error", records[2])
+	}
+
+	out.Reset()
+	if err := manager.WriteResults(ctx, job.ID, jobs.FormatCSV, &out); err != nil {
+		t.Fatalf("WriteResults(csv) error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "line,reference,masked_pan,valid") {
+		t.Errorf("unexpected CSV results: %s", out.String())
+	}
+}
+
+func TestJobsSurviveRestartAndCancel(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Submit without running workers, as if the process stopped
+	manager, _ := newJobManager(t, dir, nil)
+	first, err := manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(`{"card_number":"5555555555554444"}`+"\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := manager.Submit(ctx, "more.ndjson", jobs.FormatNDJSON, strings.NewReader(`{"pan":"378282246310005"}`+"\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.Cancel(ctx, second.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if err := manager.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, _ = newJobManager(t, dir, nil)
+	runCtx, stop := context.WithCancel(ctx)
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stop()
+		manager.Close()
+	}()
+
+	job := waitForJob(t, manager, ctx, first.ID)
+	if job.Status != jobs.StatusCompleted || job.Valid != 1 {
+		t.Errorf("restored job = %+v; want completed with one valid card", job)
+	}
+
+	canceled, err := manager.Get(ctx, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canceled.Status != jobs.StatusCanceled {
+		t.Errorf("canceled job status = %s", canceled.Status)
+	}
+	if _, err := manager.Cancel(ctx, second.ID); !errors.Is(err, jobs.ErrJobFinished) {
+		t.Errorf("second Cancel() error = %v; want %v", err, jobs.ErrJobFinished)
+	}
+	if err := manager.WriteResults(ctx, second.ID, jobs.FormatCSV, &bytes.Buffer{}); err == nil {
+		t.Error("WriteResults() for a job canceled before running should fail")
+	}
+}
+
+func TestJobInputRemovedWhenFinished(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	inputPath := func(id string) string { return filepath.Join(dir, id, "input") }
+	submit := func(manager *jobs.Manager, ctx context.Context) *jobs.Job {
+		t.Helper()
+		job, err := manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(`{"card_number":"4111111111111111"}`+"\n"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(inputPath(job.ID)); err != nil {
+			t.Fatalf("input not stored: %v", err)
+		}
+		return job
+	}
+
+	// Canceled while queued
+	manager, _ := newJobManager(t, dir, nil)
+	canceled := submit(manager, ctx)
+	if _, err := manager.Cancel(ctx, canceled.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stop()
+		manager.Close()
+	}()
+
+	completed := submit(manager, ctx)
+
+	// Jobs of a tenant missing from the registry fail
+	others, err := tenant.NewRegistry(&config.Config{Validator: *service.DefaultConfig()}, map[string]config.TenantOverride{"removed": {}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, _ := others.Get("removed")
+	removedCtx := tenant.NewContext(ctx, removed)
+	failed := submit(manager, removedCtx)
+
+	for _, tt := range []struct {
+		ctx    context.Context
+		job    *jobs.Job
+		status jobs.Status
+	}{
+		{ctx, canceled, jobs.StatusCanceled},
+		{ctx, completed, jobs.StatusCompleted},
+		{removedCtx, failed, jobs.StatusFailed},
+	} {
+		if job := waitForJob(t, manager, tt.ctx, tt.job.ID); job.Status != tt.status {
+			t.Errorf("status = %s; want %s", job.Status, tt.status)
+		}
+		if _, err := os.Stat(inputPath(tt.job.ID)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s job: input still on disk (%v)", tt.status, err)
+		}
+	}
+}
+
+func TestJobResultsOverREST(t *testing.T) {
+	manager, registry := newJobManager(t, t.TempDir(), nil)
+	ctx := context.Background()
+
+	canceled, err := manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(`{"card_number":"4111111111111111"}`+"\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Cancel(ctx, canceled.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stop()
+		manager.Close()
+	}()
+	completed, err := manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(`{"card_number":"4111111111111111"}`+"\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForJob(t, manager, ctx, completed.ID)
+
+	e := echo.New()
+	rest.NewHandler(registry, manager, &config.JobsConfig{MaxUploadSize: 1 << 20}, nil, logging.Discard()).RegisterRoutes(e, nil)
+
+	tests := []struct {
+		id     string
+		status int
+		code   apperror.Code
+	}{
+		// Jobs canceled before running have no results to send
+		{canceled.ID, http.StatusNotFound, apperror.CodeJobResultsNotFound},
+		{completed.ID, http.StatusOK, ""},
+		{strings.Repeat("0", 32), http.StatusNotFound, apperror.CodeJobNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+tt.id+"/results", nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d; want %d", tt.id, rec.Code, tt.status)
+		}
+		if tt.code == "" {
+			if !strings.Contains(rec.Body.String(), `"masked_pan":"4111********1111"`) {
+				t.Errorf("results = %s", rec.Body)
+			}
+			continue
+		}
+		var problem apperror.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil || problem.Code != tt.code {
+			t.Errorf("%s: body = %s", tt.id, rec.Body)
+		}
+	}
+}
+
+func TestJobFailsWhenAuditUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	auditLog, err := audit.NewLogger(&config.AuditConfig{Dir: filepath.Join(dir, "audit"), FingerprintKey: "test-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLog.Close()
+
+	manager, _ := newJobManager(t, filepath.Join(dir, "jobs"), auditLog)
+	runCtx, stop := context.WithCancel(context.Background())
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stop()
+		manager.Close()
+	}()
+
+	ctx := context.Background()
+	job, err := manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(`{"card_number":"abc"}`+"\n"+`{"card_number":"4111111111111111"}`+"\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Results are not written for validations that could not be audited
+	job = waitForJob(t, manager, ctx, job.ID)
+	if job.Status != jobs.StatusFailed || job.Error != "audit log unavailable: "+audit.ErrLogClosed.Error() {
+		t.Fatalf("job = %s (%s); want failed", job.Status, job.Error)
+	}
+
+	// Rows processed before the failure are kept
+	var out bytes.Buffer
+	if err := manager.WriteResults(ctx, job.ID, jobs.FormatNDJSON, &out); err != nil {
+		t.Fatalf("WriteResults() error = %v", err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 1 || strings.Contains(out.String(), "1111") {
+		t.Errorf("results = %s", out.String())
+	}
+}
+
+func TestJobRowsChargedToTenantRateLimit(t *testing.T) {
+	validator := *service.DefaultConfig()
+	validator.EnableBINLookup = false
+	limit, burst := 0.001, 3
+	base := &config.Config{
+		Validator: validator,
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	registry, err := tenant.NewRegistry(base, map[string]config.TenantOverride{
+		"limited": {RateLimit: &limit, RateBurst: &burst},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limited, _ := registry.Get("limited")
+
+	manager, err := jobs.NewManager(&config.JobsConfig{Dir: t.TempDir(), Workers: 1, QueueSize: 10}, registry, nil, nil)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	runCtx, stop := context.WithCancel(context.Background())
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stop()
+		manager.Close()
+	}()
+
+	ctx := tenant.NewContext(context.Background(), limited)
+	rows := strings.Repeat(`{"card_number":"4111111111111111"}`+"\n", 3)
+	job, err := manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(rows), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job = waitForJob(t, manager, ctx, job.ID); job.Status != jobs.StatusCompleted {
+		t.Fatalf("status = %s; want %s", job.Status, jobs.StatusCompleted)
+	}
+
+	// Every row was charged, so the tenant is out of validations
+	if limited.Allow() {
+		t.Error("tenant is still within its rate limit after the job")
+	}
+
+	// Later rows wait for the limit instead of being validated
+	job, err = manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(rows), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); job.Status == jobs.StatusQueued && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		if job, err = manager.Get(ctx, job.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	if job, err = manager.Get(ctx, job.ID); err != nil || job.Status != jobs.StatusRunning || job.Processed != 0 {
+		t.Fatalf("job = %+v, %v; want running with no rows processed", job, err)
+	}
+	if _, err := manager.Cancel(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job = waitForJob(t, manager, ctx, job.ID); job.Status != jobs.StatusCanceled {
+		t.Errorf("status = %s; want %s", job.Status, jobs.StatusCanceled)
+	}
+}
+
+func TestJobCanceledRightAfterSubmit(t *testing.T) {
+	manager, _ := newJobManager(t, t.TempDir(), nil)
+	runCtx, stop := context.WithCancel(context.Background())
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stop()
+		manager.Close()
+	}()
+
+	// A job whose cancellation was accepted never completes, whether the
+	// worker picked it up before or after the cancellation. Jobs canceled
+	// while queued stay in the queue until a worker takes them, so fewer are
+	// submitted than the queue holds.
+	ctx := context.Background()
+	rows := strings.Repeat(`{"card_number":"4111111111111111"}`+"\n", 2000)
+	for i := 0; i < 8; i++ {
+		job, err := manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(rows), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := manager.Cancel(ctx, job.ID); errors.Is(err, jobs.ErrJobFinished) {
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if job = waitForJob(t, manager, ctx, job.ID); job.Status != jobs.StatusCanceled {
+			t.Fatalf("job %d: status = %s; want %s", i, job.Status, jobs.StatusCanceled)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeJWKS writes a JWKS file holding the public part of key
+func writeJWKS(t *testing.T, path, kid string, key *rsa.PrivateKey) {
+	t.Helper()
+
+	set := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, "test-key", key)
+
+	jwks, err := auth.NewJWKS(context.Background(), path, "", time.Minute, nil)
+	if err != nil {
+		t.Fatalf("NewJWKS() error = %v", err)
+	}
+
+	verifier, err := auth.NewJWTVerifier(jwks, &config.AuthConfig{
+		JWTIssuer:       "https://issuer.internal",
+		JWTAudience:     "card-validator",
+		JWTClockSkew:    30 * time.Second,
+		JWTScopeClaim:   "scope",
+		JWTScopeMapping: "cards.validate = validate, cards.admin= admin",
+		JWTTenantClaim:  "tenant",
+	})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+
+	sign := func(signer *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":    "settlement-job",
+			"iss":    "https://issuer.internal",
+			"aud":    "card-validator",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"scope":  "cards.validate bin:read unknown",
+			"tenant": "retail",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	identity, err := verifier.Authenticate(context.Background(), auth.Credentials{
+		BearerToken: sign(key, "test-key", claims(nil)),
+	})
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if identity.Subject != "settlement-job" || identity.Method != "jwt" || identity.Tenant != "retail" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	if !identity.HasScope(auth.ScopeValidate) || !identity.HasScope(auth.ScopeBINRead) || identity.HasScope(auth.ScopeAdmin) {
+		t.Errorf("unexpected scopes %v", identity.Scopes)
+	}
+
+	// Expired within the clock skew tolerance is still accepted
+	if _, err := verifier.Authenticate(context.Background(), auth.Credentials{
+		BearerToken: sign(key, "test-key", claims(jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()})),
+	}); err != nil {
+		t.Errorf("Authenticate() within clock skew error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Expired", token: sign(key, "test-key", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))},
+		{name: "Wrong audience", token: sign(key, "test-key", claims(jwt.MapClaims{"aud": "other"}))},
+		{name: "Wrong issuer", token: sign(key, "test-key", claims(jwt.MapClaims{"iss": "https://evil"}))},
+		{name: "Missing audience", token: sign(key, "test-key", claims(jwt.MapClaims{"aud": nil}))},
+		{name: "Missing issuer", token: sign(key, "test-key", claims(jwt.MapClaims{"iss": nil}))},
+		{name: "Unknown signer", token: sign(otherKey, "test-key", claims(nil))},
+		{name: "Unknown key ID", token: sign(key, "rotated", claims(nil))},
+		{name: "Malformed", token: "not.a.jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Authenticate(context.Background(), auth.Credentials{BearerToken: tt.token})
+			if !errors.Is(err, auth.ErrInvalidCredentials) {
+				t.Errorf("Authenticate() error = %v; want %v", err, auth.ErrInvalidCredentials)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifierConfig(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, "test-key", key)
+
+	jwks, err := auth.NewJWKS(context.Background(), path, "", time.Minute, nil)
+	if err != nil {
+		t.Fatalf("NewJWKS() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		config config.AuthConfig
+	}{
+		{name: "Missing issuer", config: config.AuthConfig{JWTAudience: "card-validator"}},
+		{name: "Missing audience", config: config.AuthConfig{JWTIssuer: "https://issuer.internal"}},
+		{name: "Unknown scope", config: config.AuthConfig{JWTIssuer: "https://issuer.internal", JWTAudience: "card-validator", JWTScopeMapping: "cards.validate=validate ,cards.all=root"}},
+		{name: "Missing claim", config: config.AuthConfig{JWTIssuer: "https://issuer.internal", JWTAudience: "card-validator", JWTScopeMapping: " =validate"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := auth.NewJWTVerifier(jwks, &tt.config); err == nil {
+				t.Error("NewJWTVerifier() succeeded; want error")
+			}
+		})
+	}
+}
+
+func TestJWKSReloadFailure(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, "test-key", key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests, failing atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() == 1 {
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	jwks, err := auth.NewJWKS(context.Background(), "", server.URL, time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("NewJWKS() error = %v", err)
+	}
+	failing.Store(1)
+	time.Sleep(5 * time.Millisecond)
+
+	// Concurrent lookups of stale keys share one reload
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwks.Key(context.Background(), "test-key"); err != nil {
+				t.Errorf("Key() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The failed reload is not retried right away, for known or unknown keys
+	if _, err := jwks.Key(context.Background(), "test-key"); err != nil {
+		t.Errorf("Key() error = %v", err)
+	}
+	if _, err := jwks.Key(context.Background(), "rotated"); !errors.Is(err, auth.ErrKeyNotInJWKS) {
+		t.Errorf("Key() error = %v; want %v", err, auth.ErrKeyNotInJWKS)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("JWKS fetched %d times; want 2", got)
+	}
+}
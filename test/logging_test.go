@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/jobs"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/middleware"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	pb "credit-card-validator/pkg/proto"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newSlogLogger returns a logger writing JSON lines to the returned buffer
+func newSlogLogger() (logging.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return logging.FromSlog(slog.New(slog.NewJSONHandler(&buf, nil))), &buf
+}
+
+// logEntry returns the last JSON log line of buf with message msg
+func logEntry(t *testing.T, buf *bytes.Buffer, msg string) map[string]any {
+	t.Helper()
+
+	var found map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var entry map[string]any
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if entry["msg"] == msg {
+			found = entry
+		}
+	}
+	if found == nil {
+		t.Fatalf("no %q log entry in %s", msg, buf)
+	}
+	return found
+}
+
+// newLoggingRegistry returns tenants without BIN lookup, including "retail"
+func newLoggingRegistry(t *testing.T, logger logging.Logger) *tenant.Registry {
+	t.Helper()
+
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	registry, err := tenant.NewRegistry(cfg, map[string]config.TenantOverride{"retail": {}}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestValidatorSlogLogger(t *testing.T) {
+	logger, buf := newSlogLogger()
+
+	validator, err := service.NewTenantValidator("retail", nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := validator.ValidateCard(context.Background(), "4111111111111111"); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := logEntry(t, buf, "Card validation completed")
+	if entry["tenant"] != "retail" || entry["card_type"] != "visa" || entry["valid"] != true || entry["card_number"] != "4111********1111" {
+		t.Errorf("log entry = %v", entry)
+	}
+}
+
+func TestValidatorContextLogger(t *testing.T) {
+	fallback, fallbackBuf := newSlogLogger()
+	requestLogger, buf := newSlogLogger()
+
+	validator, err := service.NewValidator(&config.ValidatorConfig{}, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := logging.NewContext(context.Background(), requestLogger.With(logging.FieldRequestID, "req-1"))
+	if _, err := validator.ValidateCard(ctx, "4111111111111111"); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry := logEntry(t, buf, "Card validation completed"); entry["request_id"] != "req-1" {
+		t.Errorf("log entry = %v", entry)
+	}
+	if fallbackBuf.Len() != 0 {
+		t.Errorf("fallback logger used: %s", fallbackBuf)
+	}
+
+	// Adding fields to a context without a logger changes nothing
+	if plain := logging.With(context.Background(), logging.FieldTenant, "retail"); plain != context.Background() {
+		t.Error("With() without a logger returned a new context")
+	}
+}
+
+func TestJobContextLogger(t *testing.T) {
+	logger, buf := newSlogLogger()
+	registry := newLoggingRegistry(t, logging.Discard())
+
+	manager, err := jobs.NewManager(&config.JobsConfig{Dir: t.TempDir(), Workers: 1, QueueSize: 10}, registry, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runCtx, stop := context.WithCancel(context.Background())
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	retail, _ := registry.Get("retail")
+	ctx := tenant.NewContext(context.Background(), retail)
+	job, err := manager.Submit(ctx, "cards.ndjson", jobs.FormatNDJSON, strings.NewReader(`{"card_number":"4111111111111111"}`+"\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForJob(t, manager, ctx, job.ID)
+	stop()
+	if err := manager.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The validator logs with the job logger carried by the job context
+	for _, msg := range []string{"Job started", "Card validation completed", "Job finished"} {
+		if entry := logEntry(t, buf, msg); entry["job_id"] != job.ID || entry["tenant"] != "retail" {
+			t.Errorf("%s log entry = %v", msg, entry)
+		}
+	}
+}
+
+func TestRESTContextLogger(t *testing.T) {
+	store, err := auth.NewKeyStore(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, key, err := store.Create("settlement", "retail", []auth.Scope{auth.ScopeValidate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger, buf := newSlogLogger()
+	registry := newLoggingRegistry(t, logging.Discard())
+
+	e := echo.New()
+	e.Use(middleware.RequestID())
+	e.Use(middleware.ContextLogger(logger))
+	api := e.Group("/api/v1", middleware.Authenticate(store), middleware.Tenant(registry))
+	api.POST("/check", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		if _, err := registry.Validator(ctx).ValidateCard(ctx, "4111111111111111"); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/check", nil)
+	req.Header.Set("X-API-Key", token)
+	req.Header.Set(echo.HeaderXRequestID, "req-rest")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	entry := logEntry(t, buf, "Card validation completed")
+	if entry["request_id"] != "req-rest" || entry["method"] != "POST /api/v1/check" ||
+		entry["client"] != key.ID || entry["tenant"] != "retail" {
+		t.Errorf("log entry = %v", entry)
+	}
+}
+
+func TestGRPCContextLogger(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	requestLogger := logging.FromLogrus(logger)
+	registry := newLoggingRegistry(t, logging.Discard())
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcapi.RequestIDUnaryInterceptor(),
+			grpcapi.LoggerUnaryInterceptor(requestLogger),
+			grpcapi.TenantUnaryInterceptor(registry),
+		),
+	)
+	grpcapi.NewServer(registry, nil, &config.BatchConfig{MaxItems: 10, Concurrency: 1}, nil, requestLogger).RegisterServer(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-grpc", "x-tenant-id", "retail")
+	if _, err := pb.NewCardValidatorClient(conn).ValidateCard(ctx, &pb.ValidateCardRequest{CardNumber: "4111111111111111"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server and the validator log with the same request fields
+	for _, msg := range []string{"gRPC ValidateCard called", "Card validation completed"} {
+		var entry *logrus.Entry
+		for _, e := range hook.AllEntries() {
+			if e.Message == msg {
+				entry = e
+			}
+		}
+		if entry == nil {
+			t.Fatalf("no %q log entry", msg)
+		}
+		if entry.Data["request_id"] != "req-grpc" || entry.Data["tenant"] != "retail" ||
+			entry.Data["method"] != pb.CardValidator_ValidateCard_FullMethodName || entry.Data["channel"] != "grpc" {
+			t.Errorf("%q fields = %v", msg, entry.Data)
+		}
+	}
+}
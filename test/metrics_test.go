@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// histogramCount returns the number of observations of the histogram
+// matching labels
+func histogramCount(t *testing.T, name string, labels map[string]string) uint64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok && value != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
+
+func TestValidationMetrics(t *testing.T) {
+	validator, err := service.NewTenantValidator("metrics", &config.ValidatorConfig{
+		AcceptedCardTypes: []string{"visa", "mastercard"},
+	}, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, card := range []string{"4111111111111111", "4111111111111112", "378282246310005"} {
+		if _, err := validator.ValidateCard(context.Background(), card); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := validator.ValidateCardSimple("5555555555554444"); err != nil {
+		t.Fatal(err)
+	}
+	validator.ValidateCardSimple("card")
+	validator.ValidateCard(context.Background(), "4111 1111")
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"card_validation_validations_total", map[string]string{"tenant": "metrics", "card_type": "visa", "valid": "true"}, 1},
+		{"card_validation_validations_total", map[string]string{"tenant": "metrics", "card_type": "visa", "valid": "false"}, 1},
+		{"card_validation_validations_total", map[string]string{"tenant": "metrics", "card_type": "amex", "valid": "false"}, 1},
+		{"card_validation_validations_total", map[string]string{"tenant": "metrics", "card_type": "mastercard", "valid": "true"}, 1},
+		{"card_validation_issues_total", map[string]string{"tenant": "metrics", "card_type": "visa", "issue": "none"}, 1},
+		{"card_validation_issues_total", map[string]string{"tenant": "metrics", "card_type": "visa", "issue": "luhn_check_failed"}, 1},
+		{"card_validation_issues_total", map[string]string{"tenant": "metrics", "card_type": "amex", "issue": "card_type_not_accepted"}, 1},
+		{"card_validation_rejected_inputs_total", map[string]string{"tenant": "metrics", "reason": "no_digits"}, 1},
+		{"card_validation_rejected_inputs_total", map[string]string{"tenant": "metrics", "reason": "invalid_length"}, 1},
+	}
+	for _, tt := range tests {
+		if got := counterValue(t, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v; want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+
+	// Shared validators, such as the PAN scanner's, record no validations
+	shared, err := service.NewValidator(&config.ValidatorConfig{}, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := counterValue(t, "card_validation_validations_total", map[string]string{"tenant": ""})
+	shared.ValidateCardSimple("4111111111111111")
+	if counterValue(t, "card_validation_validations_total", map[string]string{"tenant": ""}) != before {
+		t.Error("shared validator recorded a validation")
+	}
+}
+
+func TestBINLookupMetrics(t *testing.T) {
+	bins := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/") {
+		case "411111":
+			w.Write([]byte(`{"scheme":"visa","bank":{"name":"Test Bank"}}`))
+		case "555555":
+			w.WriteHeader(http.StatusNotFound)
+		case "510510":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "378282":
+			w.WriteHeader(http.StatusBadGateway)
+		case "601111":
+			w.Write([]byte(`not json`))
+		case "400000":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer bins.Close()
+
+	binURL, err := url.Parse(bins.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := binURL.Host
+
+	validator, err := service.NewTenantValidator("bins", &config.ValidatorConfig{
+		EnableBINLookup: true,
+		BINServiceURL:   bins.URL,
+		HTTPTimeout:     50 * time.Millisecond,
+	}, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cards := map[string]string{
+		"4111111111111111": "success",
+		"5555555555554444": "not_found",
+		"5105105105105100": "rate_limited",
+		"378282246310005":  "http_error",
+		"6011111111111117": "invalid_response",
+		"4000000000000002": "timeout",
+	}
+	for card, outcome := range cards {
+		result, err := validator.ValidateCard(context.Background(), card)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		labels := map[string]string{"provider": provider, "outcome": outcome}
+		if got := counterValue(t, "card_validation_bin_lookups_total", labels); got != 1 {
+			t.Errorf("lookups%v = %v; want 1", labels, got)
+		}
+		if got := histogramCount(t, "card_validation_bin_lookup_duration_seconds", labels); got != 1 {
+			t.Errorf("lookup duration%v count = %v; want 1", labels, got)
+		}
+
+		// Failed lookups fall back to the offline result
+		fallbacks := counterValue(t, "card_validation_enrichment_fallbacks_total", map[string]string{
+			"tenant": "bins", "provider": provider, "reason": outcome,
+		})
+		if outcome == "success" {
+			if fallbacks != 0 || result.EnrichmentStatus != service.EnrichmentEnriched {
+				t.Errorf("%s: fallbacks = %v, status = %s", card, fallbacks, result.EnrichmentStatus)
+			}
+		} else if fallbacks != 1 || result.EnrichmentStatus != service.EnrichmentFailed {
+			t.Errorf("%s: fallbacks = %v, status = %s", card, fallbacks, result.EnrichmentStatus)
+		}
+	}
+}
+
+func TestValidationMetricsOverREST(t *testing.T) {
+	e := newGatewayServer(t)
+	labels := map[string]string{"tenant": "retail", "card_type": "visa", "valid": "false"}
+	before := counterValue(t, "card_validation_validations_total", labels)
+
+	// The gateway calls the gRPC service, whose tenant validator records the
+	// validation, so REST and gRPC traffic is counted the same way
+	header := http.Header{"X-Tenant-Id": {"retail"}}
+	if rec := postJSON(e, "/api/v2/validate", `{"card_number":"4111111111111111"}`, header); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if got := counterValue(t, "card_validation_validations_total", labels) - before; got != 1 {
+		t.Errorf("validations increased by %v; want 1", got)
+	}
+}
+
+func TestRequestMetricsTenant(t *testing.T) {
+	e := newGatewayServer(t)
+	header := http.Header{"X-Tenant-Id": {"retail"}}
+
+	// Echo, gateway and gRPC-Web routes are all labeled with the tenant,
+	// including calls the gRPC service rejects
+	tests := []struct {
+		path, body, status string
+	}{
+		{"/api/v1/validate", `{"card_number":"4111111111111111"}`, "200"},
+		{"/api/v2/validate", `{"card_number":"4111111111111111"}`, "200"},
+		{"/api/v2/validate", `{"card_number":"abc"}`, "400"},
+	}
+	for _, tt := range tests {
+		labels := map[string]string{"endpoint": tt.path, "status": tt.status, "tenant": "retail"}
+		before := counterValue(t, "card_validation_requests_total", labels)
+
+		rec := postJSON(e, tt.path, tt.body, header)
+		if rec.Header().Get("X-Tenant-Id") != "retail" {
+			t.Errorf("%s %s: tenant header = %q", tt.path, tt.body, rec.Header().Get("X-Tenant-Id"))
+		}
+		if got := counterValue(t, "card_validation_requests_total", labels) - before; got != 1 {
+			t.Errorf("%s %s: requests increased by %v; want 1", tt.path, tt.body, got)
+		}
+	}
+
+	ts := newGRPCWebServer(t)
+	labels := map[string]string{"endpoint": "/cardvalidator.v2.CardValidator/*", "status": "200", "tenant": "default"}
+	before := counterValue(t, "card_validation_requests_total", labels)
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/cardvalidator.v2.CardValidator/ValidateCard", strings.NewReader(`{"card_number":"4111111111111111"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connect-Protocol-Version", "1")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.Header.Get("X-Tenant-Id") != "default" {
+		t.Errorf("gRPC-Web tenant header = %q", res.Header.Get("X-Tenant-Id"))
+	}
+	if got := counterValue(t, "card_validation_requests_total", labels) - before; got != 1 {
+		t.Errorf("gRPC-Web requests increased by %v; want 1", got)
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/api/mux"
+	"credit-card-validator/internal/certs"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	pb "credit-card-validator/pkg/proto"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// startSinglePort serves gRPC and an Echo health route on one listener and
+// returns its address
+func startSinglePort(t *testing.T, tlsConfig *tls.Config, opts mux.Options) string {
+	t.Helper()
+
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	registry, err := tenant.NewRegistry(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcapi.TenantUnaryInterceptor(registry)),
+	)
+	grpcapi.NewServer(registry, nil, &cfg.Batch, nil, logging.Discard()).RegisterServer(grpcServer)
+
+	e := echo.New()
+	e.GET("/health", func(c echo.Context) error {
+		return c.String(http.StatusOK, "healthy")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{
+		Handler:   mux.NewHandler(grpcServer, e, opts),
+		Protocols: mux.Protocols(tlsConfig != nil),
+		TLSConfig: tlsConfig,
+	}
+	if tlsConfig != nil {
+		go server.ServeTLS(listener, "", "")
+	} else {
+		go server.Serve(listener)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	return listener.Addr().String()
+}
+
+// validateOver calls ValidateCard over a connection with the given credentials
+func validateOver(t *testing.T, addr string, creds credentials.TransportCredentials) error {
+	t.Helper()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	res, err := pb.NewCardValidatorClient(conn).ValidateCard(context.Background(), &pb.ValidateCardRequest{CardNumber: "4111111111111111"})
+	if err == nil && !res.Valid {
+		t.Errorf("response = %v", res)
+	}
+	return err
+}
+
+func TestSinglePortH2C(t *testing.T) {
+	addr := startSinglePort(t, nil, mux.Options{})
+
+	if err := validateOver(t, addr, insecure.NewCredentials()); err != nil {
+		t.Fatalf("ValidateCard() error = %v", err)
+	}
+
+	res, err := http.Get("http://" + addr + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.ProtoMajor != 1 {
+		t.Errorf("health: status %d over %s", res.StatusCode, res.Proto)
+	}
+}
+
+func TestSinglePortTLSClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeSelfSigned(t, certFile, keyFile, "localhost")
+
+	reloader, err := certs.NewReloader(&config.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: certFile,
+		MinVersion:   "1.2",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// gRPC requires client certificates, HTTP does not
+	serverTLS, err := reloader.ServerConfig(certs.ClientAuthRequire.Shared(certs.ClientAuthNone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := startSinglePort(t, serverTLS, mux.Options{GRPCClientCert: true})
+
+	pemData, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(pemData)
+	clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = validateOver(t, addr, credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "localhost"}))
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("without certificate: error = %v; want Unauthenticated", err)
+	}
+
+	err = validateOver(t, addr, credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{clientCert}}))
+	if err != nil {
+		t.Errorf("with certificate: error = %v", err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: "localhost"}}}
+	res, err := client.Get("https://" + addr + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("health without certificate: status %d", res.StatusCode)
+	}
+}
+
+func TestIsGRPC(t *testing.T) {
+	tests := []struct {
+		protoMajor  int
+		contentType string
+		want        bool
+	}{
+		{2, "application/grpc", true},
+		{2, "application/grpc+proto", true},
+		{2, "application/grpc-web+proto", false},
+		{2, "application/json", false},
+		{1, "application/grpc", false},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, "/cardvalidator.CardValidator/ValidateCard", nil)
+		req.ProtoMajor = tt.protoMajor
+		req.Header.Set("Content-Type", tt.contentType)
+		if got := mux.IsGRPC(req); got != tt.want {
+			t.Errorf("IsGRPC(HTTP/%d %s) = %v; want %v", tt.protoMajor, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestClientAuthShared(t *testing.T) {
+	tests := []struct {
+		a, b, want certs.ClientAuth
+	}{
+		{certs.ClientAuthNone, "", certs.ClientAuthNone},
+		{certs.ClientAuthRequire, certs.ClientAuthRequire, certs.ClientAuthRequire},
+		{certs.ClientAuthRequire, certs.ClientAuthNone, certs.ClientAuthOptional},
+		{certs.ClientAuthOptional, certs.ClientAuthNone, certs.ClientAuthOptional},
+		{"bogus", certs.ClientAuthNone, "bogus"},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Shared(tt.b); got != tt.want {
+			t.Errorf("%q.Shared(%q) = %q; want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"credit-card-validator/internal/api/gateway"
+	"credit-card-validator/internal/api/openapi"
+	"credit-card-validator/internal/apperror"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newSpec() *openapi.Spec {
+	return openapi.New(gateway.Routes(), openapi.Options{
+		Title:         "Credit Card Validator API",
+		Version:       "1.0.0",
+		TenantHeader:  "X-Tenant-ID",
+		Auth:          true,
+		MaxBatchItems: 3,
+		MaxBodySize:   256,
+	})
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	e := echo.New()
+	e.GET("/openapi.json", newSpec().Handler())
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas         map[string]openapi.Schema `json:"schemas"`
+			SecuritySchemes map[string]interface{}    `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+	for _, path := range []string{"/api/v1/validate", "/api/v1/validate/batch", "/api/v2/validate/stream", "/api/v2/dlp/scan"} {
+		if _, ok := doc.Paths[path]["post"]; !ok {
+			t.Errorf("missing POST %s", path)
+		}
+	}
+	if op := doc.Paths["/api/v1/validate"]["post"]; op["operationId"] != "cardvalidator.CardValidator_ValidateCard" {
+		t.Errorf("operationId = %v", op["operationId"])
+	}
+	if len(doc.Components.SecuritySchemes) != 2 {
+		t.Errorf("security schemes = %v", doc.Components.SecuritySchemes)
+	}
+
+	request := doc.Components.Schemas["cardvalidator.ValidateCardRequest"]
+	if !reflect.DeepEqual(request.Required, []string{"card_number"}) || request.AdditionalProperties != false {
+		t.Errorf("ValidateCardRequest schema = %+v", request)
+	}
+	batch := doc.Components.Schemas["cardvalidator.v2.ValidateCardsRequest"]
+	if items := batch.Properties["card_numbers"]; items.MaxItems == nil || *items.MaxItems != 3 {
+		t.Errorf("card_numbers schema = %+v", items)
+	}
+
+	response := doc.Components.Schemas["cardvalidator.v2.ValidateCardResponse"]
+	if lat := doc.Components.Schemas["cardvalidator.v2.Country"].Properties["latitude"]; lat.Type != "number" {
+		t.Errorf("v2 latitude = %+v", lat)
+	}
+	if issues := response.Properties["issues"]; issues.Type != "array" || len(issues.Items.Enum) != 3 {
+		t.Errorf("issues = %+v", issues)
+	}
+	if country := response.Properties["country"]; !country.Nullable || len(country.AllOf) != 1 {
+		t.Errorf("country = %+v", country)
+	}
+}
+
+func TestOpenAPIValidation(t *testing.T) {
+	spec := newSpec()
+
+	e := echo.New()
+	gateway.Register(e, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), spec.ValidateRequests())
+
+	tests := []struct {
+		path, body string
+		want       []openapi.FieldError
+	}{
+		{"/api/v1/validate", `{"card_number": "4111111111111111"}`, nil},
+		{"/api/v1/validate", ``, []openapi.FieldError{{Field: "card_number", Message: "is required"}}},
+		{"/api/v1/validate", `{"card_number": ""}`, []openapi.FieldError{{Field: "card_number", Message: "must not be empty"}}},
+		{"/api/v1/validate", `{"card_number": 4111111111111111, "cvv": "123"}`, []openapi.FieldError{
+			{Field: "card_number", Message: "must be a string"},
+			{Field: "cvv", Message: "is not a known field"},
+		}},
+		{"/api/v1/validate", `[]`, []openapi.FieldError{{Message: "must be an object"}}},
+		{"/api/v2/validate/batch", `{"card_numbers": ["4111111111111111", 42, null]}`, []openapi.FieldError{
+			{Field: "card_numbers[1]", Message: "must be a string"},
+			{Field: "card_numbers[2]", Message: "must not be null"},
+		}},
+		{"/api/v2/validate/batch", `{"card_numbers": []}`, []openapi.FieldError{{Field: "card_numbers", Message: "must not be empty"}}},
+		{"/api/v2/validate/batch", `{"card_numbers": ["1", "2", "3", "4"]}`, []openapi.FieldError{{Field: "card_numbers", Message: "must have at most 3 items"}}},
+		{"/api/v1/dlp/scan", `{"text": true}`, []openapi.FieldError{{Field: "text", Message: "must be a string"}}},
+		// Streams carry one message per line and are passed through
+		{"/api/v1/validate/stream", "{}\n{}\n", nil},
+	}
+
+	for _, tt := range tests {
+		rec := postJSON(e, tt.path, tt.body, nil)
+		if tt.want == nil {
+			if rec.Code != http.StatusNoContent {
+				t.Errorf("%s %s: status = %d; body %s", tt.path, tt.body, rec.Code, rec.Body)
+			}
+			continue
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: status = %d; want 400", tt.path, tt.body, rec.Code)
+			continue
+		}
+		var res apperror.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Error == "" || !reflect.DeepEqual(res.Fields, tt.want) {
+			t.Errorf("%s %s: fields = %+v; want %+v", tt.path, tt.body, res.Fields, tt.want)
+		}
+	}
+
+	// Malformed JSON is reported on the body
+	rec := postJSON(e, "/api/v1/validate", `{"card_number": `, nil)
+	var res apperror.Problem
+	json.Unmarshal(rec.Body.Bytes(), &res)
+	if rec.Code != http.StatusBadRequest || len(res.Fields) != 1 || res.Fields[0].Field != "" {
+		t.Errorf("malformed body: status %d, %s", rec.Code, rec.Body)
+	}
+
+	// Bodies above the limit are rejected before they are read in full
+	rec = postJSON(e, "/api/v2/dlp/scan", `{"text": "`+strings.Repeat("a", 300)+`"}`, nil)
+	res = apperror.Problem{}
+	json.Unmarshal(rec.Body.Bytes(), &res)
+	if rec.Code != http.StatusRequestEntityTooLarge || res.Code != apperror.CodePayloadTooLarge {
+		t.Errorf("oversized body: status %d, %s", rec.Code, rec.Body)
+	}
+}
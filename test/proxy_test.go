@@ -0,0 +1,326 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/proxy"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestProxyRedactsBodiesPerRoute(t *testing.T) {
+	var received []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+
+		switch r.URL.Path {
+		case "/payments":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status":"ok","card":"4111-1111-1111-1111"}`))
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("4111111111111111"))
+		}
+	}))
+	defer upstream.Close()
+
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.ProxyConfig{
+		Upstream:        upstream.URL,
+		DefaultRequest:  "mask",
+		DefaultResponse: "none",
+		MaxBodySize:     1 << 20,
+		TokenKey:        "secret",
+	}
+	routes := []config.ProxyRoute{
+		{Path: "/payments", Methods: []string{"POST"}, Request: "tokenize", Response: "mask"},
+	}
+	p, err := proxy.NewProxy(cfg, routes, scanner, nil)
+	if err != nil {
+		t.Fatalf("NewProxy() error = %v", err)
+	}
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	redactionLabels := map[string]string{"route": "/payments", "direction": "request", "action": "tokenize", "card_type": "visa"}
+	before := counterValue(t, "card_validation_proxy_redactions_total", redactionLabels)
+
+	// JSON numbers holding a PAN become strings
+	resp, err := http.Post(server.URL+"/payments", "application/json", strings.NewReader(`{"pan":4111111111111111,"note":"card 4111 1111 1111 1111"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	var forwarded map[string]string
+	if err := json.Unmarshal([]byte(received[0]), &forwarded); err != nil {
+		t.Fatalf("forwarded body is not valid JSON: %v: %s", err, received[0])
+	}
+	if !strings.HasPrefix(forwarded["pan"], "tok_") || forwarded["note"] != "card "+forwarded["pan"] {
+		t.Errorf("forwarded body = %s; want the same token for both PANs", received[0])
+	}
+	if string(body) != `{"status":"ok","card":"****-****-****-1111"}` {
+		t.Errorf("response body = %s", body)
+	}
+	if got := counterValue(t, "card_validation_proxy_redactions_total", redactionLabels) - before; got != 2 {
+		t.Errorf("redaction counter increased by %v; want 2", got)
+	}
+
+	// Other routes use the defaults: forms are masked, responses untouched
+	resp, err = http.Post(server.URL+"/legacy", "application/x-www-form-urlencoded", strings.NewReader("name=Jane+Doe&card=5555+5555+5555+4444"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if received[1] != "name=Jane+Doe&card=%2A%2A%2A%2A+%2A%2A%2A%2A+%2A%2A%2A%2A+4444" {
+		t.Errorf("forwarded form = %s", received[1])
+	}
+	if string(body) != "4111111111111111" {
+		t.Errorf("binary response was modified: %s", body)
+	}
+}
+
+func TestProxyRequiresTokenKeyToTokenize(t *testing.T) {
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.ProxyConfig{Upstream: "http://localhost:1", DefaultRequest: "tokenize", DefaultResponse: "mask"}
+	if _, err := proxy.NewProxy(cfg, nil, scanner, nil); err == nil {
+		t.Error("NewProxy() without a token key should fail")
+	}
+
+	cfg = &config.ProxyConfig{Upstream: "http://localhost:1", DefaultRequest: "mask", DefaultResponse: "scramble"}
+	if _, err := proxy.NewProxy(cfg, nil, scanner, nil); err == nil {
+		t.Error("NewProxy() with an unknown action should fail")
+	}
+}
+
+func TestProxyFailClosed(t *testing.T) {
+	var received []*http.Request
+	var bodies []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, string(body))
+
+		switch r.URL.Path {
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("4111111111111111"))
+		case "/redirect":
+			w.Header().Set("Location", "/image")
+			w.WriteHeader(http.StatusFound)
+		case "/huge":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("0", 100)))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer upstream.Close()
+
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.ProxyConfig{
+		Upstream:        upstream.URL,
+		DefaultRequest:  "mask",
+		DefaultResponse: "mask",
+		MaxBodySize:     64,
+		FailClosed:      true,
+	}
+	p, err := proxy.NewProxy(cfg, nil, scanner, nil)
+	if err != nil {
+		t.Fatalf("NewProxy() error = %v", err)
+	}
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	compress := func(text string) string {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(text))
+		zw.Close()
+		return buf.String()
+	}
+
+	// Redirects are not followed so their own response is checked
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	send := func(path, contentType, encoding, body string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp.StatusCode, string(data)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		encoding    string
+		body        string
+		status      int
+		reason      string
+	}{
+		{name: "Unsupported encoding", contentType: "text/plain", encoding: "br", body: "4111111111111111", status: http.StatusUnsupportedMediaType, reason: "content_encoding"},
+		{name: "Too large", contentType: "text/plain", body: strings.Repeat("4111111111111111 ", 5), status: http.StatusRequestEntityTooLarge, reason: "too_large"},
+		{name: "Too large decoded", contentType: "text/plain", encoding: "gzip", body: compress(strings.Repeat("0", 1000)), status: http.StatusRequestEntityTooLarge, reason: "too_large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{"route": "default", "direction": "request", "reason": tt.reason}
+			before := counterValue(t, "card_validation_proxy_rejected_bodies_total", labels)
+
+			if status, _ := send("/upload", tt.contentType, tt.encoding, tt.body); status != tt.status {
+				t.Errorf("status = %d; want %d", status, tt.status)
+			}
+			if len(received) != 0 {
+				t.Errorf("rejected body reached the upstream: %q", bodies)
+			}
+			if got := counterValue(t, "card_validation_proxy_rejected_bodies_total", labels) - before; got != 1 {
+				t.Errorf("rejected counter increased by %v; want 1", got)
+			}
+		})
+	}
+
+	// Gzip bodies are scanned and forwarded decoded
+	if status, _ := send("/upload", "text/plain", "gzip", compress("card 4111 1111 1111 1111")); status != http.StatusOK {
+		t.Fatalf("gzip status = %d", status)
+	}
+	if len(bodies) != 1 || bodies[0] != "card **** **** **** 1111" || received[0].Header.Get("Content-Encoding") != "" {
+		t.Errorf("forwarded gzip body = %q", bodies)
+	}
+
+	// Binary bodies and bodies without a Content-Type are not text and are
+	// forwarded unchanged
+	for _, contentType := range []string{"application/octet-stream", ""} {
+		if status, _ := send("/upload", contentType, "", "\x89PNG 4111111111111111"); status != http.StatusOK {
+			t.Errorf("%q request status = %d; want %d", contentType, status, http.StatusOK)
+		}
+		if last := bodies[len(bodies)-1]; last != "\x89PNG 4111111111111111" {
+			t.Errorf("%q request forwarded as %q", contentType, last)
+		}
+	}
+	if status, body := send("/image", "", "", ""); status != http.StatusOK || body != "4111111111111111" {
+		t.Errorf("binary response = %d %q; want it unchanged", status, body)
+	}
+	if status, _ := send("/redirect", "", "", ""); status != http.StatusFound {
+		t.Errorf("redirect status = %d; want %d", status, http.StatusFound)
+	}
+
+	// Text responses that cannot be scanned are not passed to the client
+	if status, _ := send("/huge", "", "", ""); status != http.StatusBadGateway {
+		t.Errorf("unscannable response status = %d; want %d", status, http.StatusBadGateway)
+	}
+}
+
+func TestProxyFailOpen(t *testing.T) {
+	var received string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}))
+	defer upstream.Close()
+
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.ProxyConfig{Upstream: upstream.URL, DefaultRequest: "mask", DefaultResponse: "none", MaxBodySize: 64}
+	p, err := proxy.NewProxy(cfg, nil, scanner, nil)
+	if err != nil {
+		t.Fatalf("NewProxy() error = %v", err)
+	}
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	labels := map[string]string{"route": "default", "direction": "request", "reason": "too_large"}
+	before := counterValue(t, "card_validation_proxy_skipped_bodies_total", labels)
+
+	body := strings.Repeat("4111111111111111 ", 5)
+	resp, err := http.Post(server.URL+"/upload", "text/plain", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || received != body {
+		t.Errorf("status = %d, forwarded = %q; want the body unscanned", resp.StatusCode, received)
+	}
+	if got := counterValue(t, "card_validation_proxy_skipped_bodies_total", labels) - before; got != 1 {
+		t.Errorf("skipped counter increased by %v; want 1", got)
+	}
+}
+
+// counterValue returns the value of a registered counter with the given
+// labels, 0 when it has not been incremented yet
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				value, ok := labels[label.GetName()]
+				if !ok {
+					continue
+				}
+				if value != label.GetValue() {
+					continue metrics
+				}
+				matched++
+			}
+			if matched == len(labels) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	pb "credit-card-validator/pkg/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestValidateCardStream(t *testing.T) {
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Batch:     config.BatchConfig{MaxItems: 10, Concurrency: 2, StreamConcurrency: 2},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	stream, err := newStreamClient(t, cfg, nil).ValidateCardStream(context.Background())
+	if err != nil {
+		t.Fatalf("ValidateCardStream() error = %v", err)
+	}
+
+	const count = 50
+	go func() {
+		for i := 0; i < count; i++ {
+			card := "4111 1111 1111 1111"
+			if i%5 == 0 {
+				card = "invalid"
+			}
+			stream.Send(&pb.ValidateCardStreamRequest{
+				CorrelationId: fmt.Sprintf("req-%d", i),
+				CardNumber:    card,
+			})
+		}
+		stream.CloseSend()
+	}()
+
+	seen := make(map[string]bool)
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		if seen[res.CorrelationId] {
+			t.Errorf("duplicate response for %s", res.CorrelationId)
+		}
+		seen[res.CorrelationId] = true
+
+		var index int
+		fmt.Sscanf(res.CorrelationId, "req-%d", &index)
+		if wantErr := index%5 == 0; wantErr != (res.GetError() != "") {
+			t.Errorf("%s: unexpected outcome %v", res.CorrelationId, res.Outcome)
+		}
+		if res.GetResult() != nil && !res.GetResult().Valid {
+			t.Errorf("%s: card reported invalid", res.CorrelationId)
+		}
+	}
+
+	if len(seen) != count {
+		t.Errorf("received %d responses; want %d", len(seen), count)
+	}
+}
+
+func TestValidateCardStreamEndsOnAuditFailure(t *testing.T) {
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Batch:     config.BatchConfig{MaxItems: 10, Concurrency: 2, StreamConcurrency: 2},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	auditLog, err := audit.NewLogger(&config.AuditConfig{Dir: t.TempDir(), FingerprintKey: "test-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLog.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := newStreamClient(t, cfg, auditLog).ValidateCardStream(ctx)
+	if err != nil {
+		t.Fatalf("ValidateCardStream() error = %v", err)
+	}
+
+	// The client keeps the stream open; the server must end it on its own
+	if err := stream.Send(&pb.ValidateCardStreamRequest{CorrelationId: "req-0", CardNumber: "4111111111111111"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("Recv() error = %v; want Unavailable", err)
+	}
+}
+
+func TestValidateCardStreamRateLimit(t *testing.T) {
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Batch:     config.BatchConfig{MaxItems: 10, Concurrency: 2, StreamConcurrency: 1},
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID", RateLimit: 0.001, RateBurst: 4},
+	}
+	cfg.Validator.EnableBINLookup = false
+
+	registry, err := tenant.NewRegistry(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newStreamClient(t, cfg, nil,
+		grpc.StreamInterceptor(grpcapi.TenantStreamInterceptor(registry)),
+		grpc.UnaryInterceptor(grpcapi.TenantUnaryInterceptor(registry)))
+
+	// Opening the stream is charged once; every card is charged as well
+	stream, err := client.ValidateCardStream(context.Background())
+	if err != nil {
+		t.Fatalf("ValidateCardStream() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		stream.Send(&pb.ValidateCardStreamRequest{CorrelationId: fmt.Sprintf("req-%d", i), CardNumber: "4111111111111111"})
+	}
+	stream.CloseSend()
+
+	var limited int
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		if res.GetError() == "rate limit exceeded" {
+			limited++
+		}
+	}
+	if limited != 2 {
+		t.Errorf("rate limited %d cards; want 2", limited)
+	}
+
+	// Batches are charged for every card, so they cannot exceed the burst
+	_, err = client.ValidateCards(context.Background(), &pb.ValidateCardsRequest{CardNumbers: []string{"4111111111111111", "5500000000000004"}})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("ValidateCards() error = %v; want ResourceExhausted", err)
+	}
+}
+
+// newStreamClient serves the validator API on an in-memory listener and
+// returns a client connected to it
+func newStreamClient(t *testing.T, cfg *config.Config, auditLog *audit.Logger, opts ...grpc.ServerOption) pb.CardValidatorClient {
+	t.Helper()
+
+	registry, err := tenant.NewRegistry(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	grpcapi.NewServer(registry, auditLog, &cfg.Batch, nil, logging.Discard()).RegisterServer(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewCardValidatorClient(conn)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/dlp"
+	"credit-card-validator/internal/syslog"
+)
+
+func TestSyslogMasksAndForwardsMessages(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "redacted.log")
+	cfg := &config.SyslogConfig{
+		UDPAddr:        "127.0.0.1:0",
+		TCPAddr:        "127.0.0.1:0",
+		Forward:        "file://" + out,
+		ForwardTimeout: time.Second,
+		MaxMessageSize: 4096,
+		Sources:        []string{"pos-01"},
+	}
+
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := syslog.NewServer(cfg, scanner, nil)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.Serve(ctx)
+		close(done)
+	}()
+
+	before := counterValue(t, "card_validation_syslog_redactions_total", map[string]string{"source": "pos-01"})
+	beforeOther := counterValue(t, "card_validation_syslog_redactions_total", map[string]string{"source": "other"})
+
+	udp, err := net.Dial("udp", server.UDPAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	fmt.Fprint(udp, "<34>Oct 11 22:14:15 pos-01 app[42]: charged card 4111 1111 1111 1111 ok")
+	fmt.Fprint(udp, "<34>Oct 11 22:14:16 pos-99 app[42]: refunded 4111111111111111")
+
+	tcp, err := net.Dial("tcp", server.TCPAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := `<165>1 2026-10-19T22:14:15.003Z pos-01 pay 77 ID47 [meta pan="5555555555554444"] declined`
+	fmt.Fprintf(tcp, "%d %s", len(msg), msg)
+	fmt.Fprint(tcp, "<13>Oct  9 01:02:03 batch: no cards here\n")
+	tcp.Close()
+
+	want := []string{
+		"<34>Oct 11 22:14:15 pos-01 app[42]: charged card **** **** **** 1111 ok",
+		"<34>Oct 11 22:14:16 pos-99 app[42]: refunded ************1111",
+		`<165>1 2026-10-19T22:14:15.003Z pos-01 pay 77 ID47 [meta pan="************4444"] declined`,
+		"<13>Oct  9 01:02:03 batch: no cards here",
+	}
+
+	var lines []string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(out)
+		lines = strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) == len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stop()
+	<-done
+
+	if len(lines) != len(want) {
+		t.Fatalf("forwarded %q; want %d messages", lines, len(want))
+	}
+	for _, w := range want {
+		found := false
+		for _, line := range lines {
+			found = found || line == w
+		}
+		if !found {
+			t.Errorf("message %q was not forwarded; got %q", w, lines)
+		}
+	}
+
+	if got := counterValue(t, "card_validation_syslog_redactions_total", map[string]string{"source": "pos-01"}) - before; got != 2 {
+		t.Errorf("redactions for pos-01 increased by %v; want 2", got)
+	}
+	// Hosts missing from SYSLOG_SOURCES share one label
+	if got := counterValue(t, "card_validation_syslog_redactions_total", map[string]string{"source": "other"}) - beforeOther; got != 1 {
+		t.Errorf("redactions for other hosts increased by %v; want 1", got)
+	}
+	if got := counterValue(t, "card_validation_syslog_messages_total", map[string]string{"source": "pos-99"}); got != 0 {
+		t.Errorf("pos-99 has its own label")
+	}
+}
+
+func TestSyslogDropsConnectionsWithInvalidFrameLength(t *testing.T) {
+	cfg := &config.SyslogConfig{
+		TCPAddr:        "127.0.0.1:0",
+		Forward:        "file://" + filepath.Join(t.TempDir(), "redacted.log"),
+		ForwardTimeout: time.Second,
+		MaxMessageSize: 4096,
+	}
+
+	scanner, err := dlp.NewScanner(&config.DLPConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := syslog.NewServer(cfg, scanner, nil)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.Serve(ctx)
+		close(done)
+	}()
+	defer func() {
+		stop()
+		<-done
+	}()
+
+	for name, prefix := range map[string]string{
+		"Endless digits":  strings.Repeat("1", 1<<20),
+		"Too many digits": "12345678901 <13>hello",
+		"Too large":       "99999 <13>hello",
+		"Not a number":    "12ab <13>hello",
+	} {
+		t.Run(name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", server.TCPAddr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			// The server may close the connection before everything is sent
+			go conn.Write([]byte(prefix))
+
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			_, err = conn.Read(make([]byte, 1))
+			if netErr, ok := err.(net.Error); err == nil || ok && netErr.Timeout() {
+				t.Errorf("connection still open: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"credit-card-validator/internal/auth"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+)
+
+func TestTenantRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	err := os.WriteFile(path, []byte(`
+tenants:
+  retail:
+    accepted_card_types: [mastercard]
+    enable_bin_lookup: false
+    rate_limit: 1
+    rate_burst: 1
+  travel:
+    mask_sensitive: false
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overrides, err := config.LoadTenants(path)
+	if err != nil {
+		t.Fatalf("LoadTenants() error = %v", err)
+	}
+
+	base := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	registry, err := tenant.NewRegistry(base, overrides, nil)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	retail, ok := registry.Get("retail")
+	if !ok {
+		t.Fatal("retail tenant missing")
+	}
+	if retail.Config.EnableBINLookup || !retail.Config.MaskSensitive {
+		t.Errorf("unexpected retail config %+v", retail.Config)
+	}
+
+	// Only Mastercard is accepted, so a valid Visa number is rejected
+	result, err := retail.Validator.ValidateCardSimple("4111 1111 1111 1111")
+	if err != nil {
+		t.Fatalf("ValidateCardSimple() error = %v", err)
+	}
+	if result.Valid || len(result.Issues) != 1 || result.Issues[0] != service.IssueCardTypeNotAccepted {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if !retail.Allow() || retail.Allow() {
+		t.Error("retail tenant should allow exactly one request per burst")
+	}
+
+	tests := []struct {
+		name      string
+		identity  *auth.Identity
+		requested string
+		want      string
+		wantErr   error
+	}{
+		{name: "Default", want: tenant.DefaultID},
+		{name: "Header", requested: "travel", want: "travel"},
+		{name: "Identity", identity: &auth.Identity{Tenant: "retail"}, want: "retail"},
+		{name: "Matching header", identity: &auth.Identity{Tenant: "retail"}, requested: "retail", want: "retail"},
+		{name: "Mismatch", identity: &auth.Identity{Tenant: "retail"}, requested: "travel", wantErr: tenant.ErrTenantMismatch},
+		{name: "Unknown", requested: "missing", wantErr: tenant.ErrUnknownTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Resolve(tt.identity, tt.requested)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Resolve() error = %v; want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.ID != tt.want {
+				t.Errorf("Resolve() = %v, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
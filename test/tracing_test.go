@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"credit-card-validator/internal/api/gateway"
+	grpcapi "credit-card-validator/internal/api/grpc"
+	"credit-card-validator/internal/audit"
+	"credit-card-validator/internal/config"
+	"credit-card-validator/internal/logging"
+	"credit-card-validator/internal/middleware"
+	"credit-card-validator/internal/service"
+	"credit-card-validator/internal/tenant"
+	"credit-card-validator/internal/tracing"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// collector is an in-process OTLP trace collector
+type collector struct {
+	collectortrace.UnimplementedTraceServiceServer
+
+	mu    sync.Mutex
+	spans []string
+}
+
+func (c *collector) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				c.spans = append(c.spans, span.Name)
+			}
+		}
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+func (c *collector) names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.spans...)
+}
+
+// startCollector serves an OTLP collector on a loopback port
+func startCollector(t *testing.T) (*collector, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &collector{}
+	server := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(server, c)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return c, listener.Addr().String()
+}
+
+// recordSpans installs a tracer provider recording every span in memory
+// until the test ends
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	previous := otel.GetTracerProvider()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+// binServer returns a BIN service recording the traceparent headers it receives
+func binServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		parents []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		parents = append(parents, r.Header.Get("Traceparent"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"scheme":"visa","type":"debit","bank":{"name":"Test Bank"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &parents
+}
+
+// spanNamed returns the ended span called name
+func spanNamed(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("no span %q", name)
+	return nil
+}
+
+func TestTracingExportsToCollector(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	c, endpoint := startCollector(t)
+	shutdown, err := tracing.Setup(context.Background(), &config.TracingConfig{
+		Enabled:     true,
+		Endpoint:    endpoint,
+		Insecure:    true,
+		SampleRatio: 1,
+		ServiceName: "test",
+	})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	bins, parents := binServer(t)
+	validator, err := service.NewValidator(&config.ValidatorConfig{
+		EnableBINLookup: true,
+		BINServiceURL:   bins.URL,
+	}, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "request")
+	if _, err := validator.ValidateCard(ctx, "4111111111111111"); err != nil {
+		t.Fatal(err)
+	}
+	span.End()
+
+	// Shutting down flushes the batched spans
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown error = %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, name := range c.names() {
+		got[name] = true
+	}
+	for _, name := range []string{"request", "ValidateCard", "sanitize", "detect", "luhn", "enrich", "GET"} {
+		if !got[name] {
+			t.Errorf("span %q not exported; got %v", name, c.names())
+		}
+	}
+
+	// The BIN service receives the trace context of the call
+	traceID := span.SpanContext().TraceID().String()
+	if len(*parents) != 1 || len((*parents)[0]) < 35 || (*parents)[0][3:35] != traceID {
+		t.Errorf("traceparent = %v; want trace %s", *parents, traceID)
+	}
+}
+
+func TestTracingSampling(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	c, endpoint := startCollector(t)
+	shutdown, err := tracing.Setup(context.Background(), &config.TracingConfig{
+		Enabled:     true,
+		Endpoint:    endpoint,
+		Insecure:    true,
+		SampleRatio: 0,
+		ServiceName: "test",
+	})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	// New traces are dropped at ratio 0
+	_, span := tracing.Tracer().Start(context.Background(), "unsampled")
+	span.End()
+
+	// Traces sampled by the caller are kept
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	_, span = tracing.Tracer().Start(trace.ContextWithRemoteSpanContext(context.Background(), parent), "sampled")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown error = %v", err)
+	}
+	if names := c.names(); len(names) != 1 || names[0] != "sampled" {
+		t.Errorf("exported spans = %v; want [sampled]", names)
+	}
+}
+
+func TestTracingRESTThroughGateway(t *testing.T) {
+	recorder := recordSpans(t)
+	bins, parents := binServer(t)
+
+	cfg := &config.Config{
+		Validator: *service.DefaultConfig(),
+		Batch:     config.BatchConfig{MaxItems: 2, Concurrency: 2},
+		Tenants:   config.TenantsConfig{TenantHeader: "X-Tenant-ID"},
+	}
+	cfg.Validator.BINServiceURL = bins.URL
+	registry, err := tenant.NewRegistry(cfg, nil, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcapi.TracingUnaryInterceptor(),
+		grpcapi.ChannelUnaryInterceptor(audit.ChannelREST),
+		grpcapi.TenantUnaryInterceptor(registry),
+	))
+	grpcapi.NewServer(registry, nil, &cfg.Batch, nil, logging.Discard()).RegisterServer(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(grpcapi.TracingClientUnaryInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	handler, err := gateway.NewHandler(context.Background(), conn, registry.Header())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	e.Use(middleware.Tracing())
+	gateway.Register(e, handler)
+
+	// The client's trace is continued
+	const clientTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	header := http.Header{"Traceparent": {"00-" + clientTrace + "-00f067aa0ba902b7-01"}}
+	if rec := postJSON(e, "/api/v2/validate", `{"card_number":"4111111111111111"}`, header); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	httpSpan := spanNamed(t, recorder, "POST /api/v2/validate")
+	rpcSpan := spanNamed(t, recorder, "cardvalidator.v2.CardValidator/ValidateCard")
+	validateSpan := spanNamed(t, recorder, "ValidateCard")
+	enrichSpan := spanNamed(t, recorder, "enrich")
+	binSpan := spanNamed(t, recorder, "GET")
+
+	if httpSpan.SpanContext().TraceID().String() != clientTrace || httpSpan.SpanKind() != trace.SpanKindServer {
+		t.Errorf("HTTP span trace = %s kind = %v", httpSpan.SpanContext().TraceID(), httpSpan.SpanKind())
+	}
+	for _, tt := range []struct {
+		child, parent sdktrace.ReadOnlySpan
+	}{
+		{rpcSpan, httpSpan},
+		{validateSpan, rpcSpan},
+		{enrichSpan, validateSpan},
+		{binSpan, enrichSpan},
+	} {
+		if tt.child.Parent().SpanID() != tt.parent.SpanContext().SpanID() {
+			t.Errorf("%q parent = %s; want %q", tt.child.Name(), tt.child.Parent().SpanID(), tt.parent.Name())
+		}
+	}
+	for _, stage := range []string{"sanitize", "detect", "luhn"} {
+		if span := spanNamed(t, recorder, stage); span.Parent().SpanID() != validateSpan.SpanContext().SpanID() {
+			t.Errorf("%q is not a stage of ValidateCard", stage)
+		}
+	}
+
+	// The outbound call carries the span of the BIN request
+	want := "00-" + clientTrace + "-" + binSpan.SpanContext().SpanID().String() + "-01"
+	if len(*parents) != 1 || (*parents)[0] != want {
+		t.Errorf("traceparent = %v; want %s", *parents, want)
+	}
+}
+
+func TestTracingOutsideRequests(t *testing.T) {
+	recorder := recordSpans(t)
+
+	validator, err := service.NewValidator(&config.ValidatorConfig{}, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := validator.ValidateCard(context.Background(), "4111111111111111"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validations outside of a traced request start no traces
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Errorf("recorded %d spans", len(spans))
+	}
+}